@@ -0,0 +1,115 @@
+package main
+
+import (
+	"net"
+	"regexp"
+	"strings"
+)
+
+// Whether the user's current IP can reach a resource
+const (
+	AccessReachable = "reachable"
+	AccessBlocked   = "not reachable"
+	AccessPrivate   = "private only"
+)
+
+// AccessStatus is whether the user's current IP can reach a resource, and through which entry
+type AccessStatus struct {
+	Resource CloudResource
+	Status   string
+	Via      *AuthorizedNetwork  // The entry containing the IP; nil when the resource is open to every IP
+	Stale    []AuthorizedNetwork // Entries named after the user that point at other IPs
+}
+
+// CheckAccess works out which resources the IP can reach, and which of the user's own entries
+// have gone stale. names are the user's names (OS user, gcloud account); entries match when
+// one appears in their name as a whole word.
+func CheckAccess(resources []CloudResource, ip string, names []string) []AccessStatus {
+	patterns := userNamePatterns(names)
+
+	statuses := make([]AccessStatus, 0, len(resources))
+	for _, resource := range resources {
+		status := AccessStatus{Resource: resource, Status: AccessBlocked}
+		networks := resourceNetworks(resource)
+
+		if via, ok := entryContaining(networks, ip); ok {
+			status.Status = AccessReachable
+			status.Via = &via
+		}
+		if cluster, ok := resource.(GKECluster); ok && !cluster.MasterAuthorizedNetworksEnabled && cluster.HasPublicIP() {
+			status.Status = AccessReachable
+		}
+		if !resource.HasPublicIP() {
+			status.Status = AccessPrivate
+		}
+
+		for _, network := range networks {
+			if _, current := entryContaining([]AuthorizedNetwork{network}, ip); current {
+				continue
+			}
+			if matchesUserName(networkName(network), patterns) {
+				status.Stale = append(status.Stale, network)
+			}
+		}
+		statuses = append(statuses, status)
+	}
+	return statuses
+}
+
+// accessNames returns the names the user's entries are likely to carry: the OS user
+// and the local part of the gcloud account
+func accessNames() []string {
+	var names []string
+	if name := getUserName(); name != "" {
+		names = append(names, name)
+	}
+	if account := getGcloudAccount(); account != "" {
+		local, _, _ := strings.Cut(account, "@")
+		names = append(names, local)
+	}
+	return names
+}
+
+// userNamePatterns matches a name as a whole word, e.g. "alice" in "alice-laptop" but not "malice"
+func userNamePatterns(names []string) []*regexp.Regexp {
+	var patterns []*regexp.Regexp
+	for _, name := range names {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == "" {
+			continue
+		}
+		patterns = append(patterns, regexp.MustCompile(`(^|[^a-z0-9])`+regexp.QuoteMeta(name)+`([^a-z0-9]|$)`))
+	}
+	return patterns
+}
+
+// matchesUserName reports whether an entry name matches any of the user's names
+func matchesUserName(name string, patterns []*regexp.Regexp) bool {
+	name = strings.ToLower(name)
+	for _, pattern := range patterns {
+		if pattern.MatchString(name) {
+			return true
+		}
+	}
+	return false
+}
+
+// entryContaining returns the first entry whose range contains an IP or CIDR
+func entryContaining(networks []AuthorizedNetwork, ip string) (AuthorizedNetwork, bool) {
+	addr, _, err := net.ParseCIDR(ip)
+	if err != nil {
+		if addr = net.ParseIP(ip); addr == nil {
+			return AuthorizedNetwork{}, false
+		}
+	}
+	for _, network := range networks {
+		normalized, err := normalizeIP(network.Value)
+		if err != nil {
+			continue
+		}
+		if _, block, err := net.ParseCIDR(normalized); err == nil && block.Contains(addr) {
+			return network, true
+		}
+	}
+	return AuthorizedNetwork{}, false
+}
@@ -0,0 +1,98 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestCheckAccess(t *testing.T) {
+	const ip = "203.0.113.7"
+	names := []string{"alice", "alice.smith"}
+
+	tests := []struct {
+		name      string
+		resource  CloudResource
+		wantState string
+		wantVia   string   // Name of the entry granting access
+		wantStale []string // Names of the user's entries for other IPs
+	}{
+		{
+			name: "reachable through a range",
+			resource: SQLInstance{Name: "orders", PublicIPEnabled: true, AuthorizedNetworks: []AuthorizedNetwork{
+				{Name: "office", Value: "203.0.113.0/24"},
+			}},
+			wantState: AccessReachable,
+			wantVia:   "office",
+		},
+		{
+			name: "reachable through a single IP",
+			resource: SQLInstance{Name: "orders", PublicIPEnabled: true, AuthorizedNetworks: []AuthorizedNetwork{
+				{Name: "alice-laptop", Value: "203.0.113.7"},
+			}},
+			wantState: AccessReachable,
+			wantVia:   "alice-laptop",
+		},
+		{
+			name: "stale entries",
+			resource: SQLInstance{Name: "orders", PublicIPEnabled: true, AuthorizedNetworks: []AuthorizedNetwork{
+				{Name: "alice-laptop", Value: "198.51.100.9/32"},
+				{Name: "alice.smith home", Value: "192.0.2.1/32"},
+				{Name: "malice", Value: "192.0.2.2/32"},
+				{Name: "bob-laptop", Value: "192.0.2.3/32"},
+			}},
+			wantState: AccessBlocked,
+			wantStale: []string{"alice-laptop", "alice.smith home"},
+		},
+		{
+			name: "own entry for the current IP isn't stale",
+			resource: SQLInstance{Name: "orders", PublicIPEnabled: true, AuthorizedNetworks: []AuthorizedNetwork{
+				{Name: "Alice-Laptop", Value: "203.0.113.7/32"},
+				{Name: "alice-home", Value: "192.0.2.1/32"},
+			}},
+			wantState: AccessReachable,
+			wantVia:   "Alice-Laptop",
+			wantStale: []string{"alice-home"},
+		},
+		{
+			name:      "GKE with authorized networks disabled",
+			resource:  GKECluster{Name: "sandbox", PublicEndpoint: "34.1.2.3"},
+			wantState: AccessReachable,
+		},
+		{
+			name: "private instance",
+			resource: SQLInstance{Name: "ledger", AuthorizedNetworks: []AuthorizedNetwork{
+				{Name: "office", Value: "203.0.113.0/24"},
+			}},
+			wantState: AccessPrivate,
+			wantVia:   "office",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			statuses := CheckAccess([]CloudResource{tt.resource}, ip, names)
+			if len(statuses) != 1 {
+				t.Fatalf("got %d statuses, want 1", len(statuses))
+			}
+			status := statuses[0]
+
+			if status.Status != tt.wantState {
+				t.Errorf("status = %q, want %q", status.Status, tt.wantState)
+			}
+			var via string
+			if status.Via != nil {
+				via = status.Via.Name
+			}
+			if via != tt.wantVia {
+				t.Errorf("via = %q, want %q", via, tt.wantVia)
+			}
+			var stale []string
+			for _, network := range status.Stale {
+				stale = append(stale, network.Name)
+			}
+			if !reflect.DeepEqual(stale, tt.wantStale) {
+				t.Errorf("stale = %v, want %v", stale, tt.wantStale)
+			}
+		})
+	}
+}
@@ -0,0 +1,259 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// RequestConfig is the "requests" section of the config file
+type RequestConfig struct {
+	Dir string `json:"dir,omitempty"` // Shared queue directory; defaults to requests/ next to the config file
+}
+
+// Access request statuses
+const (
+	RequestPending  = "pending"
+	RequestApproved = "approved"
+	RequestRejected = "rejected"
+	RequestFailed   = "failed" // Approved, but adding the network failed
+)
+
+// AccessRequest asks an approver to add a network to a resource
+type AccessRequest struct {
+	ID        string      `json:"id"`
+	CreatedAt time.Time   `json:"created_at"`
+	Requester string      `json:"requester"` // gcloud account
+	OSUser    string      `json:"os_user"`
+	Resource  ResourceRef `json:"resource"`
+	Name      string      `json:"name"`
+	CIDR      string      `json:"cidr"`
+	Reason    string      `json:"reason"`
+	Status    string      `json:"status"`
+	DecidedAt *time.Time  `json:"decided_at,omitempty"`
+	DecidedBy string      `json:"decided_by,omitempty"`
+	Comment   string      `json:"comment,omitempty"`
+	Error     string      `json:"error,omitempty"`
+}
+
+// RequestQueue keeps one JSON file per access request in a directory that
+// requesters and approvers share
+type RequestQueue struct {
+	dir string
+}
+
+// NewRequestQueue creates a request queue from config
+func NewRequestQueue(config RequestConfig) *RequestQueue {
+	dir := config.Dir
+	if dir == "" {
+		dir = filepath.Join(configDir(), "requests")
+	}
+	return &RequestQueue{dir: expandHome(dir)}
+}
+
+// Dir returns the queue directory
+func (q *RequestQueue) Dir() string {
+	return q.dir
+}
+
+// Submit validates and queues a new pending request, filling in its ID, time and requester
+func (q *RequestQueue) Submit(request *AccessRequest) error {
+	request.Name = strings.TrimSpace(request.Name)
+	request.Reason = strings.TrimSpace(request.Reason)
+	if request.Name == "" {
+		return fmt.Errorf("network name is required")
+	}
+	if request.Reason == "" {
+		return fmt.Errorf("a reason is required")
+	}
+	cidr, _ := normalizeIP(strings.TrimSpace(request.CIDR))
+	if _, _, err := net.ParseCIDR(cidr); err != nil {
+		return fmt.Errorf("invalid IP address or CIDR %q", request.CIDR)
+	}
+	request.CIDR = cidr
+
+	request.CreatedAt = time.Now().UTC()
+	request.ID = newSnapshotID(request.CreatedAt)
+	request.Requester = getGcloudAccount()
+	request.OSUser = getUserName()
+	request.Status = RequestPending
+	return q.save(request)
+}
+
+// save writes a request atomically, so readers never see a partial file
+func (q *RequestQueue) save(request *AccessRequest) error {
+	data, err := json.MarshalIndent(request, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode request: %v", err)
+	}
+	// Group-writable, since the directory is shared between requesters and approvers
+	if err := os.MkdirAll(q.dir, 0o770); err != nil {
+		return fmt.Errorf("failed to create request directory: %v", err)
+	}
+
+	path := filepath.Join(q.dir, request.ID+".json")
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o660); err != nil {
+		return fmt.Errorf("failed to write request: %v", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("failed to write request: %v", err)
+	}
+	return nil
+}
+
+// Load reads a request by ID
+func (q *RequestQueue) Load(id string) (*AccessRequest, error) {
+	if id == "" || strings.ContainsAny(id, `/\`) {
+		return nil, fmt.Errorf("invalid request ID %q", id)
+	}
+
+	data, err := os.ReadFile(filepath.Join(q.dir, id+".json"))
+	if os.IsNotExist(err) {
+		return nil, fmt.Errorf("request %s not found in %s", id, q.dir)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read request: %v", err)
+	}
+
+	var request AccessRequest
+	if err := json.Unmarshal(data, &request); err != nil {
+		return nil, fmt.Errorf("failed to parse request %s: %v", id, err)
+	}
+	return &request, nil
+}
+
+// List returns requests with the given status (all when empty), oldest first
+func (q *RequestQueue) List(status string) ([]AccessRequest, error) {
+	files, err := filepath.Glob(filepath.Join(q.dir, "*.json"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list requests: %v", err)
+	}
+
+	var requests []AccessRequest
+	for _, file := range files {
+		request, err := q.Load(strings.TrimSuffix(filepath.Base(file), ".json"))
+		if err != nil {
+			continue
+		}
+		if status != "" && request.Status != status {
+			continue
+		}
+		requests = append(requests, *request)
+	}
+
+	sort.Slice(requests, func(i, j int) bool {
+		return requests[i].ID < requests[j].ID
+	})
+	return requests, nil
+}
+
+// claim locks a pending request so two approvers can't decide it at once.
+// The returned function releases the lock.
+func (q *RequestQueue) claim(id string) (*AccessRequest, func(), error) {
+	lockPath := filepath.Join(q.dir, id+".lock")
+	lock, err := os.OpenFile(lockPath, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o660)
+	if os.IsExist(err) {
+		return nil, nil, fmt.Errorf("request %s is being decided by someone else", id)
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to lock request: %v", err)
+	}
+	fmt.Fprintln(lock, getGcloudAccount())
+	lock.Close()
+	release := func() { os.Remove(lockPath) }
+
+	request, err := q.Load(id)
+	if err != nil {
+		release()
+		return nil, nil, err
+	}
+	if request.Status != RequestPending {
+		release()
+		return nil, nil, fmt.Errorf("request %s is already %s", id, request.Status)
+	}
+	return request, release, nil
+}
+
+// decide records an approver's decision on a claimed request
+func (q *RequestQueue) decide(request *AccessRequest, status, comment string, err error) error {
+	now := time.Now().UTC()
+	request.Status = status
+	request.DecidedAt = &now
+	request.DecidedBy = getGcloudAccount()
+	request.Comment = strings.TrimSpace(comment)
+	request.Error = ""
+	if err != nil {
+		request.Error = err.Error()
+	}
+	return q.save(request)
+}
+
+// Reject closes a pending request with a comment for the requester
+func (q *RequestQueue) Reject(id, comment string) error {
+	if strings.TrimSpace(comment) == "" {
+		return fmt.Errorf("a comment is required to reject a request")
+	}
+	request, release, err := q.claim(id)
+	if err != nil {
+		return err
+	}
+	defer release()
+	return q.decide(request, RequestRejected, comment, nil)
+}
+
+// EvaluateRequest explains why a request can't be approved as it stands, or returns nil
+func EvaluateRequest(request AccessRequest, resource CloudResource, policy *Policy) []PolicyViolation {
+	if !resource.CanAddNetwork() {
+		return []PolicyViolation{{Rule: "resource", Message: resource.GetNetworkRestrictions()}}
+	}
+	for _, network := range resourceNetworks(resource) {
+		if value, _ := normalizeIP(network.Value); value == request.CIDR {
+			return []PolicyViolation{{Rule: "duplicate", Message: fmt.Sprintf("%s is already authorized as %q", request.CIDR, networkName(network))}}
+		}
+	}
+	return policy.Evaluate(resource, request.Name, request.CIDR, resourceNetworks(resource))
+}
+
+// ApproveRequest adds a pending request's network to its resource and records the outcome in the queue
+func (nm *NetworkManager) ApproveRequest(queue *RequestQueue, id, comment string) error {
+	request, release, err := queue.claim(id)
+	if err != nil {
+		return err
+	}
+	defer release()
+
+	placeholder, err := request.Resource.Resource()
+	if err != nil {
+		return err
+	}
+	resource, err := nm.GetResourceDetails(placeholder)
+	if err != nil {
+		return err
+	}
+
+	// Leave the request pending when policy or the resource rules it out, so it can be rejected with a reason
+	if violations := EvaluateRequest(*request, resource, nm.policy); len(violations) > 0 {
+		return &PolicyError{Violations: violations}
+	}
+
+	detail := fmt.Sprintf("%s %s requested by %s (request %s)", request.Name, request.CIDR, request.Requester, request.ID)
+	err = nm.audited("approve_request", resource, detail, func(CloudResource) (string, error) {
+		return nm.addNetwork(resource, request.Name, request.CIDR)
+	})
+
+	status := RequestApproved
+	if err != nil {
+		status = RequestFailed
+	}
+	if decideErr := queue.decide(request, status, comment, err); decideErr != nil && err == nil {
+		return fmt.Errorf("network added but %v", decideErr)
+	}
+	return err
+}
@@ -0,0 +1,242 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	logging "google.golang.org/api/logging/v2"
+	"google.golang.org/api/option"
+)
+
+// envLoggingEndpoint overrides the Cloud Logging endpoint (e.g. a local stub); requests are then unauthenticated
+const envLoggingEndpoint = "PIAM_ANC_LOGGING_ENDPOINT"
+
+const (
+	// Admin Activity audit logs are retained for 400 days
+	attributionLookback = 400 * 24 * time.Hour
+	attributionCacheTTL = 10 * time.Minute
+	attributionMaxPages = 20
+)
+
+// NetworkAttribution records who added an authorized network and when
+type NetworkAttribution struct {
+	Principal string
+	Timestamp time.Time
+	Before    bool // Already listed in the oldest request read, so added by Timestamp by someone unknown
+}
+
+// AddedBy is who added the network, or "unknown"
+func (a NetworkAttribution) AddedBy() string {
+	if a.Before || a.Principal == "" {
+		return "unknown"
+	}
+	return a.Principal
+}
+
+// AddedAt formats when the network was added, e.g. "2024-05-01 10:00" or "before 2024-05-01"
+func (a NetworkAttribution) AddedAt(layout string) string {
+	if a.Before {
+		return "before " + a.Timestamp.Local().Format("2006-01-02")
+	}
+	return a.Timestamp.Local().Format(layout)
+}
+
+type attributionCacheEntry struct {
+	attribution map[string]NetworkAttribution
+	fetched     time.Time
+}
+
+// The cache is shared by every NetworkManager, since one is created per command
+var (
+	attributionCacheMu sync.Mutex
+	attributionCache   = make(map[string]attributionCacheEntry)
+)
+
+// invalidateAttribution drops cached attribution for a resource after it changes
+func invalidateAttribution(resource CloudResource) {
+	attributionCacheMu.Lock()
+	delete(attributionCache, resourceKey(resource))
+	attributionCacheMu.Unlock()
+}
+
+// GetNetworkAttribution returns who added each authorized network of a resource, keyed by CIDR.
+// Only Cloud SQL and GKE are supported; other resource types return nil.
+func (nm *NetworkManager) GetNetworkAttribution(resource CloudResource) (map[string]NetworkAttribution, error) {
+	var filter string
+	switch r := resource.(type) {
+	case SQLInstance:
+		filter = fmt.Sprintf(`protoPayload.serviceName="cloudsql.googleapis.com" AND `+
+			`protoPayload.methodName=("cloudsql.instances.patch" OR "cloudsql.instances.update") AND `+
+			`resource.labels.database_id="%s:%s"`, r.Project, r.Name)
+	case GKECluster:
+		filter = fmt.Sprintf(`protoPayload.serviceName="container.googleapis.com" AND `+
+			`protoPayload.methodName:"UpdateCluster" AND `+
+			`resource.labels.cluster_name="%s" AND resource.labels.location="%s"`, r.Name, r.Location)
+	default:
+		return nil, nil
+	}
+
+	key := resourceKey(resource)
+	attributionCacheMu.Lock()
+	cached, ok := attributionCache[key]
+	attributionCacheMu.Unlock()
+	if ok && time.Since(cached.fetched) < attributionCacheTTL {
+		return cached.attribution, nil
+	}
+
+	service, err := newLoggingService(nm.ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	project := resource.GetProject()
+	filter = fmt.Sprintf(`logName="projects/%s/logs/cloudaudit.googleapis.com%%2Factivity" AND %s AND timestamp>="%s"`,
+		project, filter, time.Now().Add(-attributionLookback).UTC().Format(time.RFC3339))
+
+	// Read newest first, so a busy log loses its oldest requests rather than its latest
+	var entries []*logging.LogEntry
+	request := &logging.ListLogEntriesRequest{
+		ResourceNames: []string{"projects/" + project},
+		Filter:        filter,
+		OrderBy:       "timestamp desc",
+		PageSize:      1000,
+	}
+	for page := 0; page < attributionMaxPages; page++ {
+		response, err := service.Entries.List(request).Context(nm.ctx).Do()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read audit logs: %v", err)
+		}
+		entries = append(entries, response.Entries...)
+		if response.NextPageToken == "" {
+			break
+		}
+		request.PageToken = response.NextPageToken
+	}
+	for i, j := 0, len(entries)-1; i < j; i, j = i+1, j-1 {
+		entries[i], entries[j] = entries[j], entries[i]
+	}
+
+	attribution := attributeNetworks(entries)
+
+	attributionCacheMu.Lock()
+	attributionCache[key] = attributionCacheEntry{attribution: attribution, fetched: time.Now()}
+	attributionCacheMu.Unlock()
+
+	return attribution, nil
+}
+
+// newLoggingService creates a Cloud Logging client, honouring the endpoint override
+func newLoggingService(ctx context.Context) (*logging.Service, error) {
+	var opts []option.ClientOption
+	if endpoint := strings.TrimSpace(os.Getenv(envLoggingEndpoint)); endpoint != "" {
+		opts = append(opts, option.WithEndpoint(endpoint), option.WithoutAuthentication())
+	}
+
+	service, err := logging.NewService(ctx, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create Logging service: %v", err)
+	}
+	return service, nil
+}
+
+// auditPayload is the part of an AuditLog proto payload used for attribution
+type auditPayload struct {
+	AuthenticationInfo struct {
+		PrincipalEmail string `json:"principalEmail"`
+	} `json:"authenticationInfo"`
+	Status *struct {
+		Code int `json:"code"`
+	} `json:"status"`
+	Request map[string]interface{} `json:"request"`
+}
+
+// attributeNetworks diffs consecutive update requests, oldest first, crediting each
+// CIDR to the first request that added it. CIDRs removed and re-added are credited
+// to the later request. Requests that didn't touch the network list are ignored.
+// The oldest request only shows what was already there: its CIDRs are marked Before.
+func attributeNetworks(entries []*logging.LogEntry) map[string]NetworkAttribution {
+	attribution := make(map[string]NetworkAttribution)
+	var previous map[string]bool // nil until the oldest request is seen
+
+	for _, entry := range entries {
+		var payload auditPayload
+		if err := json.Unmarshal(entry.ProtoPayload, &payload); err != nil {
+			continue
+		}
+		if payload.Status != nil && payload.Status.Code != 0 {
+			continue
+		}
+
+		cidrs, ok := requestedCIDRs(payload.Request)
+		if !ok {
+			continue
+		}
+		timestamp, _ := time.Parse(time.RFC3339Nano, entry.Timestamp)
+
+		current := make(map[string]bool)
+		for _, cidr := range cidrs {
+			current[cidr] = true
+			switch {
+			case previous == nil:
+				attribution[cidr] = NetworkAttribution{Timestamp: timestamp, Before: true}
+			case !previous[cidr]:
+				attribution[cidr] = NetworkAttribution{
+					Principal: payload.AuthenticationInfo.PrincipalEmail,
+					Timestamp: timestamp,
+				}
+			}
+		}
+		previous = current
+	}
+
+	return attribution
+}
+
+// requestedCIDRs finds the full network list in an update request.
+// Cloud SQL sends settings.ipConfiguration.authorizedNetworks[].value and GKE sends
+// update.desiredMasterAuthorizedNetworksConfig.cidrBlocks[].cidrBlock.
+func requestedCIDRs(request map[string]interface{}) ([]string, bool) {
+	for key, value := range request {
+		switch key {
+		case "authorizedNetworks":
+			return listField(value, "value"), true
+		case "cidrBlocks":
+			return listField(value, "cidrBlock"), true
+		}
+		if nested, ok := value.(map[string]interface{}); ok {
+			if cidrs, found := requestedCIDRs(nested); found {
+				return cidrs, true
+			}
+		}
+	}
+	return nil, false
+}
+
+// listField collects a string field from each object of a JSON array, normalising CIDRs
+func listField(value interface{}, field string) []string {
+	items, _ := value.([]interface{})
+	var values []string
+	for _, item := range items {
+		object, ok := item.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		if s, ok := object[field].(string); ok && s != "" {
+			normalized, _ := normalizeIP(s)
+			values = append(values, normalized)
+		}
+	}
+	return values
+}
+
+// lookupAttribution finds the attribution of a network entry, if known
+func lookupAttribution(attribution map[string]NetworkAttribution, network AuthorizedNetwork) (NetworkAttribution, bool) {
+	normalized, _ := normalizeIP(network.Value)
+	a, ok := attribution[normalized]
+	return a, ok
+}
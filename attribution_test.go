@@ -0,0 +1,181 @@
+package main
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+
+	logging "google.golang.org/api/logging/v2"
+)
+
+// sqlPatchEntry builds an audit log entry for a Cloud SQL patch setting the given networks
+func sqlPatchEntry(t *testing.T, timestamp, principal string, code int, cidrs ...string) *logging.LogEntry {
+	t.Helper()
+	networks := make([]map[string]string, len(cidrs))
+	for i, cidr := range cidrs {
+		networks[i] = map[string]string{"value": cidr}
+	}
+	payload := map[string]interface{}{
+		"authenticationInfo": map[string]string{"principalEmail": principal},
+		"request": map[string]interface{}{
+			"body": map[string]interface{}{
+				"settings": map[string]interface{}{
+					"ipConfiguration": map[string]interface{}{"authorizedNetworks": networks},
+				},
+			},
+		},
+	}
+	if code != 0 {
+		payload["status"] = map[string]int{"code": code}
+	}
+	data, err := json.Marshal(payload)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &logging.LogEntry{Timestamp: timestamp, ProtoPayload: data}
+}
+
+// gkeUpdateEntry builds an audit log entry for a GKE cluster update setting the given networks
+func gkeUpdateEntry(t *testing.T, timestamp, principal string, cidrs ...string) *logging.LogEntry {
+	t.Helper()
+	blocks := make([]map[string]string, len(cidrs))
+	for i, cidr := range cidrs {
+		blocks[i] = map[string]string{"cidrBlock": cidr}
+	}
+	data, err := json.Marshal(map[string]interface{}{
+		"authenticationInfo": map[string]string{"principalEmail": principal},
+		"request": map[string]interface{}{
+			"update": map[string]interface{}{
+				"desiredMasterAuthorizedNetworksConfig": map[string]interface{}{"cidrBlocks": blocks},
+			},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &logging.LogEntry{Timestamp: timestamp, ProtoPayload: data}
+}
+
+func TestAttributeNetworks(t *testing.T) {
+	const (
+		day1 = "2026-01-01T10:00:00Z"
+		day2 = "2026-01-02T10:00:00Z"
+		day3 = "2026-01-03T10:00:00Z"
+	)
+	at := func(timestamp string) time.Time {
+		parsed, _ := time.Parse(time.RFC3339, timestamp)
+		return parsed
+	}
+
+	tests := []struct {
+		name    string
+		entries func(t *testing.T) []*logging.LogEntry
+		want    map[string]NetworkAttribution
+	}{
+		{
+			name:    "no requests",
+			entries: func(t *testing.T) []*logging.LogEntry { return nil },
+			want:    map[string]NetworkAttribution{},
+		},
+		{
+			name: "oldest request only seeds the list",
+			entries: func(t *testing.T) []*logging.LogEntry {
+				return []*logging.LogEntry{
+					sqlPatchEntry(t, day1, "alice@example.com", 0, "203.0.113.0/24", "198.51.100.0/24"),
+				}
+			},
+			want: map[string]NetworkAttribution{
+				"203.0.113.0/24":  {Timestamp: at(day1), Before: true},
+				"198.51.100.0/24": {Timestamp: at(day1), Before: true},
+			},
+		},
+		{
+			name: "later additions are credited",
+			entries: func(t *testing.T) []*logging.LogEntry {
+				return []*logging.LogEntry{
+					sqlPatchEntry(t, day1, "alice@example.com", 0, "203.0.113.0/24"),
+					sqlPatchEntry(t, day2, "bob@example.com", 0, "203.0.113.0/24", "192.0.2.1"),
+					sqlPatchEntry(t, day3, "carol@example.com", 0, "203.0.113.0/24", "192.0.2.1/32", "192.0.2.2/32"),
+				}
+			},
+			want: map[string]NetworkAttribution{
+				"203.0.113.0/24": {Timestamp: at(day1), Before: true},
+				"192.0.2.1/32":   {Principal: "bob@example.com", Timestamp: at(day2)},
+				"192.0.2.2/32":   {Principal: "carol@example.com", Timestamp: at(day3)},
+			},
+		},
+		{
+			name: "removed and re-added goes to the later request",
+			entries: func(t *testing.T) []*logging.LogEntry {
+				return []*logging.LogEntry{
+					sqlPatchEntry(t, day1, "alice@example.com", 0, "203.0.113.0/24"),
+					sqlPatchEntry(t, day2, "bob@example.com", 0),
+					sqlPatchEntry(t, day3, "carol@example.com", 0, "203.0.113.0/24"),
+				}
+			},
+			want: map[string]NetworkAttribution{
+				"203.0.113.0/24": {Principal: "carol@example.com", Timestamp: at(day3)},
+			},
+		},
+		{
+			name: "failed requests are ignored",
+			entries: func(t *testing.T) []*logging.LogEntry {
+				return []*logging.LogEntry{
+					sqlPatchEntry(t, day1, "alice@example.com", 7, "203.0.113.0/24"),
+					sqlPatchEntry(t, day2, "bob@example.com", 0, "198.51.100.0/24"),
+					sqlPatchEntry(t, day3, "carol@example.com", 0, "198.51.100.0/24", "203.0.113.0/24"),
+				}
+			},
+			want: map[string]NetworkAttribution{
+				"198.51.100.0/24": {Timestamp: at(day2), Before: true},
+				"203.0.113.0/24":  {Principal: "carol@example.com", Timestamp: at(day3)},
+			},
+		},
+		{
+			name: "GKE cluster updates",
+			entries: func(t *testing.T) []*logging.LogEntry {
+				return []*logging.LogEntry{
+					gkeUpdateEntry(t, day1, "alice@example.com", "203.0.113.0/24"),
+					gkeUpdateEntry(t, day2, "bob@example.com", "203.0.113.0/24", "198.51.100.0/24"),
+				}
+			},
+			want: map[string]NetworkAttribution{
+				"203.0.113.0/24":  {Timestamp: at(day1), Before: true},
+				"198.51.100.0/24": {Principal: "bob@example.com", Timestamp: at(day2)},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := attributeNetworks(tt.entries(t))
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("attributeNetworks() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNetworkAttributionLabels(t *testing.T) {
+	timestamp := time.Date(2026, 1, 2, 10, 30, 0, 0, time.Local)
+	tests := []struct {
+		name        string
+		attribution NetworkAttribution
+		wantBy      string
+		wantAt      string
+	}{
+		{"known", NetworkAttribution{Principal: "bob@example.com", Timestamp: timestamp}, "bob@example.com", "2026-01-02 10:30"},
+		{"before the oldest request", NetworkAttribution{Timestamp: timestamp, Before: true}, "unknown", "before 2026-01-02"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.attribution.AddedBy(); got != tt.wantBy {
+				t.Errorf("AddedBy() = %q, want %q", got, tt.wantBy)
+			}
+			if got := tt.attribution.AddedAt("2006-01-02 15:04"); got != tt.wantAt {
+				t.Errorf("AddedAt() = %q, want %q", got, tt.wantAt)
+			}
+		})
+	}
+}
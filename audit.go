@@ -0,0 +1,337 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Audit outcomes
+const (
+	AuditOutcomeSuccess = "success"
+	AuditOutcomeFailure = "failure"
+)
+
+// AuditConfig is the "audit" section of the config file
+type AuditConfig struct {
+	Path     string `json:"path,omitempty"`     // Defaults to audit.jsonl next to the config file
+	Disabled bool   `json:"disabled,omitempty"` // Turns off audit logging entirely
+}
+
+// AuditEntry is one line of the audit log, describing a single mutation
+type AuditEntry struct {
+	Timestamp    time.Time           `json:"timestamp"`
+	Account      string              `json:"account"` // gcloud account
+	OSUser       string              `json:"os_user"`
+	Operation    string              `json:"operation"`
+	ResourceType ResourceType        `json:"resource_type"`
+	Project      string              `json:"project"`
+	Location     string              `json:"location"`
+	Resource     string              `json:"resource"`
+	Detail       string              `json:"detail,omitempty"`
+	SnapshotID   string              `json:"snapshot_id,omitempty"`
+	Before       []AuthorizedNetwork `json:"before"`
+	After        []AuthorizedNetwork `json:"after"`
+	PSCBefore    []string            `json:"psc_before,omitempty"` // PSC allowed consumer projects, for SQL instances with PSC
+	PSCAfter     []string            `json:"psc_after,omitempty"`
+	OperationID  string              `json:"operation_id,omitempty"`
+	Outcome      string              `json:"outcome"`
+	Error        string              `json:"error,omitempty"`
+}
+
+// AuditFilter selects entries when reading the audit log; zero values match everything
+type AuditFilter struct {
+	Resource string // Matches the resource name or "project/name"
+	User     string // Matches the gcloud account or OS user
+	Since    time.Time
+	Until    time.Time
+}
+
+// AuditLog appends entries to a JSONL file
+type AuditLog struct {
+	path string
+	mu   sync.Mutex
+}
+
+// NewAuditLog creates an audit log from config, or returns nil when auditing is disabled
+func NewAuditLog(config AuditConfig) *AuditLog {
+	if config.Disabled {
+		return nil
+	}
+	path := config.Path
+	if path == "" {
+		path = filepath.Join(configDir(), "audit.jsonl")
+	}
+	return &AuditLog{path: expandHome(path)}
+}
+
+// Path returns the location of the audit log file
+func (a *AuditLog) Path() string {
+	return a.path
+}
+
+// Append writes one entry to the end of the log
+func (a *AuditLog) Append(entry AuditEntry) error {
+	if a == nil {
+		return nil
+	}
+
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("failed to encode audit entry: %v", err)
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if err := os.MkdirAll(filepath.Dir(a.path), 0o700); err != nil {
+		return fmt.Errorf("failed to create audit log directory: %v", err)
+	}
+
+	file, err := os.OpenFile(a.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return fmt.Errorf("failed to open audit log: %v", err)
+	}
+	defer file.Close()
+
+	if _, err := file.Write(append(data, '\n')); err != nil {
+		return fmt.Errorf("failed to write audit log: %v", err)
+	}
+	return nil
+}
+
+// Read returns the entries matching a filter, oldest first
+func (a *AuditLog) Read(filter AuditFilter) ([]AuditEntry, error) {
+	if a == nil {
+		return nil, nil
+	}
+
+	file, err := os.Open(a.path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to open audit log: %v", err)
+	}
+	defer file.Close()
+
+	var entries []AuditEntry
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+
+		var entry AuditEntry
+		if err := json.Unmarshal([]byte(line), &entry); err != nil {
+			// Skip damaged lines rather than hiding the rest of the history
+			continue
+		}
+		if filter.matches(entry) {
+			entries = append(entries, entry)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return entries, fmt.Errorf("failed to read audit log: %v", err)
+	}
+
+	return entries, nil
+}
+
+// matches reports whether an entry passes the filter
+func (f AuditFilter) matches(entry AuditEntry) bool {
+	if f.Resource != "" && f.Resource != entry.Resource && f.Resource != entry.Project+"/"+entry.Resource {
+		return false
+	}
+	if f.User != "" && !strings.EqualFold(f.User, entry.Account) && !strings.EqualFold(f.User, entry.OSUser) {
+		return false
+	}
+	if !f.Since.IsZero() && entry.Timestamp.Before(f.Since) {
+		return false
+	}
+	if !f.Until.IsZero() && !entry.Timestamp.Before(f.Until) {
+		return false
+	}
+	return true
+}
+
+var (
+	gcloudAccountOnce sync.Once
+	gcloudAccount     string
+)
+
+// getGcloudAccount returns the active gcloud account, looked up once per process
+func getGcloudAccount() string {
+	gcloudAccountOnce.Do(func() {
+		output, err := exec.Command("gcloud", "config", "get-value", "account").Output()
+		if err == nil {
+			gcloudAccount = strings.TrimSpace(string(output))
+		}
+	})
+	return gcloudAccount
+}
+
+// audited snapshots a resource's networks, runs a mutation and records it, with the
+// network lists before and after, in the audit log. Nothing is changed if the
+// snapshot can't be written. Every mutation goes through here, so in dry-run mode
+// this is where it stops. mutate is given the freshly fetched resource.
+func (nm *NetworkManager) audited(operation string, resource CloudResource, detail string, mutate func(current CloudResource) (string, error)) error {
+	if nm.dryRun {
+		return errDryRun
+	}
+
+	before := resourceNetworks(resource)
+	if current, err := nm.GetResourceDetails(resource); err == nil {
+		resource = current
+		before = resourceNetworks(current)
+	}
+
+	// A resource mid-operation or stopped rejects the patch; say so before snapshotting
+	timeout := time.Duration(0)
+	if nm.waitForReady {
+		timeout = readyWaitTimeout
+	}
+	resource, err := nm.waitUntilPatchable(resource, timeout)
+	if err != nil {
+		return err
+	}
+	before = resourceNetworks(resource)
+	pscBefore, _ := pscConsumerProjects(resource)
+
+	snapshot := &Snapshot{
+		Account:   getGcloudAccount(),
+		OSUser:    getUserName(),
+		Operation: operation,
+		Detail:    detail,
+		Resource:  resourceRefOf(resource),
+		Networks:  before,
+	}
+	snapshot.recordSettings(resource)
+	if nm.snapshots != nil {
+		if err := nm.snapshots.Save(snapshot); err != nil {
+			return fmt.Errorf("no change made: %v", err)
+		}
+	}
+
+	operationID, err := mutate(resource)
+	invalidateAttribution(resource)
+
+	after, pscAfter := before, pscBefore
+	if current, detailsErr := nm.GetResourceDetails(resource); detailsErr == nil {
+		after = resourceNetworks(current)
+		pscAfter, _ = pscConsumerProjects(current)
+	}
+
+	entry := AuditEntry{
+		Timestamp:    time.Now().UTC(),
+		Account:      getGcloudAccount(),
+		OSUser:       getUserName(),
+		Operation:    operation,
+		ResourceType: resource.GetType(),
+		Project:      resource.GetProject(),
+		Location:     resource.GetRegion(),
+		Resource:     resource.GetName(),
+		Detail:       detail,
+		SnapshotID:   snapshot.ID,
+		Before:       before,
+		After:        after,
+		PSCBefore:    pscBefore,
+		PSCAfter:     pscAfter,
+		OperationID:  operationID,
+		Outcome:      AuditOutcomeSuccess,
+	}
+	if err != nil {
+		entry.Outcome = AuditOutcomeFailure
+		entry.Error = err.Error()
+	}
+
+	if auditErr := nm.audit.Append(entry); auditErr != nil && err == nil {
+		return fmt.Errorf("change applied but %v", auditErr)
+	}
+
+	return err
+}
+
+// expandHome replaces a leading ~ with the user's home directory
+func expandHome(path string) string {
+	if path == "~" || strings.HasPrefix(path, "~/") {
+		if home, err := os.UserHomeDir(); err == nil {
+			return filepath.Join(home, strings.TrimPrefix(path, "~"))
+		}
+	}
+	return path
+}
+
+// pscConsumerProjects returns a SQL instance's PSC allowed consumer projects, and whether it has PSC enabled
+func pscConsumerProjects(resource CloudResource) ([]string, bool) {
+	instance, ok := resource.(SQLInstance)
+	if !ok || !instance.PSCEnabled {
+		return nil, false
+	}
+	return instance.AllowedConsumerProjects, true
+}
+
+// auditChangeSummary describes the networks and PSC consumer projects an entry added and removed
+func auditChangeSummary(entry AuditEntry) string {
+	before := make(map[string]bool)
+	for _, network := range entry.Before {
+		before[network.Value] = true
+	}
+	after := make(map[string]bool)
+	for _, network := range entry.After {
+		after[network.Value] = true
+	}
+
+	var changes []string
+	for _, network := range entry.After {
+		if !before[network.Value] {
+			changes = append(changes, "+"+network.Value)
+			before[network.Value] = true
+		}
+	}
+	for _, network := range entry.Before {
+		if !after[network.Value] {
+			changes = append(changes, "-"+network.Value)
+			after[network.Value] = true
+		}
+	}
+	changes = append(changes, projectChanges(entry.PSCBefore, entry.PSCAfter)...)
+
+	if len(changes) == 0 {
+		return entry.Detail
+	}
+	return strings.Join(changes, " ")
+}
+
+// projectChanges lists the PSC consumer projects added ("+psc:p") and removed ("-psc:p")
+func projectChanges(before, after []string) []string {
+	inBefore := make(map[string]bool)
+	for _, project := range before {
+		inBefore[project] = true
+	}
+	inAfter := make(map[string]bool)
+	for _, project := range after {
+		inAfter[project] = true
+	}
+
+	var changes []string
+	for _, project := range after {
+		if !inBefore[project] {
+			changes = append(changes, "+psc:"+project)
+		}
+	}
+	for _, project := range before {
+		if !inAfter[project] {
+			changes = append(changes, "-psc:"+project)
+		}
+	}
+	return changes
+}
@@ -0,0 +1,375 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awsconfig "github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	ec2types "github.com/aws/aws-sdk-go-v2/service/ec2/types"
+)
+
+// Environment variables that configure the AWS provider
+const (
+	envAWSRegions  = "PIAM_ANC_AWS_REGIONS"  // Comma-separated regions to scan; enables the provider
+	envAWSEndpoint = "PIAM_ANC_AWS_ENDPOINT" // Optional EC2 endpoint override (e.g. a local stand-in)
+	envAWSTagKey   = "PIAM_ANC_AWS_TAG"      // Tag key marking managed security groups
+)
+
+const defaultAWSTagKey = "piam-anc"
+
+// AWSSecurityGroup represents an EC2 security group managed by piam-anc
+type AWSSecurityGroup struct {
+	GroupID            string
+	GroupName          string
+	Account            string
+	Region             string
+	VpcID              string
+	Description        string
+	ManagedPorts       []int32
+	Tags               map[string]string
+	AuthorizedNetworks []AuthorizedNetwork
+}
+
+func (a AWSSecurityGroup) GetName() string       { return a.GroupName }
+func (a AWSSecurityGroup) GetProject() string    { return a.Account }
+func (a AWSSecurityGroup) GetRegion() string     { return a.Region }
+func (a AWSSecurityGroup) GetType() ResourceType { return ResourceTypeAWS }
+func (a AWSSecurityGroup) GetDisplayName() string {
+	return fmt.Sprintf("%s / %s (%s)", a.GroupName, a.GroupID, a.Account)
+}
+func (a AWSSecurityGroup) HasPublicIP() bool            { return true }
+func (a AWSSecurityGroup) CanAddNetwork() bool          { return len(a.ManagedPorts) > 0 }
+func (a AWSSecurityGroup) GetLabels() map[string]string { return a.Tags }
+func (a AWSSecurityGroup) GetNetworkRestrictions() string {
+	if len(a.ManagedPorts) == 0 {
+		return "No managed ports - set the piam-anc tag to a port list (e.g. 5432)"
+	}
+	return ""
+}
+
+// awsProvider lists and updates security groups across the configured regions
+type awsProvider struct {
+	regions []string
+	tagKey  string
+	clients map[string]*ec2.Client
+}
+
+// newAWSProvider creates the AWS provider, or returns nil when it is not configured
+func newAWSProvider(ctx context.Context) (*awsProvider, error) {
+	regionsEnv := strings.TrimSpace(os.Getenv(envAWSRegions))
+	if regionsEnv == "" {
+		return nil, nil
+	}
+
+	tagKey := strings.TrimSpace(os.Getenv(envAWSTagKey))
+	if tagKey == "" {
+		tagKey = defaultAWSTagKey
+	}
+	endpoint := strings.TrimSpace(os.Getenv(envAWSEndpoint))
+
+	provider := &awsProvider{
+		tagKey:  tagKey,
+		clients: make(map[string]*ec2.Client),
+	}
+
+	for _, region := range strings.Split(regionsEnv, ",") {
+		region = strings.TrimSpace(region)
+		if region == "" || provider.clients[region] != nil {
+			continue
+		}
+
+		cfg, err := awsconfig.LoadDefaultConfig(ctx, awsconfig.WithRegion(region))
+		if err != nil {
+			return nil, fmt.Errorf("failed to load AWS config for region %s: %v", region, err)
+		}
+
+		provider.clients[region] = ec2.NewFromConfig(cfg, func(o *ec2.Options) {
+			if endpoint != "" {
+				o.BaseEndpoint = aws.String(endpoint)
+			}
+		})
+		provider.regions = append(provider.regions, region)
+	}
+
+	return provider, nil
+}
+
+// client returns the EC2 client for a region
+func (p *awsProvider) client(region string) (*ec2.Client, error) {
+	client, ok := p.clients[region]
+	if !ok {
+		return nil, fmt.Errorf("AWS region %s is not configured", region)
+	}
+	return client, nil
+}
+
+// listSecurityGroups gets all managed security groups in a region
+func (p *awsProvider) listSecurityGroups(ctx context.Context, region string) ([]CloudResource, error) {
+	client, err := p.client(region)
+	if err != nil {
+		return nil, err
+	}
+
+	input := &ec2.DescribeSecurityGroupsInput{
+		Filters: []ec2types.Filter{
+			{Name: aws.String("tag-key"), Values: []string{p.tagKey}},
+		},
+	}
+
+	var resources []CloudResource
+	paginator := ec2.NewDescribeSecurityGroupsPaginator(client, input)
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list AWS security groups in region %s: %v", region, err)
+		}
+		for _, group := range page.SecurityGroups {
+			resources = append(resources, p.convertSecurityGroup(region, group))
+		}
+	}
+
+	return resources, nil
+}
+
+// getSecurityGroup fetches a single security group
+func (p *awsProvider) getSecurityGroup(ctx context.Context, region, groupID string) (ec2types.SecurityGroup, error) {
+	client, err := p.client(region)
+	if err != nil {
+		return ec2types.SecurityGroup{}, err
+	}
+
+	resp, err := client.DescribeSecurityGroups(ctx, &ec2.DescribeSecurityGroupsInput{
+		GroupIds: []string{groupID},
+	})
+	if err != nil {
+		return ec2types.SecurityGroup{}, fmt.Errorf("failed to get AWS security group %s: %v", groupID, err)
+	}
+	if len(resp.SecurityGroups) == 0 {
+		return ec2types.SecurityGroup{}, fmt.Errorf("AWS security group %s not found", groupID)
+	}
+
+	return resp.SecurityGroups[0], nil
+}
+
+// getSecurityGroupDetails gets detailed info for a security group
+func (p *awsProvider) getSecurityGroupDetails(ctx context.Context, region, groupID string) (CloudResource, error) {
+	group, err := p.getSecurityGroup(ctx, region, groupID)
+	if err != nil {
+		return nil, err
+	}
+	return p.convertSecurityGroup(region, group), nil
+}
+
+// convertSecurityGroup maps an EC2 security group onto AWSSecurityGroup
+func (p *awsProvider) convertSecurityGroup(region string, group ec2types.SecurityGroup) AWSSecurityGroup {
+	sg := AWSSecurityGroup{
+		GroupID:     aws.ToString(group.GroupId),
+		GroupName:   aws.ToString(group.GroupName),
+		Account:     aws.ToString(group.OwnerId),
+		Region:      region,
+		VpcID:       aws.ToString(group.VpcId),
+		Description: aws.ToString(group.Description),
+		Tags:        make(map[string]string),
+	}
+
+	for _, tag := range group.Tags {
+		sg.Tags[aws.ToString(tag.Key)] = aws.ToString(tag.Value)
+	}
+
+	// Managed ports come from the tag value; fall back to the ports already in use
+	sg.ManagedPorts = parsePortList(p.tagValue(group))
+	inferPorts := len(sg.ManagedPorts) == 0
+	seenPorts := make(map[int32]bool)
+
+	for _, perm := range group.IpPermissions {
+		if !isTCPPermission(perm) {
+			continue
+		}
+		port := portLabel(perm)
+		for _, ipRange := range perm.IpRanges {
+			sg.AuthorizedNetworks = append(sg.AuthorizedNetworks, AuthorizedNetwork{
+				Name:  aws.ToString(ipRange.Description),
+				Value: aws.ToString(ipRange.CidrIp),
+				Port:  port,
+			})
+		}
+
+		if inferPorts && len(perm.IpRanges) > 0 && perm.FromPort != nil && perm.ToPort != nil &&
+			*perm.FromPort == *perm.ToPort && !seenPorts[*perm.FromPort] {
+			seenPorts[*perm.FromPort] = true
+			sg.ManagedPorts = append(sg.ManagedPorts, *perm.FromPort)
+		}
+	}
+
+	return sg
+}
+
+// tagValue returns the value of the management tag on a group
+func (p *awsProvider) tagValue(group ec2types.SecurityGroup) string {
+	for _, tag := range group.Tags {
+		if aws.ToString(tag.Key) == p.tagKey {
+			return aws.ToString(tag.Value)
+		}
+	}
+	return ""
+}
+
+// addNetworkToSecurityGroup authorizes a CIDR on every managed port of a security group
+func (p *awsProvider) addNetworkToSecurityGroup(ctx context.Context, region, groupID, networkName, networkIP string) error {
+	normalizedIP, err := normalizeIP(networkIP)
+	if err != nil {
+		return fmt.Errorf("invalid IP format: %v", err)
+	}
+
+	group, err := p.getSecurityGroup(ctx, region, groupID)
+	if err != nil {
+		return err
+	}
+
+	sg := p.convertSecurityGroup(region, group)
+	if len(sg.ManagedPorts) == 0 {
+		return fmt.Errorf("security group %s has no managed ports", groupID)
+	}
+
+	var permissions []ec2types.IpPermission
+	for _, port := range sg.ManagedPorts {
+		if hasIngressRule(group, port, normalizedIP) {
+			continue
+		}
+		permissions = append(permissions, ec2types.IpPermission{
+			IpProtocol: aws.String("tcp"),
+			FromPort:   aws.Int32(port),
+			ToPort:     aws.Int32(port),
+			IpRanges: []ec2types.IpRange{
+				{CidrIp: aws.String(normalizedIP), Description: aws.String(networkName)},
+			},
+		})
+	}
+	if len(permissions) == 0 {
+		return fmt.Errorf("network %s already exists on all managed ports", normalizedIP)
+	}
+
+	client, err := p.client(region)
+	if err != nil {
+		return err
+	}
+
+	_, err = client.AuthorizeSecurityGroupIngress(ctx, &ec2.AuthorizeSecurityGroupIngressInput{
+		GroupId:       aws.String(groupID),
+		IpPermissions: permissions,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to authorize ingress: %v", err)
+	}
+
+	return nil
+}
+
+// removeNetworkFromSecurityGroup revokes a CIDR's single-port TCP ingress rules on the group's
+// managed ports. Rules for other protocols, port ranges or unmanaged ports are left alone.
+func (p *awsProvider) removeNetworkFromSecurityGroup(ctx context.Context, region, groupID, networkIP string) error {
+	normalizedIP, err := normalizeIP(networkIP)
+	if err != nil {
+		return fmt.Errorf("invalid IP format: %v", err)
+	}
+
+	group, err := p.getSecurityGroup(ctx, region, groupID)
+	if err != nil {
+		return err
+	}
+
+	managed := make(map[int32]bool)
+	for _, port := range p.convertSecurityGroup(region, group).ManagedPorts {
+		managed[port] = true
+	}
+
+	var permissions []ec2types.IpPermission
+	for _, perm := range group.IpPermissions {
+		if !isTCPPermission(perm) || perm.FromPort == nil || perm.ToPort == nil ||
+			*perm.FromPort != *perm.ToPort || !managed[*perm.FromPort] {
+			continue
+		}
+		for _, ipRange := range perm.IpRanges {
+			if aws.ToString(ipRange.CidrIp) != normalizedIP {
+				continue
+			}
+			permissions = append(permissions, ec2types.IpPermission{
+				IpProtocol: perm.IpProtocol,
+				FromPort:   perm.FromPort,
+				ToPort:     perm.ToPort,
+				IpRanges:   []ec2types.IpRange{{CidrIp: aws.String(normalizedIP)}},
+			})
+		}
+	}
+	if len(permissions) == 0 {
+		return fmt.Errorf("network %s not found on the managed ports", normalizedIP)
+	}
+
+	client, err := p.client(region)
+	if err != nil {
+		return err
+	}
+
+	_, err = client.RevokeSecurityGroupIngress(ctx, &ec2.RevokeSecurityGroupIngressInput{
+		GroupId:       aws.String(groupID),
+		IpPermissions: permissions,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to revoke ingress: %v", err)
+	}
+
+	return nil
+}
+
+// hasIngressRule reports whether a CIDR is already authorized on a single TCP port
+func hasIngressRule(group ec2types.SecurityGroup, port int32, cidr string) bool {
+	for _, perm := range group.IpPermissions {
+		if !isTCPPermission(perm) || perm.FromPort == nil || perm.ToPort == nil {
+			continue
+		}
+		if *perm.FromPort > port || *perm.ToPort < port {
+			continue
+		}
+		for _, ipRange := range perm.IpRanges {
+			if aws.ToString(ipRange.CidrIp) == cidr {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// isTCPPermission reports whether a rule covers TCP traffic
+func isTCPPermission(perm ec2types.IpPermission) bool {
+	protocol := aws.ToString(perm.IpProtocol)
+	return protocol == "tcp" || protocol == "6" || protocol == "-1"
+}
+
+// portLabel formats the port range of a rule for display
+func portLabel(perm ec2types.IpPermission) string {
+	if aws.ToString(perm.IpProtocol) == "-1" || perm.FromPort == nil || perm.ToPort == nil {
+		return "all"
+	}
+	if *perm.FromPort == *perm.ToPort {
+		return fmt.Sprintf("tcp/%d", *perm.FromPort)
+	}
+	return fmt.Sprintf("tcp/%d-%d", *perm.FromPort, *perm.ToPort)
+}
+
+// parsePortList parses a comma-separated list of ports such as "5432,6432"
+func parsePortList(value string) []int32 {
+	var ports []int32
+	for _, part := range strings.Split(value, ",") {
+		port, err := strconv.ParseInt(strings.TrimSpace(part), 10, 32)
+		if err != nil || port <= 0 || port > 65535 {
+			continue
+		}
+		ports = append(ports, int32(port))
+	}
+	return ports
+}
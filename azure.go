@@ -0,0 +1,693 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"os/exec"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Environment variables that configure the Azure provider
+const (
+	envAzureSubscriptions = "PIAM_ANC_AZURE_SUBSCRIPTIONS" // Comma-separated subscription IDs, or "*" for all; enables the provider
+	envAzureEndpoint      = "PIAM_ANC_AZURE_ENDPOINT"      // Optional ARM endpoint override (e.g. a local fake)
+	envAzureToken         = "PIAM_ANC_AZURE_TOKEN"         // Optional bearer token; defaults to the az CLI
+)
+
+const defaultAzureEndpoint = "https://management.azure.com"
+
+// ARM API versions used by the provider
+const (
+	azureSubscriptionsAPIVersion = "2020-01-01"
+	azureSQLAPIVersion           = "2021-11-01"
+	azurePostgresAPIVersion      = "2022-12-01"
+)
+
+// AzureServerKind identifies the Azure database service
+type AzureServerKind string
+
+const (
+	AzureServerKindSQL      AzureServerKind = "sql"
+	AzureServerKindPostgres AzureServerKind = "postgres-flexible"
+)
+
+// AzureFirewallRule represents a named firewall rule with an IP range
+type AzureFirewallRule struct {
+	Name    string
+	StartIP string
+	EndIP   string
+}
+
+// AzureDatabaseServer represents an Azure SQL server or PostgreSQL flexible server
+type AzureDatabaseServer struct {
+	ID                  string // Full ARM resource ID
+	Name                string
+	Subscription        string
+	ResourceGroup       string
+	Location            string
+	Kind                AzureServerKind
+	State               string
+	FQDN                string
+	PublicNetworkAccess bool
+	Tags                map[string]string
+	FirewallRules       []AzureFirewallRule
+	AuthorizedNetworks  []AuthorizedNetwork
+}
+
+func (a AzureDatabaseServer) GetName() string       { return a.Name }
+func (a AzureDatabaseServer) GetProject() string    { return a.Subscription }
+func (a AzureDatabaseServer) GetRegion() string     { return a.Location }
+func (a AzureDatabaseServer) GetType() ResourceType { return ResourceTypeAzure }
+func (a AzureDatabaseServer) GetDisplayName() string {
+	return fmt.Sprintf("%s (%s)", a.Name, a.ResourceGroup)
+}
+func (a AzureDatabaseServer) HasPublicIP() bool            { return a.PublicNetworkAccess }
+func (a AzureDatabaseServer) CanAddNetwork() bool          { return a.PublicNetworkAccess }
+func (a AzureDatabaseServer) GetLabels() map[string]string { return a.Tags }
+func (a AzureDatabaseServer) GetNetworkRestrictions() string {
+	if !a.PublicNetworkAccess {
+		return "Public network access disabled - firewall rules have no effect"
+	}
+	return ""
+}
+
+// kindLabel returns a human readable name for the server kind
+func (a AzureDatabaseServer) kindLabel() string {
+	if a.Kind == AzureServerKindPostgres {
+		return "PostgreSQL flexible server"
+	}
+	return "SQL server"
+}
+
+// azureProvider lists and updates firewall rules through the ARM REST API
+type azureProvider struct {
+	endpoint      string
+	subscriptions []string
+	client        *http.Client
+
+	mu          sync.Mutex
+	token       string
+	tokenExpiry time.Time // Zero when unknown
+	fromCLI     bool      // The token came from the az CLI and can be fetched again
+}
+
+// How long before expiry an az CLI token is replaced
+const azureTokenRefreshMargin = 5 * time.Minute
+
+// newAzureProvider creates the Azure provider, or returns nil when it is not configured
+func newAzureProvider(ctx context.Context) (*azureProvider, error) {
+	subscriptionsEnv := strings.TrimSpace(os.Getenv(envAzureSubscriptions))
+	if subscriptionsEnv == "" {
+		return nil, nil
+	}
+
+	endpoint := strings.TrimRight(strings.TrimSpace(os.Getenv(envAzureEndpoint)), "/")
+	if endpoint == "" {
+		endpoint = defaultAzureEndpoint
+	}
+
+	provider := &azureProvider{
+		endpoint: endpoint,
+		token:    strings.TrimSpace(os.Getenv(envAzureToken)),
+		client:   &http.Client{Timeout: 30 * time.Second},
+	}
+	if provider.token == "" {
+		provider.fromCLI = true
+		if _, err := provider.accessToken(ctx, false); err != nil {
+			return nil, err
+		}
+	}
+
+	if subscriptionsEnv == "*" {
+		subscriptions, err := provider.listSubscriptions(ctx)
+		if err != nil {
+			return nil, err
+		}
+		provider.subscriptions = subscriptions
+	} else {
+		for _, sub := range strings.Split(subscriptionsEnv, ",") {
+			if sub = strings.TrimSpace(sub); sub != "" {
+				provider.subscriptions = append(provider.subscriptions, sub)
+			}
+		}
+	}
+
+	return provider, nil
+}
+
+// ARM response shapes
+type azureListResponse struct {
+	Value    []json.RawMessage `json:"value"`
+	NextLink string            `json:"nextLink"`
+}
+
+type azureServer struct {
+	ID         string            `json:"id"`
+	Name       string            `json:"name"`
+	Location   string            `json:"location"`
+	Tags       map[string]string `json:"tags"`
+	Properties struct {
+		State                    string `json:"state"`
+		FullyQualifiedDomainName string `json:"fullyQualifiedDomainName"`
+		PublicNetworkAccess      string `json:"publicNetworkAccess"`
+		Network                  *struct {
+			PublicNetworkAccess string `json:"publicNetworkAccess"`
+		} `json:"network"`
+	} `json:"properties"`
+}
+
+type azureFirewallRuleResource struct {
+	Name       string `json:"name"`
+	Properties struct {
+		StartIPAddress string `json:"startIpAddress"`
+		EndIPAddress   string `json:"endIpAddress"`
+	} `json:"properties"`
+}
+
+type azureAsyncOperation struct {
+	Status string `json:"status"`
+	Error  *struct {
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+// azureCLIToken is the part of "az account get-access-token" output we use
+type azureCLIToken struct {
+	AccessToken string `json:"accessToken"`
+	ExpiresOn   int64  `json:"expires_on"` // Unix time; missing from older az versions
+}
+
+// accessToken returns the bearer token, fetching a new one from the az CLI when it
+// is about to expire or refresh is set. A token from PIAM_ANC_AZURE_TOKEN is used as is.
+func (p *azureProvider) accessToken(ctx context.Context, refresh bool) (string, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if !p.fromCLI {
+		return p.token, nil
+	}
+	expiring := !p.tokenExpiry.IsZero() && time.Now().Add(azureTokenRefreshMargin).After(p.tokenExpiry)
+	if p.token != "" && !refresh && !expiring {
+		return p.token, nil
+	}
+
+	// Use the az CLI the same way we use gcloud for project discovery
+	cmd := exec.CommandContext(ctx, "az", "account", "get-access-token",
+		"--resource", defaultAzureEndpoint+"/", "-o", "json")
+	output, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("failed to get Azure access token (run 'az login' or set %s): %v", envAzureToken, err)
+	}
+	var token azureCLIToken
+	if err := json.Unmarshal(output, &token); err != nil || token.AccessToken == "" {
+		return "", fmt.Errorf("failed to parse Azure access token from az: %v", err)
+	}
+
+	p.token = token.AccessToken
+	p.tokenExpiry = time.Time{}
+	if token.ExpiresOn > 0 {
+		p.tokenExpiry = time.Unix(token.ExpiresOn, 0)
+	}
+	return p.token, nil
+}
+
+// do sends an authenticated ARM request and returns the response body. A 401 with an
+// az CLI token fetches a fresh token and retries once.
+func (p *azureProvider) do(ctx context.Context, method, url string, body interface{}) (*http.Response, []byte, error) {
+	if !strings.HasPrefix(url, "http") {
+		url = p.endpoint + url
+	}
+
+	var payload []byte
+	if body != nil {
+		var err error
+		if payload, err = json.Marshal(body); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	resp, data, err := p.send(ctx, method, url, payload, false)
+	if err == nil && resp.StatusCode == http.StatusUnauthorized && p.fromCLI {
+		resp, data, err = p.send(ctx, method, url, payload, true)
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if resp.StatusCode >= 300 {
+		return resp, data, fmt.Errorf("%s %s returned %s: %s", method, url, resp.Status, strings.TrimSpace(string(data)))
+	}
+
+	return resp, data, nil
+}
+
+// send makes one ARM request, with a freshly fetched token if refreshToken is set
+func (p *azureProvider) send(ctx context.Context, method, url string, payload []byte, refreshToken bool) (*http.Response, []byte, error) {
+	token, err := p.accessToken(ctx, refreshToken)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var reader io.Reader
+	if payload != nil {
+		reader = bytes.NewReader(payload)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, url, reader)
+	if err != nil {
+		return nil, nil, err
+	}
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	if payload != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, nil, err
+	}
+	return resp, data, nil
+}
+
+// list follows nextLink pagination and returns every item
+func (p *azureProvider) list(ctx context.Context, url string) ([]json.RawMessage, error) {
+	var items []json.RawMessage
+	for url != "" {
+		_, data, err := p.do(ctx, http.MethodGet, url, nil)
+		if err != nil {
+			return nil, err
+		}
+
+		var page azureListResponse
+		if err := json.Unmarshal(data, &page); err != nil {
+			return nil, fmt.Errorf("failed to parse Azure response: %v", err)
+		}
+		items = append(items, page.Value...)
+		url = page.NextLink
+	}
+	return items, nil
+}
+
+// listSubscriptions gets every subscription visible to the token
+func (p *azureProvider) listSubscriptions(ctx context.Context) ([]string, error) {
+	items, err := p.list(ctx, "/subscriptions?api-version="+azureSubscriptionsAPIVersion)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list Azure subscriptions: %v", err)
+	}
+
+	var subscriptions []string
+	for _, item := range items {
+		var sub struct {
+			SubscriptionID string `json:"subscriptionId"`
+		}
+		if err := json.Unmarshal(item, &sub); err == nil && sub.SubscriptionID != "" {
+			subscriptions = append(subscriptions, sub.SubscriptionID)
+		}
+	}
+	return subscriptions, nil
+}
+
+// listServers gets all servers of one kind in a subscription, including their firewall rules
+func (p *azureProvider) listServers(ctx context.Context, subscription string, kind AzureServerKind) ([]CloudResource, error) {
+	url := fmt.Sprintf("/subscriptions/%s/providers/%s?api-version=%s", subscription, azureProviderPath(kind), azureAPIVersion(kind))
+	items, err := p.list(ctx, url)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list Azure %s servers in subscription %s: %v", kind, subscription, err)
+	}
+
+	var resources []CloudResource
+	for _, item := range items {
+		var server azureServer
+		if err := json.Unmarshal(item, &server); err != nil {
+			continue
+		}
+
+		resource, err := p.convertServer(ctx, kind, server)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list Azure firewall rules for %s: %v", server.Name, err)
+		}
+		resources = append(resources, resource)
+	}
+
+	return resources, nil
+}
+
+// getServerDetails gets detailed info for a server
+func (p *azureProvider) getServerDetails(ctx context.Context, server AzureDatabaseServer) (CloudResource, error) {
+	_, data, err := p.do(ctx, http.MethodGet, fmt.Sprintf("%s?api-version=%s", server.ID, azureAPIVersion(server.Kind)), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get Azure server details: %v", err)
+	}
+
+	var raw azureServer
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("failed to parse Azure server: %v", err)
+	}
+
+	return p.convertServer(ctx, server.Kind, raw)
+}
+
+// convertServer maps an ARM server onto AzureDatabaseServer and loads its firewall rules
+func (p *azureProvider) convertServer(ctx context.Context, kind AzureServerKind, server azureServer) (AzureDatabaseServer, error) {
+	subscription, resourceGroup := parseAzureResourceID(server.ID)
+
+	publicAccess := server.Properties.PublicNetworkAccess
+	if server.Properties.Network != nil {
+		publicAccess = server.Properties.Network.PublicNetworkAccess
+	}
+
+	result := AzureDatabaseServer{
+		ID:            server.ID,
+		Name:          server.Name,
+		Subscription:  subscription,
+		ResourceGroup: resourceGroup,
+		Location:      server.Location,
+		Tags:          server.Tags,
+		Kind:          kind,
+		State:         server.Properties.State,
+		FQDN:          server.Properties.FullyQualifiedDomainName,
+		// SQL servers created before publicNetworkAccess existed report it as empty
+		PublicNetworkAccess: publicAccess == "" || strings.EqualFold(publicAccess, "Enabled"),
+	}
+
+	rules, err := p.listFirewallRules(ctx, result)
+	if err != nil {
+		return result, err
+	}
+	result.FirewallRules = rules
+
+	for _, rule := range rules {
+		cidrs, err := rangeToCIDRs(rule.StartIP, rule.EndIP)
+		if err != nil {
+			continue
+		}
+		for _, cidr := range cidrs {
+			result.AuthorizedNetworks = append(result.AuthorizedNetworks, AuthorizedNetwork{
+				Name:  rule.Name,
+				Value: cidr,
+			})
+		}
+	}
+
+	return result, nil
+}
+
+// listFirewallRules gets the firewall rules of a server
+func (p *azureProvider) listFirewallRules(ctx context.Context, server AzureDatabaseServer) ([]AzureFirewallRule, error) {
+	items, err := p.list(ctx, fmt.Sprintf("%s/firewallRules?api-version=%s", server.ID, azureAPIVersion(server.Kind)))
+	if err != nil {
+		return nil, err
+	}
+
+	var rules []AzureFirewallRule
+	for _, item := range items {
+		var rule azureFirewallRuleResource
+		if err := json.Unmarshal(item, &rule); err != nil {
+			continue
+		}
+		rules = append(rules, AzureFirewallRule{
+			Name:    rule.Name,
+			StartIP: rule.Properties.StartIPAddress,
+			EndIP:   rule.Properties.EndIPAddress,
+		})
+	}
+	return rules, nil
+}
+
+// addNetworkToServer creates a firewall rule covering a CIDR block
+func (p *azureProvider) addNetworkToServer(ctx context.Context, server AzureDatabaseServer, networkName, networkIP string) error {
+	normalizedIP, err := normalizeIP(networkIP)
+	if err != nil {
+		return fmt.Errorf("invalid IP format: %v", err)
+	}
+
+	startIP, endIP, err := cidrToRange(normalizedIP)
+	if err != nil {
+		return fmt.Errorf("invalid IP format: %v", err)
+	}
+
+	ruleName := azureRuleName(networkName)
+	if ruleName == "" {
+		return fmt.Errorf("network name %q is not a valid Azure firewall rule name", networkName)
+	}
+
+	// Check current rules so we never overwrite an existing rule by name
+	rules, err := p.listFirewallRules(ctx, server)
+	if err != nil {
+		return fmt.Errorf("failed to get firewall rules: %v", err)
+	}
+	for _, rule := range rules {
+		if rule.StartIP == startIP && rule.EndIP == endIP {
+			return fmt.Errorf("network %s already exists with name %s", normalizedIP, rule.Name)
+		}
+		if strings.EqualFold(rule.Name, ruleName) {
+			return fmt.Errorf("firewall rule %s already exists (%s - %s)", rule.Name, rule.StartIP, rule.EndIP)
+		}
+	}
+
+	body := map[string]interface{}{
+		"properties": map[string]string{
+			"startIpAddress": startIP,
+			"endIpAddress":   endIP,
+		},
+	}
+
+	url := fmt.Sprintf("%s/firewallRules/%s?api-version=%s", server.ID, ruleName, azureAPIVersion(server.Kind))
+	resp, _, err := p.do(ctx, http.MethodPut, url, body)
+	if err != nil {
+		return fmt.Errorf("failed to create firewall rule: %v", err)
+	}
+
+	return p.waitForOperation(ctx, resp, 60*time.Second)
+}
+
+// removeNetworkFromServer deletes the firewall rule that exactly covers a CIDR block
+func (p *azureProvider) removeNetworkFromServer(ctx context.Context, server AzureDatabaseServer, networkIP string) error {
+	normalizedIP, err := normalizeIP(networkIP)
+	if err != nil {
+		return fmt.Errorf("invalid IP format: %v", err)
+	}
+
+	startIP, endIP, err := cidrToRange(normalizedIP)
+	if err != nil {
+		return fmt.Errorf("invalid IP format: %v", err)
+	}
+
+	rules, err := p.listFirewallRules(ctx, server)
+	if err != nil {
+		return fmt.Errorf("failed to get firewall rules: %v", err)
+	}
+
+	var target *AzureFirewallRule
+	for i, rule := range rules {
+		if rule.StartIP == startIP && rule.EndIP == endIP {
+			target = &rules[i]
+			break
+		}
+	}
+	if target == nil {
+		// A CIDR that is only part of a wider rule can't be removed on its own
+		for _, rule := range rules {
+			if ipInRange(startIP, rule.StartIP, rule.EndIP) && ipInRange(endIP, rule.StartIP, rule.EndIP) {
+				return fmt.Errorf("network %s is part of firewall rule %s (%s - %s); remove the whole rule instead",
+					normalizedIP, rule.Name, rule.StartIP, rule.EndIP)
+			}
+		}
+		return fmt.Errorf("network %s not found", normalizedIP)
+	}
+
+	url := fmt.Sprintf("%s/firewallRules/%s?api-version=%s", server.ID, target.Name, azureAPIVersion(server.Kind))
+	resp, _, err := p.do(ctx, http.MethodDelete, url, nil)
+	if err != nil {
+		return fmt.Errorf("failed to delete firewall rule: %v", err)
+	}
+
+	return p.waitForOperation(ctx, resp, 60*time.Second)
+}
+
+// waitForOperation polls an ARM long-running operation until it completes
+func (p *azureProvider) waitForOperation(ctx context.Context, resp *http.Response, timeout time.Duration) error {
+	if resp.StatusCode != http.StatusAccepted {
+		return nil
+	}
+
+	statusURL := resp.Header.Get("Azure-AsyncOperation")
+	if statusURL == "" {
+		statusURL = resp.Header.Get("Location")
+	}
+	if statusURL == "" {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("operation timeout: %v", ctx.Err())
+		case <-time.After(2 * time.Second):
+			opResp, data, err := p.do(ctx, http.MethodGet, statusURL, nil)
+			if err != nil {
+				return fmt.Errorf("failed to get operation status: %v", err)
+			}
+			// Location polling returns 202 until done and no body
+			if opResp.StatusCode == http.StatusAccepted {
+				continue
+			}
+
+			var op azureAsyncOperation
+			if len(data) == 0 || json.Unmarshal(data, &op) != nil || op.Status == "" {
+				return nil
+			}
+
+			switch strings.ToLower(op.Status) {
+			case "succeeded":
+				return nil
+			case "failed", "canceled":
+				if op.Error != nil && op.Error.Message != "" {
+					return fmt.Errorf("operation failed: %s", op.Error.Message)
+				}
+				return fmt.Errorf("operation %s", strings.ToLower(op.Status))
+			}
+		}
+	}
+}
+
+// azureProviderPath returns the ARM provider path for a server kind
+func azureProviderPath(kind AzureServerKind) string {
+	if kind == AzureServerKindPostgres {
+		return "Microsoft.DBforPostgreSQL/flexibleServers"
+	}
+	return "Microsoft.Sql/servers"
+}
+
+// azureAPIVersion returns the ARM API version for a server kind
+func azureAPIVersion(kind AzureServerKind) string {
+	if kind == AzureServerKindPostgres {
+		return azurePostgresAPIVersion
+	}
+	return azureSQLAPIVersion
+}
+
+// parseAzureResourceID extracts the subscription and resource group from an ARM ID
+func parseAzureResourceID(id string) (subscription, resourceGroup string) {
+	parts := strings.Split(strings.Trim(id, "/"), "/")
+	for i := 0; i+1 < len(parts); i++ {
+		switch strings.ToLower(parts[i]) {
+		case "subscriptions":
+			subscription = parts[i+1]
+		case "resourcegroups":
+			resourceGroup = parts[i+1]
+		}
+	}
+	return subscription, resourceGroup
+}
+
+var azureRuleNameInvalid = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+
+// azureRuleName turns a network name into a firewall rule name valid for both SQL and PostgreSQL
+func azureRuleName(name string) string {
+	ruleName := strings.Trim(azureRuleNameInvalid.ReplaceAllString(strings.TrimSpace(name), "-"), "-.")
+	if len(ruleName) > 80 {
+		ruleName = ruleName[:80]
+	}
+	return ruleName
+}
+
+// cidrToRange converts an IPv4 CIDR block to its first and last address
+func cidrToRange(cidr string) (string, string, error) {
+	_, network, err := net.ParseCIDR(cidr)
+	if err != nil {
+		return "", "", err
+	}
+	start := network.IP.To4()
+	if start == nil {
+		return "", "", fmt.Errorf("only IPv4 ranges are supported")
+	}
+
+	end := make(net.IP, len(start))
+	for i := range start {
+		end[i] = start[i] | ^network.Mask[i]
+	}
+	return start.String(), end.String(), nil
+}
+
+// rangeToCIDRs converts an inclusive IPv4 range to the minimal list of CIDR blocks
+func rangeToCIDRs(startIP, endIP string) ([]string, error) {
+	start, err := ipv4ToUint(startIP)
+	if err != nil {
+		return nil, err
+	}
+	end, err := ipv4ToUint(endIP)
+	if err != nil {
+		return nil, err
+	}
+	if start > end {
+		return nil, fmt.Errorf("invalid range %s - %s", startIP, endIP)
+	}
+
+	var cidrs []string
+	current := uint64(start)
+	last := uint64(end)
+	for current <= last {
+		// Largest block aligned at current that doesn't pass the end of the range
+		prefix := 32
+		for prefix > 0 {
+			size := uint64(1) << uint(32-prefix+1)
+			if current%size != 0 || current+size-1 > last {
+				break
+			}
+			prefix--
+		}
+
+		ip := make(net.IP, 4)
+		binary.BigEndian.PutUint32(ip, uint32(current))
+		cidrs = append(cidrs, fmt.Sprintf("%s/%d", ip.String(), prefix))
+		current += uint64(1) << uint(32-prefix)
+	}
+	return cidrs, nil
+}
+
+// ipInRange reports whether an IPv4 address lies within an inclusive range
+func ipInRange(ip, startIP, endIP string) bool {
+	value, err := ipv4ToUint(ip)
+	if err != nil {
+		return false
+	}
+	start, err := ipv4ToUint(startIP)
+	if err != nil {
+		return false
+	}
+	end, err := ipv4ToUint(endIP)
+	if err != nil {
+		return false
+	}
+	return value >= start && value <= end
+}
+
+// ipv4ToUint parses a dotted IPv4 address into an integer
+func ipv4ToUint(ip string) (uint32, error) {
+	parsed := net.ParseIP(strings.TrimSpace(ip))
+	if parsed == nil || parsed.To4() == nil {
+		return 0, fmt.Errorf("invalid IPv4 address %q", ip)
+	}
+	return binary.BigEndian.Uint32(parsed.To4()), nil
+}
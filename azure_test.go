@@ -0,0 +1,98 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestCIDRToRange(t *testing.T) {
+	tests := []struct {
+		cidr      string
+		wantStart string
+		wantEnd   string
+		wantErr   bool
+	}{
+		{cidr: "203.0.113.7/32", wantStart: "203.0.113.7", wantEnd: "203.0.113.7"},
+		{cidr: "203.0.113.0/24", wantStart: "203.0.113.0", wantEnd: "203.0.113.255"},
+		{cidr: "203.0.113.77/24", wantStart: "203.0.113.0", wantEnd: "203.0.113.255"},
+		{cidr: "10.0.0.0/8", wantStart: "10.0.0.0", wantEnd: "10.255.255.255"},
+		{cidr: "0.0.0.0/0", wantStart: "0.0.0.0", wantEnd: "255.255.255.255"},
+		{cidr: "2001:db8::/32", wantErr: true},
+		{cidr: "203.0.113.7", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.cidr, func(t *testing.T) {
+			start, end, err := cidrToRange(tt.cidr)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("cidrToRange(%q) = %s - %s, want an error", tt.cidr, start, end)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("cidrToRange(%q): %v", tt.cidr, err)
+			}
+			if start != tt.wantStart || end != tt.wantEnd {
+				t.Errorf("cidrToRange(%q) = %s - %s, want %s - %s", tt.cidr, start, end, tt.wantStart, tt.wantEnd)
+			}
+		})
+	}
+}
+
+func TestRangeToCIDRs(t *testing.T) {
+	tests := []struct {
+		name    string
+		start   string
+		end     string
+		want    []string
+		wantErr bool
+	}{
+		{name: "single address", start: "203.0.113.7", end: "203.0.113.7", want: []string{"203.0.113.7/32"}},
+		{name: "aligned block", start: "203.0.113.0", end: "203.0.113.255", want: []string{"203.0.113.0/24"}},
+		{name: "everything", start: "0.0.0.0", end: "255.255.255.255", want: []string{"0.0.0.0/0"}},
+		{name: "two addresses across a boundary", start: "203.0.113.255", end: "203.0.114.0", want: []string{"203.0.113.255/32", "203.0.114.0/32"}},
+		{
+			name:  "unaligned range",
+			start: "10.0.0.1",
+			end:   "10.0.0.10",
+			want:  []string{"10.0.0.1/32", "10.0.0.2/31", "10.0.0.4/30", "10.0.0.8/31", "10.0.0.10/32"},
+		},
+		{name: "last address", start: "255.255.255.255", end: "255.255.255.255", want: []string{"255.255.255.255/32"}},
+		{name: "reversed", start: "10.0.0.10", end: "10.0.0.1", wantErr: true},
+		{name: "IPv6", start: "2001:db8::", end: "2001:db8::ff", wantErr: true},
+		{name: "not an address", start: "nope", end: "10.0.0.1", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := rangeToCIDRs(tt.start, tt.end)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("rangeToCIDRs(%s, %s) = %v, want an error", tt.start, tt.end, got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("rangeToCIDRs(%s, %s): %v", tt.start, tt.end, err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("rangeToCIDRs(%s, %s) = %v, want %v", tt.start, tt.end, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCIDRRangeRoundTrip(t *testing.T) {
+	for _, cidr := range []string{"203.0.113.7/32", "203.0.113.128/25", "172.16.0.0/12", "0.0.0.0/0"} {
+		start, end, err := cidrToRange(cidr)
+		if err != nil {
+			t.Fatalf("cidrToRange(%q): %v", cidr, err)
+		}
+		got, err := rangeToCIDRs(start, end)
+		if err != nil {
+			t.Fatalf("rangeToCIDRs(%s, %s): %v", start, end, err)
+		}
+		if len(got) != 1 || got[0] != cidr {
+			t.Errorf("%s round-tripped to %v", cidr, got)
+		}
+	}
+}
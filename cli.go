@@ -0,0 +1,680 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+// runHistory implements "piam-anc history", printing audit log entries that match the flags
+func runHistory(args []string) error {
+	flags := flag.NewFlagSet("history", flag.ContinueOnError)
+	resource := flags.String("resource", "", "Only show changes to this resource (name or project/name)")
+	user := flags.String("user", "", "Only show changes by this gcloud account or OS user")
+	since := flags.String("since", "", "Only show changes at or after this date (YYYY-MM-DD or RFC 3339)")
+	until := flags.String("until", "", "Only show changes before this date (YYYY-MM-DD or RFC 3339)")
+	asJSON := flags.Bool("json", false, "Print entries as JSON lines")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	filter := AuditFilter{Resource: *resource, User: *user}
+	var err error
+	if filter.Since, err = parseCLITime(*since); err != nil {
+		return fmt.Errorf("invalid --since: %v", err)
+	}
+	if filter.Until, err = parseCLITime(*until); err != nil {
+		return fmt.Errorf("invalid --until: %v", err)
+	}
+
+	config, err := LoadConfig()
+	if err != nil {
+		return err
+	}
+	audit := NewAuditLog(config.Audit)
+	if audit == nil {
+		return fmt.Errorf("audit logging is disabled in %s", configPath())
+	}
+
+	entries, err := audit.Read(filter)
+	if err != nil {
+		return err
+	}
+
+	if *asJSON {
+		encoder := json.NewEncoder(os.Stdout)
+		for _, entry := range entries {
+			if err := encoder.Encode(entry); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	if len(entries) == 0 {
+		fmt.Printf("No matching changes in %s\n", audit.Path())
+		return nil
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "TIME\tUSER\tTYPE\tRESOURCE\tOPERATION\tCHANGE\tOUTCOME")
+	for _, entry := range entries {
+		user := entry.Account
+		if user == "" {
+			user = entry.OSUser
+		}
+		outcome := entry.Outcome
+		if entry.Error != "" {
+			outcome += ": " + entry.Error
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s/%s\t%s\t%s\t%s\n",
+			entry.Timestamp.Local().Format("2006-01-02 15:04:05"),
+			user,
+			entry.ResourceType,
+			entry.Project,
+			entry.Resource,
+			entry.Operation,
+			auditChangeSummary(entry),
+			outcome,
+		)
+	}
+	return w.Flush()
+}
+
+// parseCLITime parses a date or timestamp flag, returning the zero time for an empty value
+func parseCLITime(value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	return time.ParseInLocation("2006-01-02", value, time.Local)
+}
+
+// runRestore implements "piam-anc restore", re-applying a snapshot exactly after showing the diff
+func runRestore(args []string) error {
+	flags := flag.NewFlagSet("restore", flag.ContinueOnError)
+	list := flags.Bool("list", false, "List snapshots instead of restoring one")
+	resource := flags.String("resource", "", "With --list, only show snapshots of this resource (name or project/name)")
+	yes := flags.Bool("yes", false, "Restore without asking for confirmation")
+	positional, err := parseInterspersed(flags, args)
+	if err != nil {
+		return err
+	}
+
+	config, err := LoadConfig()
+	if err != nil {
+		return err
+	}
+	store := NewSnapshotStore(config.Snapshots)
+
+	if *list {
+		return listSnapshots(store, *resource)
+	}
+	if len(positional) != 1 {
+		return fmt.Errorf("usage: piam-anc restore [--yes] <snapshot-id> (or --list)")
+	}
+
+	snapshot, err := store.Load(positional[0])
+	if err != nil {
+		return err
+	}
+
+	nm, err := NewNetworkManager(context.Background())
+	if err != nil {
+		return err
+	}
+	placeholder, err := snapshot.Resource.Resource()
+	if err != nil {
+		return err
+	}
+	current, err := nm.GetResourceDetails(placeholder)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Snapshot %s of %s, taken %s before %s\n\n",
+		snapshot.ID, snapshot.Resource, snapshot.Timestamp.Local().Format("2006-01-02 15:04:05"), snapshot.Operation)
+	diff := diffNetworks(resourceNetworks(current), snapshot.Networks)
+	settings := snapshot.SettingChanges(current)
+	if diff.Empty() && len(settings) == 0 {
+		fmt.Println("Live resource already matches the snapshot; nothing to do.")
+		return nil
+	}
+	for _, line := range append(diff.Lines(), settings...) {
+		fmt.Println("  " + line)
+	}
+	fmt.Println()
+
+	if nm.dryRun {
+		fmt.Println(dryRunNotice)
+		return nil
+	}
+	if !*yes && !confirmPrompt("Apply these changes?") {
+		return fmt.Errorf("cancelled")
+	}
+
+	fmt.Println("Restoring... (GCP may take up to 60 seconds)")
+	if err := nm.RestoreSnapshot(snapshot); err != nil {
+		return err
+	}
+	fmt.Println("Restored.")
+	return nil
+}
+
+// listSnapshots prints snapshots, newest first
+func listSnapshots(store *SnapshotStore, resource string) error {
+	snapshots, err := store.List(nil)
+	if err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tTIME\tRESOURCE\tBEFORE\tNETWORKS")
+	for _, snapshot := range snapshots {
+		ref := snapshot.Resource
+		if resource != "" && resource != ref.Name && resource != ref.Project+"/"+ref.Name {
+			continue
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%d\n",
+			snapshot.ID,
+			snapshot.Timestamp.Local().Format("2006-01-02 15:04:05"),
+			ref,
+			snapshot.Operation,
+			len(snapshot.Networks),
+		)
+	}
+	return w.Flush()
+}
+
+// parseInterspersed parses flags that may appear before or after positional arguments
+func parseInterspersed(flags *flag.FlagSet, args []string) ([]string, error) {
+	var positional []string
+	for {
+		if err := flags.Parse(args); err != nil {
+			return nil, err
+		}
+		args = flags.Args()
+		if len(args) == 0 {
+			return positional, nil
+		}
+		positional = append(positional, args[0])
+		args = args[1:]
+	}
+}
+
+// confirmPrompt asks a yes/no question on the terminal, defaulting to no
+func confirmPrompt(question string) bool {
+	fmt.Printf("%s [y/N] ", question)
+	answer, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+	answer = strings.ToLower(strings.TrimSpace(answer))
+	return answer == "y" || answer == "yes"
+}
+
+// runPlan implements "piam-anc plan", showing the changes apply would make
+func runPlan(args []string) error {
+	flags := flag.NewFlagSet("plan", flag.ContinueOnError)
+	detailedExitCode := flags.Bool("detailed-exitcode", false, "Exit 2 when there are changes to apply")
+	positional, err := parseInterspersed(flags, args)
+	if err != nil {
+		return err
+	}
+	if len(positional) != 1 {
+		return fmt.Errorf("usage: piam-anc plan [--detailed-exitcode] <desired-state.yaml>")
+	}
+
+	_, plan, err := loadPlan(positional[0])
+	if err != nil {
+		return err
+	}
+	printPlan(plan)
+
+	if *detailedExitCode && len(plan.Changed()) > 0 {
+		return &exitError{code: 2}
+	}
+	return nil
+}
+
+// runApply implements "piam-anc apply", converging resources to a desired-state file
+func runApply(args []string) error {
+	flags := flag.NewFlagSet("apply", flag.ContinueOnError)
+	yes := flags.Bool("yes", false, "Apply without asking for confirmation")
+	positional, err := parseInterspersed(flags, args)
+	if err != nil {
+		return err
+	}
+	if len(positional) != 1 {
+		return fmt.Errorf("usage: piam-anc apply [--yes] <desired-state.yaml>")
+	}
+
+	nm, plan, err := loadPlan(positional[0])
+	if err != nil {
+		return err
+	}
+	printPlan(plan)
+
+	applicable := 0
+	for _, resourcePlan := range plan.Changed() {
+		if resourcePlan.Err == nil {
+			applicable++
+		}
+	}
+	if applicable == 0 {
+		return nil
+	}
+	if nm.dryRun {
+		fmt.Println(dryRunNotice)
+		return nil
+	}
+
+	if !*yes && !confirmPrompt(fmt.Sprintf("Apply changes to %d resources?", applicable)) {
+		return fmt.Errorf("cancelled")
+	}
+
+	fmt.Println("Applying... (GCP may take up to 60 seconds per resource)")
+	failed := 0
+	for _, result := range nm.ApplyPlan(plan) {
+		if result.Err != nil {
+			failed++
+			fmt.Printf("  ✗ %s: %v\n", resourceRefOf(result.Resource), result.Err)
+		} else {
+			fmt.Printf("  ✓ %s\n", resourceRefOf(result.Resource))
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d resources failed", failed, applicable)
+	}
+	return nil
+}
+
+// loadPlan discovers all resources and diffs them against a desired-state file
+func loadPlan(path string) (*NetworkManager, *Plan, error) {
+	state, err := LoadDesiredState(path)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	nm, err := NewNetworkManager(context.Background())
+	if err != nil {
+		return nil, nil, err
+	}
+
+	fmt.Fprintln(os.Stderr, "Discovering resources...")
+	resources, err := nm.ListAllResources()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return nm, BuildPlan(state, resources, nm.policy), nil
+}
+
+// printPlan prints each changed resource's diff followed by a summary
+func printPlan(plan *Plan) {
+	changed := plan.Changed()
+	for _, resourcePlan := range changed {
+		fmt.Printf("~ %s\n", resourceRefOf(resourcePlan.Resource))
+		for _, line := range resourcePlan.Diff.Lines() {
+			fmt.Println("    " + line)
+		}
+		if resourcePlan.Err != nil {
+			fmt.Printf("    ! will not apply: %v\n", resourcePlan.Err)
+		}
+		fmt.Println()
+	}
+
+	for _, problem := range plan.Problems {
+		fmt.Printf("! %s\n", problem)
+	}
+	if len(plan.Problems) > 0 {
+		fmt.Println()
+	}
+
+	fmt.Printf("Plan: %d to change, %d unchanged, %d unmanaged.\n",
+		len(changed), len(plan.Resources)-len(changed), len(plan.Unmanaged))
+}
+
+// runCheck implements "piam-anc check", comparing live networks with a baseline and exiting 1 on drift
+func runCheck(args []string) error {
+	flags := flag.NewFlagSet("check", flag.ContinueOnError)
+	format := flags.String("format", "text", "Report format: text, junit or sarif")
+	output := flags.String("output", "", "Write the report to this file instead of stdout")
+	includeUnmanaged := flags.Bool("include-unmanaged", false, "Report every resource the baseline doesn't mention, not only open ones")
+	positional, err := parseInterspersed(flags, args)
+	if err != nil {
+		return err
+	}
+	if len(positional) != 1 {
+		return fmt.Errorf("usage: piam-anc check [--format text|junit|sarif] [--output FILE] [--include-unmanaged] <baseline>")
+	}
+
+	var write func(*DriftReport, io.Writer) error
+	switch *format {
+	case "text":
+		write = (*DriftReport).WriteText
+	case "junit":
+		write = (*DriftReport).WriteJUnit
+	case "sarif":
+		write = (*DriftReport).WriteSARIF
+	default:
+		return fmt.Errorf("unknown format %q (want text, junit or sarif)", *format)
+	}
+
+	baseline, err := LoadBaseline(positional[0])
+	if err != nil {
+		return err
+	}
+
+	nm, err := NewNetworkManager(context.Background())
+	if err != nil {
+		return err
+	}
+	fmt.Fprintln(os.Stderr, "Discovering resources...")
+	resources, err := nm.ListAllResources()
+	if err != nil {
+		return err
+	}
+
+	report := CheckDrift(baseline, positional[0], resources, *includeUnmanaged)
+
+	if *output != "" {
+		file, err := os.Create(*output)
+		if err != nil {
+			return fmt.Errorf("failed to create report: %v", err)
+		}
+		defer file.Close()
+		if err := write(report, file); err != nil {
+			return err
+		}
+		// Keep a readable summary in the job log
+		if *format != "text" {
+			report.WriteText(os.Stdout)
+		}
+	} else if err := write(report, os.Stdout); err != nil {
+		return err
+	}
+
+	if report.HasDrift() {
+		return &exitError{code: 1}
+	}
+	return nil
+}
+
+// runSearch implements "piam-anc search", listing every entry across the estate that authorizes an IP or CIDR
+func runSearch(args []string) error {
+	flags := flag.NewFlagSet("search", flag.ContinueOnError)
+	asJSON := flags.Bool("json", false, "Print matches as JSON lines")
+	filter := flags.String("filter", "", "Only search resources matching this query, e.g. \"type:sql public:true\"")
+	positional, err := parseInterspersed(flags, args)
+	if err != nil {
+		return err
+	}
+	if len(positional) != 1 {
+		return fmt.Errorf("usage: piam-anc search [--json] [--filter query] <ip-or-cidr>")
+	}
+	query, err := ParseSearchQuery(positional[0])
+	if err != nil {
+		return err
+	}
+	resourceQuery, err := ParseResourceQuery(*filter)
+	if err != nil {
+		return err
+	}
+
+	nm, err := NewNetworkManager(context.Background())
+	if err != nil {
+		return err
+	}
+	fmt.Fprintln(os.Stderr, "Discovering resources...")
+	resources, err := nm.ListAllResources()
+	if err != nil {
+		return err
+	}
+	resources = resourceQuery.Filter(resources)
+	matches := SearchNetworks(resources, query)
+
+	if *asJSON {
+		encoder := json.NewEncoder(os.Stdout)
+		for _, match := range matches {
+			if err := encoder.Encode(match); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	if len(matches) == 0 {
+		fmt.Printf("No entry in %d resources matches %s\n", len(resources), query)
+		return nil
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "RESOURCE\tNAME\tCIDR\tPORT\tMATCH")
+	for _, match := range matches {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", match.Resource, match.Name, match.CIDR, match.Port, match.Match)
+	}
+	return w.Flush()
+}
+
+// ResourceSummary is one resource as printed by "piam-anc list --json"
+type ResourceSummary struct {
+	Resource ResourceRef `json:"resource"`
+	State    string      `json:"state,omitempty"`
+	Public   bool        `json:"public"`
+	Open     bool        `json:"open"`
+	Networks int         `json:"networks"`
+}
+
+// runList implements "piam-anc list", printing the discovered resources that match a query
+func runList(args []string) error {
+	flags := flag.NewFlagSet("list", flag.ContinueOnError)
+	asJSON := flags.Bool("json", false, "Print resources as JSON lines")
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "usage: piam-anc list [--json] [query...]")
+		flags.PrintDefaults()
+		fmt.Fprintln(flags.Output(), "\nQuery terms (prefix with - to negate; put -- before a negated first term):")
+		keys := make([]string, 0, len(queryKeys))
+		for key := range queryKeys {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			fmt.Fprintf(flags.Output(), "  %-9s %s\n", key, queryKeys[key])
+		}
+	}
+	// Flags come first so that negated terms such as -type:gke aren't taken for flags
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	query, err := ParseResourceQuery(strings.Join(flags.Args(), " "))
+	if err != nil {
+		return err
+	}
+
+	nm, err := NewNetworkManager(context.Background())
+	if err != nil {
+		return err
+	}
+	fmt.Fprintln(os.Stderr, "Discovering resources...")
+	resources, err := nm.ListAllResources()
+	if err != nil {
+		return err
+	}
+	matched := query.Filter(resources)
+
+	if *asJSON {
+		encoder := json.NewEncoder(os.Stdout)
+		for _, resource := range matched {
+			summary := ResourceSummary{
+				Resource: resourceRefOf(resource),
+				State:    resourceState(resource),
+				Public:   resource.HasPublicIP(),
+				Open:     openToWorld(resource),
+				Networks: len(resourceNetworks(resource)),
+			}
+			if err := encoder.Encode(summary); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	if len(matched) == 0 {
+		fmt.Printf("None of %d resources match\n", len(resources))
+		return nil
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "RESOURCE\tLOCATION\tSTATE\tPUBLIC\tOPEN\tNETWORKS")
+	for _, resource := range matched {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%d\n", resourceRefOf(resource), resource.GetRegion(), resourceState(resource),
+			yesNo(resource.HasPublicIP()), yesNo(openToWorld(resource)), len(resourceNetworks(resource)))
+	}
+	return w.Flush()
+}
+
+// yesNo formats a boolean for tables
+func yesNo(b bool) string {
+	if b {
+		return "yes"
+	}
+	return "no"
+}
+
+// runExport implements "piam-anc export", writing every discovered resource's networks to a file
+func runExport(args []string) error {
+	flags := flag.NewFlagSet("export", flag.ContinueOnError)
+	output := flags.String("output", "", "File to write (default piam-anc-export-<time>.json, - for stdout)")
+	filter := flags.String("filter", "", "Only export resources matching this query, e.g. \"type:gke\"")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	resourceQuery, err := ParseResourceQuery(*filter)
+	if err != nil {
+		return err
+	}
+
+	nm, err := NewNetworkManager(context.Background())
+	if err != nil {
+		return err
+	}
+	fmt.Fprintln(os.Stderr, "Discovering resources...")
+	resources, err := nm.ListAllResources()
+	if err != nil {
+		return err
+	}
+	export := NewExport(resourceQuery.Filter(resources))
+
+	if *output == "-" {
+		return export.Write(os.Stdout)
+	}
+	path := *output
+	if path == "" {
+		path = fmt.Sprintf("piam-anc-export-%s.json", export.ExportedAt.Format("20060102T150405Z"))
+	}
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o600)
+	if err != nil {
+		return fmt.Errorf("failed to create export: %v", err)
+	}
+	defer file.Close()
+	if err := export.Write(file); err != nil {
+		return err
+	}
+	fmt.Fprintf(os.Stderr, "Exported %d resources to %s\n", len(export.Resources), path)
+	return nil
+}
+
+// runImport implements "piam-anc import", adding networks from an export or CSV file
+func runImport(args []string) error {
+	flags := flag.NewFlagSet("import", flag.ContinueOnError)
+	dryRun := flags.Bool("dry-run", false, "Show what would be added without changing anything")
+	yes := flags.Bool("yes", false, "Import without asking for confirmation")
+	progressPath := flags.String("progress", "", "Progress file for resuming (default <file>.progress)")
+	restart := flags.Bool("restart", false, "Ignore the progress of earlier runs")
+	positional, err := parseInterspersed(flags, args)
+	if err != nil {
+		return err
+	}
+	if len(positional) != 1 {
+		return fmt.Errorf("usage: piam-anc import [--dry-run] [--yes] [--progress FILE] [--restart] <export.json|networks.csv>")
+	}
+
+	items, err := LoadImportItems(positional[0])
+	if err != nil {
+		return err
+	}
+
+	if *progressPath == "" {
+		*progressPath = positional[0] + ".progress"
+	}
+	if *restart {
+		if err := os.Remove(*progressPath); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("failed to remove progress file: %v", err)
+		}
+	}
+	progress, done, err := loadImportProgress(*progressPath)
+	if err != nil {
+		return err
+	}
+
+	nm, err := NewNetworkManager(context.Background())
+	if err != nil {
+		return err
+	}
+	fmt.Fprintln(os.Stderr, "Discovering resources...")
+	resources, err := nm.ListAllResources()
+	if err != nil {
+		return err
+	}
+
+	actions := PlanImport(items, resources, done, nm.policy)
+	counts := make(map[string]int)
+	for _, action := range actions {
+		counts[action.Status]++
+		switch action.Status {
+		case ImportAdd:
+			fmt.Printf("+ %-30s %-20s %s\n", action.Item.Project+"/"+action.Item.Resource, action.Item.CIDR, action.Item.Name)
+		case ImportConflict:
+			fmt.Printf("! %-30s %-20s %s (%s): %s\n", action.Item.Project+"/"+action.Item.Resource, action.Item.CIDR, action.Item.Name, action.Item.Source, action.Reason)
+		}
+	}
+	fmt.Printf("\nImport: %d to add, %d already present, %d done in an earlier run, %d conflicts.\n",
+		counts[ImportAdd], counts[ImportPresent], counts[ImportDone], counts[ImportConflict])
+
+	if *dryRun || counts[ImportAdd] == 0 {
+		return nil
+	}
+	if nm.dryRun {
+		fmt.Println(dryRunNotice)
+		return nil
+	}
+	if !*yes && !confirmPrompt(fmt.Sprintf("Add %d networks?", counts[ImportAdd])) {
+		return fmt.Errorf("cancelled")
+	}
+
+	fmt.Println("Importing... (GCP may take up to 60 seconds per network)")
+	nm.RunImport(actions, progress)
+
+	failed := 0
+	for _, action := range actions {
+		if action.Status == ImportAdd && action.Err != nil {
+			failed++
+			fmt.Printf("  ✗ %s/%s %s: %v\n", action.Item.Project, action.Item.Resource, action.Item.CIDR, action.Err)
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d networks failed; run the same command again to resume", failed, counts[ImportAdd])
+	}
+
+	fmt.Printf("Added %d networks.\n", counts[ImportAdd])
+	os.Remove(*progressPath)
+	return nil
+}
@@ -0,0 +1,59 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// envConfigPath overrides the location of the config file
+const envConfigPath = "PIAM_ANC_CONFIG"
+
+// Config holds user settings loaded from the piam-anc config file
+type Config struct {
+	Policy    PolicyConfig   `json:"policy"`
+	Audit     AuditConfig    `json:"audit"`
+	Snapshots SnapshotConfig `json:"snapshots"`
+	Groups    []NetworkGroup `json:"groups"`
+	Requests  RequestConfig  `json:"requests"`
+	Theme     ThemeConfig    `json:"theme"`
+	Keys      KeyConfig      `json:"keys"`
+}
+
+// configDir returns the directory holding piam-anc's config and state files
+func configDir() string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		dir = "."
+	}
+	return filepath.Join(dir, "piam-anc")
+}
+
+// configPath returns the path of the config file
+func configPath() string {
+	if path := os.Getenv(envConfigPath); path != "" {
+		return path
+	}
+	return filepath.Join(configDir(), "config.json")
+}
+
+// LoadConfig reads the config file, returning defaults when it doesn't exist
+func LoadConfig() (*Config, error) {
+	config := &Config{}
+
+	path := configPath()
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return config, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config %s: %v", path, err)
+	}
+
+	if err := json.Unmarshal(data, config); err != nil {
+		return nil, fmt.Errorf("failed to parse config %s: %v", path, err)
+	}
+
+	return config, nil
+}
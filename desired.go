@@ -0,0 +1,313 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// DesiredNetwork is one named CIDR in a desired-state file
+type DesiredNetwork struct {
+	Name string `yaml:"name" json:"name"`
+	CIDR string `yaml:"cidr" json:"cidr"`
+}
+
+// DesiredSelector matches every resource with all the given labels, optionally
+// limited to some projects and resource types
+type DesiredSelector struct {
+	Labels   map[string]string `yaml:"labels" json:"labels"`
+	Projects []string          `yaml:"projects,omitempty" json:"projects,omitempty"`
+	Types    []ResourceType    `yaml:"types,omitempty" json:"types,omitempty"`
+}
+
+// DesiredResource is the exact network list for one named resource, or for every resource a selector matches
+type DesiredResource struct {
+	Type     ResourceType     `yaml:"type,omitempty" json:"type,omitempty"`
+	Project  string           `yaml:"project,omitempty" json:"project,omitempty"`
+	Location string           `yaml:"location,omitempty" json:"location,omitempty"`
+	Name     string           `yaml:"name,omitempty" json:"name,omitempty"`
+	Selector *DesiredSelector `yaml:"selector,omitempty" json:"selector,omitempty"`
+	Networks []DesiredNetwork `yaml:"networks" json:"networks"`
+}
+
+// DesiredState is the contents of a desired-state file. Resources it doesn't mention are unmanaged.
+type DesiredState struct {
+	Version   int               `yaml:"version" json:"version"`
+	Resources []DesiredResource `yaml:"resources" json:"resources"`
+}
+
+// LoadDesiredState reads and validates a desired-state file; .json files are parsed as JSON, anything else as YAML
+func LoadDesiredState(path string) (*DesiredState, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read desired state: %v", err)
+	}
+
+	state := &DesiredState{}
+	if strings.EqualFold(filepath.Ext(path), ".json") {
+		decoder := json.NewDecoder(bytes.NewReader(data))
+		decoder.DisallowUnknownFields()
+		err = decoder.Decode(state)
+	} else {
+		decoder := yaml.NewDecoder(bytes.NewReader(data))
+		decoder.KnownFields(true)
+		err = decoder.Decode(state)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse desired state %s: %v", path, err)
+	}
+
+	if err := state.validate(); err != nil {
+		return nil, fmt.Errorf("invalid desired state %s: %v", path, err)
+	}
+	return state, nil
+}
+
+// validate checks the file version, targets and CIDRs, normalising CIDRs in place
+func (s *DesiredState) validate() error {
+	if s.Version == 0 {
+		s.Version = 1
+	}
+	if s.Version != 1 {
+		return fmt.Errorf("unsupported version %d", s.Version)
+	}
+
+	for i := range s.Resources {
+		r := &s.Resources[i]
+		label := fmt.Sprintf("resources[%d]", i)
+
+		named := r.Project != "" || r.Name != ""
+		if named && r.Selector != nil {
+			return fmt.Errorf("%s: use either project/name or selector, not both", label)
+		}
+		if !named && r.Selector == nil {
+			return fmt.Errorf("%s: needs project and name, or a selector", label)
+		}
+		if named && (r.Project == "" || r.Name == "") {
+			return fmt.Errorf("%s: both project and name are required", label)
+		}
+		if r.Selector != nil && len(r.Selector.Labels) == 0 {
+			return fmt.Errorf("%s: selector needs at least one label", label)
+		}
+
+		seen := make(map[string]bool)
+		for j := range r.Networks {
+			network := &r.Networks[j]
+			normalized, _ := normalizeIP(strings.TrimSpace(network.CIDR))
+			if _, _, err := net.ParseCIDR(normalized); err != nil {
+				return fmt.Errorf("%s: invalid CIDR %q", label, network.CIDR)
+			}
+			if seen[normalized] {
+				return fmt.Errorf("%s: CIDR %s listed twice", label, normalized)
+			}
+			seen[normalized] = true
+			network.CIDR = normalized
+		}
+	}
+	return nil
+}
+
+// matches reports whether a desired-state entry targets a resource
+func (r DesiredResource) matches(resource CloudResource) bool {
+	if r.Selector != nil {
+		return r.Selector.matches(resource)
+	}
+	if r.Type != "" && !strings.EqualFold(string(r.Type), string(resource.GetType())) {
+		return false
+	}
+	if r.Location != "" && r.Location != resource.GetRegion() {
+		return false
+	}
+	return r.Project == resource.GetProject() && r.Name == resource.GetName()
+}
+
+// matches reports whether a selector matches a resource
+func (s DesiredSelector) matches(resource CloudResource) bool {
+	if len(s.Types) > 0 {
+		found := false
+		for _, t := range s.Types {
+			if strings.EqualFold(string(t), string(resource.GetType())) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	if len(s.Projects) > 0 {
+		found := false
+		for _, project := range s.Projects {
+			if project == resource.GetProject() {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	labels := resource.GetLabels()
+	for key, value := range s.Labels {
+		if labels[key] != value {
+			return false
+		}
+	}
+	return true
+}
+
+// describe returns a short description of the entry for messages
+func (r DesiredResource) describe() string {
+	if r.Selector != nil {
+		pairs := make([]string, 0, len(r.Selector.Labels))
+		for key, value := range r.Selector.Labels {
+			pairs = append(pairs, key+"="+value)
+		}
+		return "selector " + strings.Join(pairs, ",")
+	}
+	return r.Project + "/" + r.Name
+}
+
+// authorizedNetworks converts the entry's networks to AuthorizedNetworks
+func (r DesiredResource) authorizedNetworks() []AuthorizedNetwork {
+	networks := make([]AuthorizedNetwork, len(r.Networks))
+	for i, network := range r.Networks {
+		networks[i] = AuthorizedNetwork{Name: network.Name, Value: network.CIDR}
+	}
+	return networks
+}
+
+// ResourcePlan is the change needed to bring one managed resource to its desired state
+type ResourcePlan struct {
+	Resource CloudResource
+	Desired  []AuthorizedNetwork
+	Diff     NetworkDiff
+	Err      error // Why the change can't be applied
+}
+
+// Plan is the set of changes needed to converge the estate to a desired state
+type Plan struct {
+	Resources []ResourcePlan  // Every managed resource, changed or not
+	Unmanaged []CloudResource // Resources no entry matches
+	Problems  []string        // Entries that matched nothing or conflicted
+}
+
+// BuildPlan diffs live resources against a desired state. Named entries take
+// precedence over selectors; a resource matched by two entries of the same kind is a problem.
+func BuildPlan(state *DesiredState, resources []CloudResource, policy *Policy) *Plan {
+	plan := &Plan{}
+	matched := make([]int, len(state.Resources))
+
+	for _, resource := range resources {
+		var named, selected []int
+		for i, desired := range state.Resources {
+			if !desired.matches(resource) {
+				continue
+			}
+			matched[i]++
+			if desired.Selector != nil {
+				selected = append(selected, i)
+			} else {
+				named = append(named, i)
+			}
+		}
+
+		candidates := named
+		if len(candidates) == 0 {
+			candidates = selected
+		}
+		if len(candidates) == 0 {
+			plan.Unmanaged = append(plan.Unmanaged, resource)
+			continue
+		}
+		if len(candidates) > 1 {
+			entries := make([]string, len(candidates))
+			for i, c := range candidates {
+				entries[i] = state.Resources[c].describe()
+			}
+			plan.Problems = append(plan.Problems, fmt.Sprintf("%s is matched by both %s",
+				resourceRefOf(resource), strings.Join(entries, " and ")))
+			continue
+		}
+
+		desired := state.Resources[candidates[0]].authorizedNetworks()
+		resourcePlan := ResourcePlan{
+			Resource: resource,
+			Desired:  desired,
+			Diff:     diffNetworks(resourceNetworks(resource), desired),
+		}
+		resourcePlan.Err = checkPlannedNetworks(resource, desired, resourcePlan.Diff, policy)
+		plan.Resources = append(plan.Resources, resourcePlan)
+	}
+
+	for i, count := range matched {
+		if count == 0 && state.Resources[i].Selector == nil {
+			plan.Problems = append(plan.Problems, fmt.Sprintf("%s did not match any discovered resource", state.Resources[i].describe()))
+		}
+	}
+
+	return plan
+}
+
+// checkPlannedNetworks explains why a planned change can't be applied, or returns nil
+func checkPlannedNetworks(resource CloudResource, desired []AuthorizedNetwork, diff NetworkDiff, policy *Policy) error {
+	if diff.Empty() {
+		return nil
+	}
+	if len(diff.Added) > 0 && !resource.CanAddNetwork() {
+		return fmt.Errorf("cannot add networks: %s", resource.GetNetworkRestrictions())
+	}
+
+	var violations []PolicyViolation
+	for _, network := range diff.Added {
+		// Count the rest of the desired list against the entry limit
+		var others []AuthorizedNetwork
+		for _, other := range desired {
+			if other.Value != network.Value {
+				others = append(others, other)
+			}
+		}
+		violations = append(violations, policy.Evaluate(resource, network.Name, network.Value, others)...)
+	}
+	if len(violations) > 0 {
+		return &PolicyError{Violations: violations}
+	}
+	return nil
+}
+
+// Changed returns the resources whose networks differ from the desired state
+func (p *Plan) Changed() []ResourcePlan {
+	var changed []ResourcePlan
+	for _, resourcePlan := range p.Resources {
+		if !resourcePlan.Diff.Empty() {
+			changed = append(changed, resourcePlan)
+		}
+	}
+	return changed
+}
+
+// ApplyPlan converges every changed resource that has no problems, with one patch per resource
+func (nm *NetworkManager) ApplyPlan(plan *Plan) []ResourceResult {
+	var resources []CloudResource
+	desired := make(map[string][]AuthorizedNetwork)
+	for _, resourcePlan := range plan.Changed() {
+		if resourcePlan.Err != nil {
+			continue
+		}
+		resources = append(resources, resourcePlan.Resource)
+		desired[resourceKey(resourcePlan.Resource)] = resourcePlan.Desired
+	}
+
+	return nm.forEachResource(resources, func(resource CloudResource) error {
+		networks := desired[resourceKey(resource)]
+		return nm.audited("apply", resource, fmt.Sprintf("desired state, %d networks", len(networks)), func(CloudResource) (string, error) {
+			return nm.setResourceNetworks(resource, networks)
+		})
+	})
+}
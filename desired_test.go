@@ -0,0 +1,214 @@
+package main
+
+import (
+	"reflect"
+	"sort"
+	"testing"
+)
+
+func TestBuildPlan(t *testing.T) {
+	prod := SQLInstance{
+		Name: "orders", Project: "prod-project", Region: "us-central1", PublicIPEnabled: true,
+		Labels:             map[string]string{"env": "prod"},
+		AuthorizedNetworks: []AuthorizedNetwork{{Name: "office", Value: "203.0.113.0/24"}},
+	}
+	staging := SQLInstance{
+		Name: "orders", Project: "staging-project", Region: "us-central1", PublicIPEnabled: true,
+		Labels:             map[string]string{"env": "staging"},
+		AuthorizedNetworks: []AuthorizedNetwork{{Name: "office", Value: "203.0.113.0/24"}},
+	}
+	cluster := GKECluster{
+		Name: "apps", Project: "prod-project", Location: "us-central1", PublicEndpoint: "34.1.2.3",
+		MasterAuthorizedNetworksEnabled: true,
+		Labels:                          map[string]string{"env": "prod"},
+		MasterAuthorizedNetworks:        []AuthorizedNetwork{{DisplayName: "office", Value: "203.0.113.0/24"}},
+	}
+	private := SQLInstance{Name: "ledger", Project: "prod-project", Region: "us-central1"}
+	resources := []CloudResource{prod, staging, cluster, private}
+
+	office := DesiredNetwork{Name: "office", CIDR: "203.0.113.0/24"}
+	vpn := DesiredNetwork{Name: "vpn", CIDR: "198.51.100.0/24"}
+	prodSelector := &DesiredSelector{Labels: map[string]string{"env": "prod"}}
+
+	tests := []struct {
+		name          string
+		state         DesiredState
+		policy        PolicyConfig
+		wantManaged   []string
+		wantChanged   []string
+		wantBlocked   []string // Changed resources with an Err
+		wantUnmanaged []string
+		wantProblems  int
+	}{
+		{
+			name:          "nothing desired",
+			wantUnmanaged: []string{"apps", "ledger", "orders", "orders"},
+		},
+		{
+			name:          "named entry unchanged",
+			state:         DesiredState{Resources: []DesiredResource{{Project: "prod-project", Name: "orders", Networks: []DesiredNetwork{office}}}},
+			wantManaged:   []string{"orders"},
+			wantUnmanaged: []string{"apps", "ledger", "orders"},
+		},
+		{
+			name:          "named entry adds a network",
+			state:         DesiredState{Resources: []DesiredResource{{Project: "prod-project", Name: "orders", Networks: []DesiredNetwork{office, vpn}}}},
+			wantManaged:   []string{"orders"},
+			wantChanged:   []string{"orders"},
+			wantUnmanaged: []string{"apps", "ledger", "orders"},
+		},
+		{
+			name: "selector matches every labelled resource",
+			state: DesiredState{Resources: []DesiredResource{
+				{Selector: prodSelector, Networks: []DesiredNetwork{vpn}},
+			}},
+			wantManaged:   []string{"apps", "orders"},
+			wantChanged:   []string{"apps", "orders"},
+			wantUnmanaged: []string{"ledger", "orders"},
+		},
+		{
+			name: "selector limited to a type",
+			state: DesiredState{Resources: []DesiredResource{
+				{Selector: &DesiredSelector{Labels: map[string]string{"env": "prod"}, Types: []ResourceType{ResourceTypeGKE}}, Networks: []DesiredNetwork{office}},
+			}},
+			wantManaged:   []string{"apps"},
+			wantUnmanaged: []string{"ledger", "orders", "orders"},
+		},
+		{
+			name: "named entry wins over a selector",
+			state: DesiredState{Resources: []DesiredResource{
+				{Selector: prodSelector, Networks: []DesiredNetwork{vpn}},
+				{Project: "prod-project", Name: "orders", Networks: []DesiredNetwork{office}},
+			}},
+			wantManaged:   []string{"apps", "orders"},
+			wantChanged:   []string{"apps"},
+			wantUnmanaged: []string{"ledger", "orders"},
+		},
+		{
+			name: "two selectors on one resource",
+			state: DesiredState{Resources: []DesiredResource{
+				{Selector: prodSelector, Networks: []DesiredNetwork{vpn}},
+				{Selector: &DesiredSelector{Labels: map[string]string{"env": "prod"}, Projects: []string{"prod-project"}}, Networks: []DesiredNetwork{office}},
+			}},
+			wantUnmanaged: []string{"ledger", "orders"},
+			wantProblems:  2,
+		},
+		{
+			name: "named entry matching nothing",
+			state: DesiredState{Resources: []DesiredResource{
+				{Project: "prod-project", Name: "missing", Networks: []DesiredNetwork{office}},
+			}},
+			wantUnmanaged: []string{"apps", "ledger", "orders", "orders"},
+			wantProblems:  1,
+		},
+		{
+			name: "location narrows a named entry",
+			state: DesiredState{Resources: []DesiredResource{
+				{Project: "prod-project", Name: "orders", Location: "europe-west1", Networks: []DesiredNetwork{office}},
+			}},
+			wantUnmanaged: []string{"apps", "ledger", "orders", "orders"},
+			wantProblems:  1,
+		},
+		{
+			name: "private resource can't take additions",
+			state: DesiredState{Resources: []DesiredResource{
+				{Project: "prod-project", Name: "ledger", Networks: []DesiredNetwork{vpn}},
+			}},
+			wantManaged:   []string{"ledger"},
+			wantChanged:   []string{"ledger"},
+			wantBlocked:   []string{"ledger"},
+			wantUnmanaged: []string{"apps", "orders", "orders"},
+		},
+		{
+			name: "policy blocks an addition",
+			state: DesiredState{Resources: []DesiredResource{
+				{Project: "prod-project", Name: "orders", Networks: []DesiredNetwork{office, vpn}},
+			}},
+			policy:        PolicyConfig{PolicyRules: PolicyRules{MaxEntries: intPtr(1)}},
+			wantManaged:   []string{"orders"},
+			wantChanged:   []string{"orders"},
+			wantBlocked:   []string{"orders"},
+			wantUnmanaged: []string{"apps", "ledger", "orders"},
+		},
+		{
+			name: "policy allows removals",
+			state: DesiredState{Resources: []DesiredResource{
+				{Project: "prod-project", Name: "orders", Networks: []DesiredNetwork{}},
+			}},
+			policy:        PolicyConfig{PolicyRules: PolicyRules{MaxEntries: intPtr(0)}},
+			wantManaged:   []string{"orders"},
+			wantChanged:   []string{"orders"},
+			wantUnmanaged: []string{"apps", "ledger", "orders"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			policy, err := NewPolicy(tt.policy)
+			if err != nil {
+				t.Fatalf("NewPolicy: %v", err)
+			}
+			plan := BuildPlan(&tt.state, resources, policy)
+
+			var managed, changed, blocked, unmanaged []string
+			for _, resourcePlan := range plan.Resources {
+				managed = append(managed, resourcePlan.Resource.GetName())
+			}
+			for _, resourcePlan := range plan.Changed() {
+				changed = append(changed, resourcePlan.Resource.GetName())
+				if resourcePlan.Err != nil {
+					blocked = append(blocked, resourcePlan.Resource.GetName())
+				}
+			}
+			for _, resource := range plan.Unmanaged {
+				unmanaged = append(unmanaged, resource.GetName())
+			}
+			for _, names := range [][]string{managed, changed, blocked, unmanaged} {
+				sort.Strings(names)
+			}
+
+			if !reflect.DeepEqual(managed, tt.wantManaged) {
+				t.Errorf("managed = %v, want %v", managed, tt.wantManaged)
+			}
+			if !reflect.DeepEqual(changed, tt.wantChanged) {
+				t.Errorf("changed = %v, want %v", changed, tt.wantChanged)
+			}
+			if !reflect.DeepEqual(blocked, tt.wantBlocked) {
+				t.Errorf("blocked = %v, want %v", blocked, tt.wantBlocked)
+			}
+			if !reflect.DeepEqual(unmanaged, tt.wantUnmanaged) {
+				t.Errorf("unmanaged = %v, want %v", unmanaged, tt.wantUnmanaged)
+			}
+			if len(plan.Problems) != tt.wantProblems {
+				t.Errorf("problems = %q, want %d", plan.Problems, tt.wantProblems)
+			}
+		})
+	}
+}
+
+func TestBuildPlanDiff(t *testing.T) {
+	instance := SQLInstance{
+		Name: "orders", Project: "prod-project", PublicIPEnabled: true,
+		AuthorizedNetworks: []AuthorizedNetwork{
+			{Name: "office", Value: "203.0.113.0/24"},
+			{Name: "old", Value: "192.0.2.1/32"},
+		},
+	}
+	state := &DesiredState{Resources: []DesiredResource{{
+		Project: "prod-project", Name: "orders",
+		Networks: []DesiredNetwork{{Name: "hq", CIDR: "203.0.113.0/24"}, {Name: "vpn", CIDR: "198.51.100.0/24"}},
+	}}}
+
+	plan := BuildPlan(state, []CloudResource{instance}, nil)
+	if len(plan.Resources) != 1 {
+		t.Fatalf("planned %d resources, want 1", len(plan.Resources))
+	}
+	want := NetworkDiff{
+		Added:   []AuthorizedNetwork{{Name: "vpn", Value: "198.51.100.0/24"}},
+		Removed: []AuthorizedNetwork{{Name: "old", Value: "192.0.2.1/32"}},
+		Renamed: []NetworkRename{{Value: "203.0.113.0/24", From: "office", To: "hq"}},
+	}
+	if got := plan.Resources[0].Diff; !reflect.DeepEqual(got, want) {
+		t.Errorf("diff = %+v, want %+v", got, want)
+	}
+}
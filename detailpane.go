@@ -0,0 +1,170 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// detailPaneWidth is the width of the resource detail pane, borders included
+const detailPaneWidth = 56
+
+// detailField is one line of the resource detail pane; fields with several values
+// repeat without a label
+type detailField struct {
+	label string
+	value string
+}
+
+// resourceDetailFields lists what is known about a resource beyond its networks
+func resourceDetailFields(resource CloudResource) []detailField {
+	var fields []detailField
+	add := func(label, value string) {
+		if value != "" {
+			fields = append(fields, detailField{label, value})
+		}
+	}
+	addAll := func(label string, values []string) {
+		for i, value := range values {
+			if i > 0 {
+				label = ""
+			}
+			add(label, value)
+		}
+	}
+
+	switch r := resource.(type) {
+	case SQLInstance:
+		add("State", r.State)
+		if r.ActivationPolicy == "NEVER" {
+			add("Activation", "stopped")
+		}
+		add("Version", r.DatabaseVersion)
+		add("Tier", r.Tier)
+		add("Availability", r.AvailabilityType)
+		if r.IsReplica() {
+			add("Replica of", r.MasterInstanceName)
+		}
+		addAll("Replicas", r.ReplicaNames)
+		add("Connection", r.ConnectionName)
+		var addresses []string
+		for _, ip := range r.IPAddresses {
+			addresses = append(addresses, fmt.Sprintf("%s (%s)", ip.Address, strings.ToLower(ip.Type)))
+		}
+		if len(addresses) == 0 && r.PrivateIP != "" {
+			addresses = append(addresses, r.PrivateIP+" (private)")
+		}
+		addAll("IP addresses", addresses)
+		add("Public IP", enabledLabel(r.PublicIPEnabled))
+		add("PSC", enabledLabel(r.PSCEnabled))
+		add("Maintenance", r.MaintenanceWindow)
+		addAll("Labels", formatLabels(r.Labels))
+
+	case GKECluster:
+		add("State", r.State)
+		if r.Autopilot {
+			add("Mode", "Autopilot")
+		}
+		add("Control plane", r.MasterVersion)
+		if r.NodeVersion != "" && r.NodeVersion != r.MasterVersion {
+			add("Nodes version", r.NodeVersion)
+		}
+		if r.NodeCount > 0 {
+			add("Nodes", fmt.Sprintf("%d", r.NodeCount))
+		}
+		var pools []string
+		for _, pool := range r.NodePools {
+			pools = append(pools, formatNodePool(pool))
+		}
+		addAll("Node pools", pools)
+		add("Public endpoint", r.PublicEndpoint)
+		add("Private endpoint", r.PrivateEndpoint)
+		add("Private nodes", enabledLabel(r.PrivateClusterEnabled))
+		add("Maintenance", r.MaintenanceWindow)
+		addAll("Labels", formatLabels(r.Labels))
+
+	case AWSSecurityGroup:
+		add("Group ID", r.GroupID)
+		add("VPC", r.VpcID)
+		add("Account", r.Account)
+		add("Region", r.Region)
+		add("Description", r.Description)
+		var ports []string
+		for _, port := range r.ManagedPorts {
+			ports = append(ports, fmt.Sprintf("%d", port))
+		}
+		add("Ports", strings.Join(ports, ", "))
+		addAll("Tags", formatLabels(r.Tags))
+
+	case AzureDatabaseServer:
+		add("Kind", r.kindLabel())
+		add("State", r.State)
+		add("FQDN", r.FQDN)
+		add("Resource group", r.ResourceGroup)
+		add("Location", r.Location)
+		add("Public access", enabledLabel(r.PublicNetworkAccess))
+		addAll("Tags", formatLabels(r.Tags))
+	}
+	return fields
+}
+
+// formatNodePool describes a node pool, e.g. "default: e2-medium, 3/zone × 3 zones, autoscaling 1-5"
+func formatNodePool(pool NodePool) string {
+	parts := []string{pool.Name + ":"}
+	if pool.MachineType != "" {
+		parts = append(parts, pool.MachineType+",")
+	}
+	nodes := fmt.Sprintf("%d nodes", pool.NodeCount)
+	if pool.Zones > 1 {
+		nodes = fmt.Sprintf("%d/zone × %d zones", pool.NodeCount, pool.Zones)
+	}
+	parts = append(parts, nodes)
+	if pool.MaxNodes > 0 {
+		parts[len(parts)-1] += ","
+		parts = append(parts, fmt.Sprintf("autoscaling %d-%d", pool.MinNodes, pool.MaxNodes))
+	}
+	return strings.Join(parts, " ")
+}
+
+// formatLabels returns labels as sorted "key=value" strings
+func formatLabels(labels map[string]string) []string {
+	formatted := make([]string, 0, len(labels))
+	for key, value := range labels {
+		formatted = append(formatted, key+"="+value)
+	}
+	sort.Strings(formatted)
+	return formatted
+}
+
+// detailPaneLayout reports whether the detail pane fits next to the network view's body,
+// and whether it is shown: by default only when it fits, and I flips that
+func (m Model) detailPaneLayout(body string) (beside, shown bool) {
+	beside = m.width >= lipgloss.Width(body)+detailPaneWidth+2
+	return beside, beside != m.detailsToggled
+}
+
+// detailPaneReplacesBody reports whether the detail pane is shown instead of the network table
+func (m Model) detailPaneReplacesBody() bool {
+	beside, shown := m.detailPaneLayout(m.renderNetworkBody())
+	return shown && !beside
+}
+
+// renderDetailPane renders the selected resource's details
+func (m Model) renderDetailPane() string {
+	labelWidth := 18
+	valueWidth := detailPaneWidth - labelWidth - 6
+
+	lines := []string{TitleStyle.Render("ℹ️  Details"), ""}
+	fields := resourceDetailFields(m.selectedResource)
+	if len(fields) == 0 {
+		lines = append(lines, EmptyStateStyle.Render("No details available"))
+	}
+	for _, field := range fields {
+		label := LabelStyle.Copy().Width(labelWidth).Render(field.label)
+		value := lipgloss.NewStyle().Width(valueWidth).Render(field.value)
+		lines = append(lines, lipgloss.JoinHorizontal(lipgloss.Top, label, value))
+	}
+	return FormBoxStyle.Copy().Width(detailPaneWidth - 2).Render(lipgloss.JoinVertical(lipgloss.Left, lines...))
+}
@@ -0,0 +1,85 @@
+package main
+
+import "fmt"
+
+// NetworkRename is an entry whose CIDR is unchanged but whose name differs
+type NetworkRename struct {
+	Value string
+	From  string
+	To    string
+}
+
+// NetworkDiff describes how to get from one network list to another
+type NetworkDiff struct {
+	Added   []AuthorizedNetwork
+	Removed []AuthorizedNetwork
+	Renamed []NetworkRename
+}
+
+// diffNetworks compares two network lists by CIDR. Entries repeated per port (AWS)
+// count once, under the first name seen.
+func diffNetworks(current, target []AuthorizedNetwork) NetworkDiff {
+	currentByValue, currentOrder := indexNetworks(current)
+	targetByValue, targetOrder := indexNetworks(target)
+
+	var diff NetworkDiff
+	for _, value := range targetOrder {
+		want := targetByValue[value]
+		have, ok := currentByValue[value]
+		if !ok {
+			diff.Added = append(diff.Added, want)
+		} else if networkName(have) != networkName(want) {
+			diff.Renamed = append(diff.Renamed, NetworkRename{Value: value, From: networkName(have), To: networkName(want)})
+		}
+	}
+	for _, value := range currentOrder {
+		if _, ok := targetByValue[value]; !ok {
+			diff.Removed = append(diff.Removed, currentByValue[value])
+		}
+	}
+	return diff
+}
+
+// indexNetworks maps normalised CIDRs to their first entry, keeping the original order
+func indexNetworks(networks []AuthorizedNetwork) (map[string]AuthorizedNetwork, []string) {
+	byValue := make(map[string]AuthorizedNetwork)
+	var order []string
+	for _, network := range networks {
+		value, _ := normalizeIP(network.Value)
+		if _, seen := byValue[value]; seen {
+			continue
+		}
+		network.Value = value
+		byValue[value] = network
+		order = append(order, value)
+	}
+	return byValue, order
+}
+
+// networkName returns the name shown for an entry
+func networkName(network AuthorizedNetwork) string {
+	if network.Name != "" {
+		return network.Name
+	}
+	return network.DisplayName
+}
+
+// Empty reports whether the lists are equivalent
+func (d NetworkDiff) Empty() bool {
+	return len(d.Added) == 0 && len(d.Removed) == 0 && len(d.Renamed) == 0
+}
+
+// Lines renders the diff as "+", "-" and "~" lines
+func (d NetworkDiff) Lines() []string {
+	var lines []string
+	for _, network := range d.Added {
+		lines = append(lines, fmt.Sprintf("+ %-20s %s", network.Value, networkName(network)))
+	}
+	for _, network := range d.Removed {
+		lines = append(lines, fmt.Sprintf("- %-20s %s", network.Value, networkName(network)))
+	}
+	for _, rename := range d.Renamed {
+		lines = append(lines, fmt.Sprintf("~ %-20s %s → %s", rename.Value, rename.From, rename.To))
+	}
+	return lines
+}
@@ -0,0 +1,75 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDiffNetworks(t *testing.T) {
+	tests := []struct {
+		name    string
+		current []AuthorizedNetwork
+		target  []AuthorizedNetwork
+		want    NetworkDiff
+	}{
+		{
+			name: "both empty",
+		},
+		{
+			name:    "identical",
+			current: []AuthorizedNetwork{{Name: "office", Value: "203.0.113.0/24"}},
+			target:  []AuthorizedNetwork{{Name: "office", Value: "203.0.113.0/24"}},
+		},
+		{
+			name:    "single IP matches its /32",
+			current: []AuthorizedNetwork{{Name: "laptop", Value: "203.0.113.7"}},
+			target:  []AuthorizedNetwork{{Name: "laptop", Value: "203.0.113.7/32"}},
+		},
+		{
+			name:    "added and removed",
+			current: []AuthorizedNetwork{{Name: "old", Value: "198.51.100.0/24"}, {Name: "office", Value: "203.0.113.0/24"}},
+			target:  []AuthorizedNetwork{{Name: "office", Value: "203.0.113.0/24"}, {Name: "new", Value: "192.0.2.1/32"}},
+			want: NetworkDiff{
+				Added:   []AuthorizedNetwork{{Name: "new", Value: "192.0.2.1/32"}},
+				Removed: []AuthorizedNetwork{{Name: "old", Value: "198.51.100.0/24"}},
+			},
+		},
+		{
+			name:    "renamed",
+			current: []AuthorizedNetwork{{Name: "office", Value: "203.0.113.0/24"}},
+			target:  []AuthorizedNetwork{{Name: "hq", Value: "203.0.113.0/24"}},
+			want:    NetworkDiff{Renamed: []NetworkRename{{Value: "203.0.113.0/24", From: "office", To: "hq"}}},
+		},
+		{
+			name:    "GKE display names",
+			current: []AuthorizedNetwork{{DisplayName: "office", Value: "203.0.113.0/24"}},
+			target:  []AuthorizedNetwork{{Name: "office", Value: "203.0.113.0/24"}},
+		},
+		{
+			name: "entries repeated per port count once",
+			current: []AuthorizedNetwork{
+				{Name: "vpn", Value: "198.51.100.0/24", Port: "5432"},
+				{Name: "vpn", Value: "198.51.100.0/24", Port: "6432"},
+			},
+			target: []AuthorizedNetwork{{Name: "vpn", Value: "198.51.100.0/24"}},
+		},
+		{
+			name:    "everything removed",
+			current: []AuthorizedNetwork{{Name: "a", Value: "192.0.2.1/32"}, {Name: "b", Value: "192.0.2.2/32"}},
+			want: NetworkDiff{
+				Removed: []AuthorizedNetwork{{Name: "a", Value: "192.0.2.1/32"}, {Name: "b", Value: "192.0.2.2/32"}},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := diffNetworks(tt.current, tt.target)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("diffNetworks() = %+v, want %+v", got, tt.want)
+			}
+			if got.Empty() != tt.want.Empty() {
+				t.Errorf("Empty() = %v, want %v", got.Empty(), tt.want.Empty())
+			}
+		})
+	}
+}
@@ -0,0 +1,409 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// ResourceDrift is how one resource's live networks differ from the baseline
+type ResourceDrift struct {
+	Resource ResourceRef
+	Diff     NetworkDiff // Baseline to live: Added entries exist live but not in the baseline
+}
+
+// UnmanagedResource is a live resource the baseline doesn't mention
+type UnmanagedResource struct {
+	Resource ResourceRef
+	Networks []AuthorizedNetwork
+	Open     bool // Reachable from any IP, see openToWorld
+}
+
+// DriftReport is the result of comparing live resources with a baseline
+type DriftReport struct {
+	Baseline  string
+	Resources []ResourceDrift     // Every resource checked, drifted or not
+	Unmanaged []UnmanagedResource // Resources outside the baseline: open ones, or all with --include-unmanaged
+	Problems  []string            // Baseline entries that are missing or ambiguous
+}
+
+// Drifted returns the resources whose live networks differ from the baseline
+func (r *DriftReport) Drifted() []ResourceDrift {
+	var drifted []ResourceDrift
+	for _, resource := range r.Resources {
+		if !resource.Diff.Empty() {
+			drifted = append(drifted, resource)
+		}
+	}
+	return drifted
+}
+
+// HasDrift reports whether anything differs from the baseline
+func (r *DriftReport) HasDrift() bool {
+	return len(r.Drifted()) > 0 || len(r.Unmanaged) > 0 || len(r.Problems) > 0
+}
+
+// LoadBaseline reads a baseline for drift checks: a desired-state file, an export,
+// a snapshot, or a JSON array of snapshots. Snapshots become named desired-state entries.
+func LoadBaseline(path string) (*DesiredState, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read baseline: %v", err)
+	}
+
+	export, isExport, err := parseExport(data)
+	if err != nil {
+		return nil, err
+	}
+	if isExport {
+		return snapshotsToDesiredState(export.snapshots(), path)
+	}
+
+	trimmed := bytes.TrimSpace(data)
+	if bytes.HasPrefix(trimmed, []byte("[")) {
+		var snapshots []Snapshot
+		if err := json.Unmarshal(trimmed, &snapshots); err != nil {
+			return nil, fmt.Errorf("failed to parse snapshots %s: %v", path, err)
+		}
+		return snapshotsToDesiredState(snapshots, path)
+	}
+	if bytes.HasPrefix(trimmed, []byte("{")) {
+		var probe map[string]json.RawMessage
+		if err := json.Unmarshal(trimmed, &probe); err == nil {
+			if _, ok := probe["resources"]; !ok {
+				var snapshot Snapshot
+				if err := json.Unmarshal(trimmed, &snapshot); err != nil {
+					return nil, fmt.Errorf("failed to parse snapshot %s: %v", path, err)
+				}
+				return snapshotsToDesiredState([]Snapshot{snapshot}, path)
+			}
+		}
+	}
+
+	return LoadDesiredState(path)
+}
+
+// snapshotsToDesiredState turns snapshots into a desired state naming each resource exactly
+func snapshotsToDesiredState(snapshots []Snapshot, path string) (*DesiredState, error) {
+	state := &DesiredState{Version: 1}
+	for _, snapshot := range snapshots {
+		if snapshot.Resource.Name == "" {
+			return nil, fmt.Errorf("snapshot %q in %s has no resource", snapshot.ID, path)
+		}
+		byValue, order := indexNetworks(snapshot.Networks)
+		desired := DesiredResource{
+			Type:     snapshot.Resource.Type,
+			Project:  snapshot.Resource.Project,
+			Location: snapshot.Resource.Location,
+			Name:     snapshot.Resource.Name,
+			Networks: []DesiredNetwork{},
+		}
+		for _, value := range order {
+			desired.Networks = append(desired.Networks, DesiredNetwork{Name: networkName(byValue[value]), CIDR: value})
+		}
+		state.Resources = append(state.Resources, desired)
+	}
+	return state, nil
+}
+
+// CheckDrift compares live resources with a baseline. Resources the baseline doesn't
+// mention are reported when they are open to the world, or all of them with includeUnmanaged.
+func CheckDrift(baseline *DesiredState, baselinePath string, resources []CloudResource, includeUnmanaged bool) *DriftReport {
+	plan := BuildPlan(baseline, resources, nil)
+	report := &DriftReport{Baseline: baselinePath, Problems: plan.Problems}
+	for _, resourcePlan := range plan.Resources {
+		report.Resources = append(report.Resources, ResourceDrift{
+			Resource: resourceRefOf(resourcePlan.Resource),
+			Diff:     diffNetworks(resourcePlan.Desired, resourceNetworks(resourcePlan.Resource)),
+		})
+	}
+	for _, resource := range plan.Unmanaged {
+		open := openToWorld(resource)
+		if open || includeUnmanaged {
+			report.Unmanaged = append(report.Unmanaged, UnmanagedResource{
+				Resource: resourceRefOf(resource),
+				Networks: resourceNetworks(resource),
+				Open:     open,
+			})
+		}
+	}
+	return report
+}
+
+// summary describes an unmanaged resource in one line, e.g. "open to the world and not in the baseline, 3 networks"
+func (u UnmanagedResource) summary() string {
+	if u.Open {
+		return fmt.Sprintf("open to the world and not in the baseline, %d networks", len(u.Networks))
+	}
+	return fmt.Sprintf("not in the baseline, %d networks", len(u.Networks))
+}
+
+// lines lists an unmanaged resource's networks, e.g. "? 0.0.0.0/0 (anywhere)"
+func (u UnmanagedResource) lines() []string {
+	lines := make([]string, len(u.Networks))
+	for i, network := range u.Networks {
+		lines[i] = fmt.Sprintf("? %s (%s)", network.Value, networkName(network))
+	}
+	return lines
+}
+
+// summary describes a drift in one line, e.g. "2 added, 1 removed"
+func (d ResourceDrift) summary() string {
+	var parts []string
+	if n := len(d.Diff.Added); n > 0 {
+		parts = append(parts, fmt.Sprintf("%d added", n))
+	}
+	if n := len(d.Diff.Removed); n > 0 {
+		parts = append(parts, fmt.Sprintf("%d removed", n))
+	}
+	if n := len(d.Diff.Renamed); n > 0 {
+		parts = append(parts, fmt.Sprintf("%d renamed", n))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// WriteText writes a human-readable drift report
+func (r *DriftReport) WriteText(w io.Writer) error {
+	drifted := r.Drifted()
+	for _, drift := range drifted {
+		fmt.Fprintf(w, "~ %s (%s)\n", drift.Resource, drift.summary())
+		for _, line := range drift.Diff.Lines() {
+			fmt.Fprintf(w, "    %s\n", line)
+		}
+		fmt.Fprintln(w)
+	}
+	for _, unmanaged := range r.Unmanaged {
+		fmt.Fprintf(w, "? %s (%s)\n", unmanaged.Resource, unmanaged.summary())
+		for _, line := range unmanaged.lines() {
+			fmt.Fprintf(w, "    %s\n", line)
+		}
+		fmt.Fprintln(w)
+	}
+	for _, problem := range r.Problems {
+		fmt.Fprintf(w, "! %s\n", problem)
+	}
+	if len(r.Problems) > 0 {
+		fmt.Fprintln(w)
+	}
+	_, err := fmt.Fprintf(w, "Drift: %d of %d resources differ from %s, %d unmanaged reported, %d baseline problems.\n",
+		len(drifted), len(r.Resources), r.Baseline, len(r.Unmanaged), len(r.Problems))
+	return err
+}
+
+type junitTestSuites struct {
+	XMLName  xml.Name         `xml:"testsuites"`
+	Name     string           `xml:"name,attr"`
+	Tests    int              `xml:"tests,attr"`
+	Failures int              `xml:"failures,attr"`
+	Suites   []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name      string          `xml:"name,attr"`
+	Tests     int             `xml:"tests,attr"`
+	Failures  int             `xml:"failures,attr"`
+	TestCases []junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	ClassName string        `xml:"classname,attr"`
+	Name      string        `xml:"name,attr"`
+	Failure   *junitFailure `xml:"failure,omitempty"`
+}
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr"`
+	Body    string `xml:",chardata"`
+}
+
+// WriteJUnit writes the report as JUnit XML, one test case per resource and baseline problem.
+// Reported unmanaged resources are failing test cases.
+func (r *DriftReport) WriteJUnit(w io.Writer) error {
+	suite := junitTestSuite{Name: "piam-anc drift: " + r.Baseline}
+	for _, drift := range r.Resources {
+		testCase := junitTestCase{
+			ClassName: fmt.Sprintf("%s.%s", drift.Resource.Type, drift.Resource.Project),
+			Name:      drift.Resource.Name,
+		}
+		if !drift.Diff.Empty() {
+			testCase.Failure = &junitFailure{
+				Message: drift.summary(),
+				Type:    "drift",
+				Body:    strings.Join(drift.Diff.Lines(), "\n"),
+			}
+			suite.Failures++
+		}
+		suite.TestCases = append(suite.TestCases, testCase)
+	}
+	for _, unmanaged := range r.Unmanaged {
+		suite.TestCases = append(suite.TestCases, junitTestCase{
+			ClassName: fmt.Sprintf("%s.%s", unmanaged.Resource.Type, unmanaged.Resource.Project),
+			Name:      unmanaged.Resource.Name,
+			Failure: &junitFailure{
+				Message: unmanaged.summary(),
+				Type:    "unmanaged",
+				Body:    strings.Join(unmanaged.lines(), "\n"),
+			},
+		})
+		suite.Failures++
+	}
+	for _, problem := range r.Problems {
+		suite.TestCases = append(suite.TestCases, junitTestCase{
+			ClassName: "baseline",
+			Name:      problem,
+			Failure:   &junitFailure{Message: problem, Type: "baseline"},
+		})
+		suite.Failures++
+	}
+	suite.Tests = len(suite.TestCases)
+
+	suites := junitTestSuites{
+		Name:     "piam-anc",
+		Tests:    suite.Tests,
+		Failures: suite.Failures,
+		Suites:   []junitTestSuite{suite},
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(suites); err != nil {
+		return fmt.Errorf("failed to write JUnit report: %v", err)
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+// Rule IDs used in SARIF reports
+const (
+	sarifRuleAdded     = "network-added"
+	sarifRuleRemoved   = "network-removed"
+	sarifRuleRenamed   = "network-renamed"
+	sarifRuleUnmanaged = "resource-unmanaged"
+	sarifRuleBaseline  = "baseline-problem"
+)
+
+// WriteSARIF writes the report as SARIF 2.1.0, one result per drifted entry
+func (r *DriftReport) WriteSARIF(w io.Writer) error {
+	type message struct {
+		Text string `json:"text"`
+	}
+	type rule struct {
+		ID               string  `json:"id"`
+		ShortDescription message `json:"shortDescription"`
+	}
+	type logicalLocation struct {
+		FullyQualifiedName string `json:"fullyQualifiedName"`
+		Kind               string `json:"kind"`
+	}
+	type location struct {
+		PhysicalLocation struct {
+			ArtifactLocation struct {
+				URI string `json:"uri"`
+			} `json:"artifactLocation"`
+		} `json:"physicalLocation"`
+		LogicalLocations []logicalLocation `json:"logicalLocations,omitempty"`
+	}
+	type result struct {
+		RuleID    string     `json:"ruleId"`
+		Level     string     `json:"level"`
+		Message   message    `json:"message"`
+		Locations []location `json:"locations"`
+	}
+
+	newLocation := func(name string) location {
+		var loc location
+		loc.PhysicalLocation.ArtifactLocation.URI = r.Baseline
+		if name != "" {
+			loc.LogicalLocations = []logicalLocation{{FullyQualifiedName: name, Kind: "resource"}}
+		}
+		return loc
+	}
+
+	results := []result{}
+	for _, drift := range r.Drifted() {
+		name := fmt.Sprintf("%s/%s/%s", drift.Resource.Type, drift.Resource.Project, drift.Resource.Name)
+		for _, network := range drift.Diff.Added {
+			results = append(results, result{
+				RuleID:    sarifRuleAdded,
+				Level:     "error",
+				Message:   message{fmt.Sprintf("%s: %s (%s) is authorized but not in the baseline", drift.Resource, network.Value, networkName(network))},
+				Locations: []location{newLocation(name)},
+			})
+		}
+		for _, network := range drift.Diff.Removed {
+			results = append(results, result{
+				RuleID:    sarifRuleRemoved,
+				Level:     "error",
+				Message:   message{fmt.Sprintf("%s: %s (%s) is in the baseline but no longer authorized", drift.Resource, network.Value, networkName(network))},
+				Locations: []location{newLocation(name)},
+			})
+		}
+		for _, rename := range drift.Diff.Renamed {
+			results = append(results, result{
+				RuleID:    sarifRuleRenamed,
+				Level:     "warning",
+				Message:   message{fmt.Sprintf("%s: %s was renamed from %q to %q", drift.Resource, rename.Value, rename.From, rename.To)},
+				Locations: []location{newLocation(name)},
+			})
+		}
+	}
+	for _, unmanaged := range r.Unmanaged {
+		name := fmt.Sprintf("%s/%s/%s", unmanaged.Resource.Type, unmanaged.Resource.Project, unmanaged.Resource.Name)
+		level := "warning"
+		if unmanaged.Open {
+			level = "error"
+		}
+		results = append(results, result{
+			RuleID:    sarifRuleUnmanaged,
+			Level:     level,
+			Message:   message{fmt.Sprintf("%s is %s", unmanaged.Resource, unmanaged.summary())},
+			Locations: []location{newLocation(name)},
+		})
+	}
+	for _, problem := range r.Problems {
+		results = append(results, result{
+			RuleID:    sarifRuleBaseline,
+			Level:     "error",
+			Message:   message{problem},
+			Locations: []location{newLocation("")},
+		})
+	}
+
+	log := map[string]interface{}{
+		"$schema": "https://json.schemastore.org/sarif-2.1.0.json",
+		"version": "2.1.0",
+		"runs": []interface{}{
+			map[string]interface{}{
+				"tool": map[string]interface{}{
+					"driver": map[string]interface{}{
+						"name":           "piam-anc",
+						"informationUri": "https://github.com/ExclamationLabs/piam-anc",
+						"rules": []rule{
+							{sarifRuleAdded, message{"Authorized network added outside the baseline"}},
+							{sarifRuleRemoved, message{"Authorized network in the baseline was removed"}},
+							{sarifRuleRenamed, message{"Authorized network was renamed"}},
+							{sarifRuleUnmanaged, message{"Resource outside the baseline, open to the world or reported with --include-unmanaged"}},
+							{sarifRuleBaseline, message{"Baseline resource missing or ambiguous"}},
+						},
+					},
+				},
+				"results": results,
+			},
+		},
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(log); err != nil {
+		return fmt.Errorf("failed to write SARIF report: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,151 @@
+package main
+
+import (
+	"reflect"
+	"sort"
+	"testing"
+)
+
+func TestCheckDrift(t *testing.T) {
+	managed := SQLInstance{
+		Name: "orders", Project: "prod-project", PublicIPEnabled: true,
+		AuthorizedNetworks: []AuthorizedNetwork{{Name: "office", Value: "203.0.113.0/24"}},
+	}
+	drifted := SQLInstance{
+		Name: "billing", Project: "prod-project", PublicIPEnabled: true,
+		AuthorizedNetworks: []AuthorizedNetwork{{Name: "office", Value: "203.0.113.0/24"}, {Name: "debug", Value: "192.0.2.1/32"}},
+	}
+	renamed := SQLInstance{
+		Name: "billing", Project: "prod-project", PublicIPEnabled: true,
+		AuthorizedNetworks: []AuthorizedNetwork{{Name: "hq", Value: "203.0.113.0/24"}},
+	}
+	emptied := SQLInstance{Name: "billing", Project: "prod-project", PublicIPEnabled: true}
+	openInstance := SQLInstance{
+		Name: "scratch", Project: "dev-project", PublicIPEnabled: true,
+		AuthorizedNetworks: []AuthorizedNetwork{{Name: "anywhere", Value: "0.0.0.0/0"}},
+	}
+	openCluster := GKECluster{Name: "sandbox", Project: "dev-project", PublicEndpoint: "34.1.2.3"}
+	closedInstance := SQLInstance{
+		Name: "reports", Project: "dev-project", PublicIPEnabled: true,
+		AuthorizedNetworks: []AuthorizedNetwork{{Name: "office", Value: "203.0.113.0/24"}},
+	}
+	privateOpen := SQLInstance{
+		Name: "ledger", Project: "dev-project",
+		AuthorizedNetworks: []AuthorizedNetwork{{Name: "anywhere", Value: "0.0.0.0/0"}},
+	}
+
+	office := DesiredNetwork{Name: "office", CIDR: "203.0.113.0/24"}
+	baseline := &DesiredState{Resources: []DesiredResource{
+		{Project: "prod-project", Name: "orders", Networks: []DesiredNetwork{office}},
+		{Project: "prod-project", Name: "billing", Networks: []DesiredNetwork{office}},
+	}}
+
+	tests := []struct {
+		name             string
+		resources        []CloudResource
+		includeUnmanaged bool
+		wantDrifted      []string
+		wantLines        int // Diff lines of the drifted resources
+		wantUnmanaged    []string
+		wantProblems     int
+	}{
+		{
+			name:         "managed resource missing",
+			resources:    []CloudResource{managed},
+			wantProblems: 1,
+		},
+		{
+			name:        "added entry",
+			resources:   []CloudResource{managed, drifted},
+			wantDrifted: []string{"billing"},
+			wantLines:   1,
+		},
+		{
+			name:        "renamed entry",
+			resources:   []CloudResource{managed, renamed},
+			wantDrifted: []string{"billing"},
+			wantLines:   1,
+		},
+		{
+			name:        "removed entry",
+			resources:   []CloudResource{managed, emptied},
+			wantDrifted: []string{"billing"},
+			wantLines:   1,
+		},
+		{
+			name:        "closed unmanaged resource is ignored",
+			resources:   []CloudResource{managed, drifted, closedInstance},
+			wantDrifted: []string{"billing"},
+			wantLines:   1,
+		},
+		{
+			name:          "open unmanaged resources are reported",
+			resources:     []CloudResource{managed, drifted, openInstance, openCluster, closedInstance, privateOpen},
+			wantDrifted:   []string{"billing"},
+			wantLines:     1,
+			wantUnmanaged: []string{"sandbox", "scratch"},
+		},
+		{
+			name:             "include every unmanaged resource",
+			resources:        []CloudResource{managed, drifted, openInstance, closedInstance},
+			includeUnmanaged: true,
+			wantDrifted:      []string{"billing"},
+			wantLines:        1,
+			wantUnmanaged:    []string{"reports", "scratch"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			report := CheckDrift(baseline, "baseline.yaml", tt.resources, tt.includeUnmanaged)
+
+			var drifted, unmanaged []string
+			lines := 0
+			for _, drift := range report.Drifted() {
+				drifted = append(drifted, drift.Resource.Name)
+				lines += len(drift.Diff.Lines())
+			}
+			for _, resource := range report.Unmanaged {
+				unmanaged = append(unmanaged, resource.Resource.Name)
+			}
+			sort.Strings(drifted)
+			sort.Strings(unmanaged)
+
+			if !reflect.DeepEqual(drifted, tt.wantDrifted) {
+				t.Errorf("drifted = %v, want %v", drifted, tt.wantDrifted)
+			}
+			if lines != tt.wantLines {
+				t.Errorf("diff lines = %d, want %d", lines, tt.wantLines)
+			}
+			if !reflect.DeepEqual(unmanaged, tt.wantUnmanaged) {
+				t.Errorf("unmanaged = %v, want %v", unmanaged, tt.wantUnmanaged)
+			}
+			if len(report.Problems) != tt.wantProblems {
+				t.Errorf("problems = %q, want %d", report.Problems, tt.wantProblems)
+			}
+			if !report.HasDrift() {
+				t.Error("HasDrift() = false, want true")
+			}
+		})
+	}
+}
+
+func TestCheckDriftClean(t *testing.T) {
+	instance := SQLInstance{
+		Name: "orders", Project: "prod-project", PublicIPEnabled: true,
+		AuthorizedNetworks: []AuthorizedNetwork{{Name: "office", Value: "203.0.113.0/24"}},
+	}
+	closed := SQLInstance{Name: "reports", Project: "dev-project", PublicIPEnabled: true}
+	baseline := &DesiredState{Resources: []DesiredResource{
+		{Project: "prod-project", Name: "orders", Networks: []DesiredNetwork{{Name: "office", CIDR: "203.0.113.0/24"}}},
+	}}
+
+	report := CheckDrift(baseline, "baseline.yaml", []CloudResource{instance, closed}, false)
+	if report.HasDrift() {
+		t.Errorf("HasDrift() = true for a matching baseline: drifted %v, unmanaged %v, problems %v",
+			report.Drifted(), report.Unmanaged, report.Problems)
+	}
+	if len(report.Resources) != 1 {
+		t.Errorf("checked %d resources, want 1", len(report.Resources))
+	}
+}
@@ -0,0 +1,377 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	exportFormat  = "piam-anc-export"
+	exportVersion = 1
+)
+
+// ExportFile is a versioned backup of every discovered resource's networks
+type ExportFile struct {
+	Format     string             `json:"format"`
+	Version    int                `json:"version"`
+	ExportedAt time.Time          `json:"exported_at"`
+	ExportedBy string             `json:"exported_by,omitempty"`
+	Resources  []ExportedResource `json:"resources"`
+}
+
+// ExportedResource is one resource's networks in an export
+type ExportedResource struct {
+	Resource ResourceRef         `json:"resource"`
+	Networks []AuthorizedNetwork `json:"networks"`
+}
+
+// NewExport captures the networks of the given resources
+func NewExport(resources []CloudResource) *ExportFile {
+	export := &ExportFile{
+		Format:     exportFormat,
+		Version:    exportVersion,
+		ExportedAt: time.Now().UTC(),
+		ExportedBy: getGcloudAccount(),
+		Resources:  []ExportedResource{},
+	}
+	for _, resource := range resources {
+		networks := resourceNetworks(resource)
+		if networks == nil {
+			networks = []AuthorizedNetwork{}
+		}
+		export.Resources = append(export.Resources, ExportedResource{
+			Resource: resourceRefOf(resource),
+			Networks: networks,
+		})
+	}
+	return export
+}
+
+// Write encodes the export as indented JSON
+func (e *ExportFile) Write(w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(e); err != nil {
+		return fmt.Errorf("failed to write export: %v", err)
+	}
+	return nil
+}
+
+// parseExport decodes an export file, returning ok=false if the data isn't one
+func parseExport(data []byte) (*ExportFile, bool, error) {
+	var probe struct {
+		Format string `json:"format"`
+	}
+	if err := json.Unmarshal(data, &probe); err != nil || probe.Format != exportFormat {
+		return nil, false, nil
+	}
+
+	var export ExportFile
+	if err := json.Unmarshal(data, &export); err != nil {
+		return nil, true, fmt.Errorf("failed to parse export: %v", err)
+	}
+	if export.Version != exportVersion {
+		return nil, true, fmt.Errorf("unsupported export version %d", export.Version)
+	}
+	return &export, true, nil
+}
+
+// snapshots converts an export to snapshots, so it can be used as a drift baseline
+func (e *ExportFile) snapshots() []Snapshot {
+	snapshots := make([]Snapshot, len(e.Resources))
+	for i, resource := range e.Resources {
+		snapshots[i] = Snapshot{
+			ID:        fmt.Sprintf("export-%d", i),
+			Timestamp: e.ExportedAt,
+			Account:   e.ExportedBy,
+			Operation: "export",
+			Resource:  resource.Resource,
+			Networks:  resource.Networks,
+		}
+	}
+	return snapshots
+}
+
+// ImportItem is one network to add, from an export or a CSV line
+type ImportItem struct {
+	Type     ResourceType // Empty when the source doesn't say (CSV)
+	Project  string
+	Location string
+	Resource string
+	Name     string
+	CIDR     string
+	Source   string // Where the item came from, e.g. "line 4"
+}
+
+// key identifies an item in the progress file
+func (i ImportItem) key() string {
+	return strings.Join([]string{string(i.Type), i.Project, i.Location, i.Resource, i.CIDR}, "|")
+}
+
+// LoadImportItems reads the networks to import from an export file or a CSV of project,resource,name,cidr
+func LoadImportItems(path string) ([]ImportItem, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read import file: %v", err)
+	}
+
+	export, isExport, err := parseExport(data)
+	if err != nil {
+		return nil, err
+	}
+	if isExport {
+		var items []ImportItem
+		for i, resource := range export.Resources {
+			byValue, order := indexNetworks(resource.Networks)
+			for _, value := range order {
+				items = append(items, ImportItem{
+					Type:     resource.Resource.Type,
+					Project:  resource.Resource.Project,
+					Location: resource.Resource.Location,
+					Resource: resource.Resource.Name,
+					Name:     networkName(byValue[value]),
+					CIDR:     value,
+					Source:   fmt.Sprintf("resources[%d]", i),
+				})
+			}
+		}
+		return items, nil
+	}
+
+	return parseImportCSV(data)
+}
+
+// parseImportCSV reads project,resource,name,cidr rows, skipping an optional header and # comments
+func parseImportCSV(data []byte) ([]ImportItem, error) {
+	reader := csv.NewReader(bytes.NewReader(data))
+	reader.Comment = '#'
+	reader.FieldsPerRecord = 4
+	reader.TrimLeadingSpace = true
+
+	var items []ImportItem
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid CSV (want project,resource,name,cidr): %v", err)
+		}
+		line, _ := reader.FieldPos(0)
+
+		if len(items) == 0 && strings.EqualFold(strings.TrimSpace(record[0]), "project") {
+			continue
+		}
+
+		cidr, _ := normalizeIP(strings.TrimSpace(record[3]))
+		if _, _, err := net.ParseCIDR(cidr); err != nil {
+			return nil, fmt.Errorf("line %d: invalid CIDR %q", line, record[3])
+		}
+		items = append(items, ImportItem{
+			Project:  strings.TrimSpace(record[0]),
+			Resource: strings.TrimSpace(record[1]),
+			Name:     strings.TrimSpace(record[2]),
+			CIDR:     cidr,
+			Source:   fmt.Sprintf("line %d", line),
+		})
+	}
+	return items, nil
+}
+
+// Import action statuses
+const (
+	ImportAdd      = "add"
+	ImportPresent  = "present"  // Already there with the same name
+	ImportDone     = "done"     // Recorded in the progress file by an earlier run
+	ImportConflict = "conflict" // Can't be added as asked
+)
+
+// ImportAction is what will happen to one import item
+type ImportAction struct {
+	Item     ImportItem
+	Resource CloudResource
+	Status   string
+	Reason   string
+	Err      error // Set after applying if the add failed
+}
+
+// PlanImport matches import items to discovered resources and works out which need adding
+func PlanImport(items []ImportItem, resources []CloudResource, done map[string]bool, policy *Policy) []ImportAction {
+	actions := make([]ImportAction, len(items))
+	for i, item := range items {
+		action := ImportAction{Item: item}
+
+		var matches []CloudResource
+		for _, resource := range resources {
+			if resource.GetProject() != item.Project || resource.GetName() != item.Resource {
+				continue
+			}
+			if item.Type != "" && resource.GetType() != item.Type {
+				continue
+			}
+			if item.Location != "" && resource.GetRegion() != item.Location {
+				continue
+			}
+			matches = append(matches, resource)
+		}
+
+		switch {
+		case len(matches) == 0:
+			action.Status = ImportConflict
+			action.Reason = "resource not found"
+		case len(matches) > 1:
+			action.Status = ImportConflict
+			action.Reason = fmt.Sprintf("%d resources match; use an export file to disambiguate", len(matches))
+		case done[item.key()]:
+			action.Resource = matches[0]
+			action.Status = ImportDone
+		default:
+			action.Resource = matches[0]
+			action.Status, action.Reason = importStatus(matches[0], item)
+		}
+		actions[i] = action
+	}
+
+	// The same CIDR listed twice for one resource can only be added once
+	seen := make(map[string]int)
+	for i, action := range actions {
+		if action.Status != ImportAdd {
+			continue
+		}
+		key := resourceKey(action.Resource) + "|" + action.Item.CIDR
+		if first, ok := seen[key]; ok {
+			actions[i].Status = ImportConflict
+			actions[i].Reason = fmt.Sprintf("duplicate of %s", actions[first].Item.Source)
+			continue
+		}
+		seen[key] = i
+	}
+
+	// Check each resource's additions together, so limits such as max_entries count every
+	// network the import would add, not just the one being checked
+	planned := make(map[string][]AuthorizedNetwork)
+	for i, action := range actions {
+		if action.Status != ImportAdd {
+			continue
+		}
+		key := resourceKey(action.Resource)
+		existing := append(append([]AuthorizedNetwork{}, resourceNetworks(action.Resource)...), planned[key]...)
+		if err := policy.Check(action.Resource, action.Item.Name, action.Item.CIDR, existing); err != nil {
+			actions[i].Status = ImportConflict
+			actions[i].Reason = err.Error()
+			if n := len(planned[key]); n > 0 {
+				actions[i].Reason = fmt.Sprintf("%v (counting %d earlier additions in this import)", err, n)
+			}
+			continue
+		}
+		planned[key] = append(planned[key], AuthorizedNetwork{Name: action.Item.Name, Value: action.Item.CIDR})
+	}
+
+	return actions
+}
+
+// importStatus decides whether an item can be added to its resource; PlanImport checks policy afterwards
+func importStatus(resource CloudResource, item ImportItem) (string, string) {
+	for _, network := range resourceNetworks(resource) {
+		value, _ := normalizeIP(network.Value)
+		if value != item.CIDR {
+			continue
+		}
+		if networkName(network) == item.Name {
+			return ImportPresent, ""
+		}
+		return ImportConflict, fmt.Sprintf("already present as %q", networkName(network))
+	}
+
+	if !resource.CanAddNetwork() {
+		return ImportConflict, resource.GetNetworkRestrictions()
+	}
+	return ImportAdd, ""
+}
+
+// importProgress records completed items so an interrupted import can resume
+type importProgress struct {
+	path string
+	mu   sync.Mutex
+}
+
+// loadImportProgress reads the keys of items completed by earlier runs
+func loadImportProgress(path string) (*importProgress, map[string]bool, error) {
+	done := make(map[string]bool)
+	file, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return &importProgress{path: path}, done, nil
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read progress file: %v", err)
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		if line := strings.TrimSpace(scanner.Text()); line != "" {
+			done[line] = true
+		}
+	}
+	return &importProgress{path: path}, done, scanner.Err()
+}
+
+// record appends a completed item to the progress file
+func (p *importProgress) record(item ImportItem) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	file, err := os.OpenFile(p.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return fmt.Errorf("failed to write progress file: %v", err)
+	}
+	defer file.Close()
+	_, err = fmt.Fprintln(file, item.key())
+	return err
+}
+
+// RunImport adds every "add" action through AddNetworkToResource, one resource at a
+// time per resource and resources in parallel, recording each success in the progress file
+func (nm *NetworkManager) RunImport(actions []ImportAction, progress *importProgress) {
+	byResource := make(map[string][]int)
+	var resources []CloudResource
+	for i, action := range actions {
+		if action.Status != ImportAdd {
+			continue
+		}
+		key := resourceKey(action.Resource)
+		if _, ok := byResource[key]; !ok {
+			resources = append(resources, action.Resource)
+		}
+		byResource[key] = append(byResource[key], i)
+	}
+	sort.Slice(resources, func(i, j int) bool { return resourceKey(resources[i]) < resourceKey(resources[j]) })
+
+	nm.forEachResource(resources, func(resource CloudResource) error {
+		for _, i := range byResource[resourceKey(resource)] {
+			item := actions[i].Item
+			// Refresh between adds so policy limits count the entries just added
+			if current, err := nm.GetResourceDetails(resource); err == nil {
+				resource = current
+			}
+			if err := nm.AddNetworkToResource(resource, item.Name, item.CIDR); err != nil {
+				actions[i].Err = err
+				continue
+			}
+			if err := progress.record(item); err != nil {
+				actions[i].Err = err
+			}
+		}
+		return nil
+	})
+}
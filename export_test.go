@@ -0,0 +1,152 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestPlanImport(t *testing.T) {
+	orders := SQLInstance{
+		Name: "orders", Project: "prod-project", Region: "us-central1", PublicIPEnabled: true,
+		AuthorizedNetworks: []AuthorizedNetwork{{Name: "office", Value: "203.0.113.0/24"}},
+	}
+	ordersEU := SQLInstance{Name: "orders", Project: "prod-project", Region: "europe-west1", PublicIPEnabled: true}
+	ledger := SQLInstance{Name: "ledger", Project: "prod-project", Region: "us-central1"}
+	cluster := GKECluster{Name: "apps", Project: "prod-project", Location: "us-central1", PublicEndpoint: "34.1.2.3", MasterAuthorizedNetworksEnabled: true}
+
+	item := func(resource, name, cidr string) ImportItem {
+		return ImportItem{Project: "prod-project", Location: "us-central1", Resource: resource, Name: name, CIDR: cidr}
+	}
+
+	tests := []struct {
+		name      string
+		items     []ImportItem
+		resources []CloudResource
+		done      map[string]bool
+		policy    PolicyConfig
+		want      []string // Status of each item
+	}{
+		{
+			name:      "new network",
+			items:     []ImportItem{item("orders", "vpn", "198.51.100.0/24")},
+			resources: []CloudResource{orders},
+			want:      []string{ImportAdd},
+		},
+		{
+			name:      "already present",
+			items:     []ImportItem{item("orders", "office", "203.0.113.0/24")},
+			resources: []CloudResource{orders},
+			want:      []string{ImportPresent},
+		},
+		{
+			name:      "present under another name",
+			items:     []ImportItem{item("orders", "hq", "203.0.113.0/24")},
+			resources: []CloudResource{orders},
+			want:      []string{ImportConflict},
+		},
+		{
+			name:      "resource not found",
+			items:     []ImportItem{item("missing", "vpn", "198.51.100.0/24")},
+			resources: []CloudResource{orders},
+			want:      []string{ImportConflict},
+		},
+		{
+			name:      "ambiguous without a location",
+			items:     []ImportItem{{Project: "prod-project", Resource: "orders", Name: "vpn", CIDR: "198.51.100.0/24"}},
+			resources: []CloudResource{orders, ordersEU},
+			want:      []string{ImportConflict},
+		},
+		{
+			name:      "location disambiguates",
+			items:     []ImportItem{item("orders", "vpn", "198.51.100.0/24")},
+			resources: []CloudResource{orders, ordersEU},
+			want:      []string{ImportAdd},
+		},
+		{
+			name:      "done in an earlier run",
+			items:     []ImportItem{item("orders", "vpn", "198.51.100.0/24")},
+			resources: []CloudResource{orders},
+			done:      map[string]bool{item("orders", "vpn", "198.51.100.0/24").key(): true},
+			want:      []string{ImportDone},
+		},
+		{
+			name:      "resource doesn't accept networks",
+			items:     []ImportItem{item("ledger", "vpn", "198.51.100.0/24")},
+			resources: []CloudResource{ledger},
+			want:      []string{ImportConflict},
+		},
+		{
+			name:      "duplicate in the file",
+			items:     []ImportItem{item("orders", "vpn", "198.51.100.0/24"), item("orders", "vpn-2", "198.51.100.0/24")},
+			resources: []CloudResource{orders},
+			want:      []string{ImportAdd, ImportConflict},
+		},
+		{
+			name:      "policy violation",
+			items:     []ImportItem{item("orders", "anywhere", "0.0.0.0/0")},
+			resources: []CloudResource{orders},
+			policy:    PolicyConfig{PolicyRules: PolicyRules{MinPrefixLength: intPtr(24)}},
+			want:      []string{ImportConflict},
+		},
+		{
+			name: "entry limit counts earlier additions",
+			items: []ImportItem{
+				item("orders", "vpn", "198.51.100.0/24"),
+				item("orders", "home", "192.0.2.1/32"),
+				item("orders", "cafe", "192.0.2.2/32"),
+			},
+			resources: []CloudResource{orders},
+			policy:    PolicyConfig{PolicyRules: PolicyRules{MaxEntries: intPtr(2)}},
+			want:      []string{ImportAdd, ImportConflict, ImportConflict},
+		},
+		{
+			name: "entry limit is per resource",
+			items: []ImportItem{
+				item("orders", "vpn", "198.51.100.0/24"),
+				item("apps", "vpn", "198.51.100.0/24"),
+				item("apps", "home", "192.0.2.1/32"),
+			},
+			resources: []CloudResource{orders, cluster},
+			policy:    PolicyConfig{PolicyRules: PolicyRules{MaxEntries: intPtr(2)}},
+			want:      []string{ImportAdd, ImportAdd, ImportAdd},
+		},
+		{
+			name: "conflicts don't count towards the limit",
+			items: []ImportItem{
+				item("orders", "anywhere", "0.0.0.0/0"),
+				item("orders", "vpn", "198.51.100.0/24"),
+			},
+			resources: []CloudResource{orders},
+			policy:    PolicyConfig{PolicyRules: PolicyRules{MaxEntries: intPtr(2), MinPrefixLength: intPtr(8)}},
+			want:      []string{ImportConflict, ImportAdd},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			policy, err := NewPolicy(tt.policy)
+			if err != nil {
+				t.Fatalf("NewPolicy: %v", err)
+			}
+			done := tt.done
+			if done == nil {
+				done = map[string]bool{}
+			}
+
+			actions := PlanImport(tt.items, tt.resources, done, policy)
+			got := make([]string, len(actions))
+			for i, action := range actions {
+				got[i] = action.Status
+				if action.Status == ImportConflict && action.Reason == "" {
+					t.Errorf("item %d is a conflict with no reason", i)
+				}
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("statuses = %v, want %v", got, tt.want)
+				for _, action := range actions {
+					t.Logf("%s %s: %s %s", action.Item.Resource, action.Item.CIDR, action.Status, action.Reason)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,234 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Keys of resource query terms
+var queryKeys = map[string]string{
+	"type":     "resource type: sql, gke, aws or azure",
+	"name":     "name contains the value",
+	"project":  "project, AWS account or Azure subscription contains the value",
+	"region":   "region or location contains the value",
+	"state":    "lifecycle state, e.g. RUNNABLE",
+	"label":    "has a label: label:env or label:env=prod",
+	"public":   "has a public endpoint (true/false)",
+	"private":  "has no public endpoint (true/false)",
+	"stopped":  "stopped or suspended (true/false)",
+	"open":     "reachable from any IP: 0.0.0.0/0 or GKE authorized networks disabled (true/false)",
+	"replica":  "is a SQL read replica (true/false)",
+	"networks": "number of entries, e.g. networks>20 or networks:0",
+}
+
+// boolQueryKeys take true or false
+var boolQueryKeys = map[string]bool{"public": true, "private": true, "stopped": true, "open": true, "replica": true}
+
+// queryTermPattern matches "key:value", "key>N" and similar; a leading "-" negates the term
+var queryTermPattern = regexp.MustCompile(`^(-?)([A-Za-z]+)(>=|<=|:|>|<|=)(.*)$`)
+
+// ResourceQuery is a parsed resource filter: structured terms that must all match, plus
+// free text left for the fuzzy search
+type ResourceQuery struct {
+	Terms []QueryTerm
+	Text  string
+}
+
+// QueryTerm is one structured condition of a query, e.g. "networks>20" or "-type:gke"
+type QueryTerm struct {
+	Key    string
+	Op     string
+	Value  string
+	Negate bool
+}
+
+// String returns the term as it is written in a query
+func (t QueryTerm) String() string {
+	negate := ""
+	if t.Negate {
+		negate = "-"
+	}
+	return negate + t.Key + t.Op + t.Value
+}
+
+// ParseResourceQuery splits a query into structured terms and free text. Invalid terms are
+// reported in the error but the valid ones are still returned, so a query being typed keeps
+// filtering.
+func ParseResourceQuery(query string) (ResourceQuery, error) {
+	var parsed ResourceQuery
+	var text []string
+	var problems []string
+	for _, word := range strings.Fields(query) {
+		match := queryTermPattern.FindStringSubmatch(word)
+		if match == nil {
+			text = append(text, word)
+			continue
+		}
+		term := QueryTerm{Negate: match[1] == "-", Key: strings.ToLower(match[2]), Op: match[3], Value: match[4]}
+		if err := term.validate(); err != nil {
+			problems = append(problems, err.Error())
+			continue
+		}
+		parsed.Terms = append(parsed.Terms, term)
+	}
+	parsed.Text = strings.Join(text, " ")
+
+	if len(problems) > 0 {
+		return parsed, fmt.Errorf("%s", strings.Join(problems, "; "))
+	}
+	return parsed, nil
+}
+
+// validate checks that a term's key, operator and value go together
+func (t QueryTerm) validate() error {
+	if _, ok := queryKeys[t.Key]; !ok {
+		return fmt.Errorf("unknown filter %q", t.Key)
+	}
+	comparison := t.Op != ":" && t.Op != "="
+	switch {
+	case t.Key == "networks":
+		if _, err := strconv.Atoi(t.Value); err != nil {
+			return fmt.Errorf("%s needs a number", t)
+		}
+	case comparison:
+		return fmt.Errorf("%s: only networks can be compared with %s", t, t.Op)
+	case boolQueryKeys[t.Key]:
+		if _, err := parseQueryBool(t.Value); err != nil {
+			return fmt.Errorf("%s needs true or false", t)
+		}
+	case t.Value == "":
+		return fmt.Errorf("%s needs a value", t)
+	}
+	return nil
+}
+
+// parseQueryBool parses the value of a true/false term
+func parseQueryBool(value string) (bool, error) {
+	switch strings.ToLower(value) {
+	case "true", "yes", "y", "1":
+		return true, nil
+	case "false", "no", "n", "0":
+		return false, nil
+	}
+	return false, fmt.Errorf("invalid boolean %q", value)
+}
+
+// Matches reports whether a resource satisfies every structured term. Free text is not
+// considered; the caller matches it however suits (fuzzy in the TUI, substrings in the CLI).
+func (q ResourceQuery) Matches(resource CloudResource) bool {
+	for _, term := range q.Terms {
+		if term.matches(resource) == term.Negate {
+			return false
+		}
+	}
+	return true
+}
+
+// MatchesText reports whether every word of the free text appears in the resource's name,
+// project, region or type
+func (q ResourceQuery) MatchesText(resource CloudResource) bool {
+	haystack := strings.ToLower(resourceItem{resource: resource}.FilterValue())
+	for _, word := range strings.Fields(strings.ToLower(q.Text)) {
+		if !strings.Contains(haystack, word) {
+			return false
+		}
+	}
+	return true
+}
+
+// Filter returns the resources matching both the terms and the free text
+func (q ResourceQuery) Filter(resources []CloudResource) []CloudResource {
+	var matched []CloudResource
+	for _, resource := range resources {
+		if q.Matches(resource) && q.MatchesText(resource) {
+			matched = append(matched, resource)
+		}
+	}
+	return matched
+}
+
+// matches evaluates a term, ignoring its negation
+func (t QueryTerm) matches(resource CloudResource) bool {
+	contains := func(s string) bool {
+		return strings.Contains(strings.ToLower(s), strings.ToLower(t.Value))
+	}
+
+	switch t.Key {
+	case "type":
+		return strings.EqualFold(string(resource.GetType()), t.Value)
+	case "name":
+		return contains(resource.GetName())
+	case "project":
+		return contains(resource.GetProject())
+	case "region":
+		return contains(resource.GetRegion())
+	case "state":
+		return strings.EqualFold(resourceState(resource), t.Value)
+	case "label":
+		key, value, hasValue := strings.Cut(t.Value, "=")
+		actual, ok := resource.GetLabels()[key]
+		return ok && (!hasValue || actual == value)
+	case "networks":
+		want, _ := strconv.Atoi(t.Value)
+		count := len(resourceNetworks(resource))
+		switch t.Op {
+		case ">":
+			return count > want
+		case ">=":
+			return count >= want
+		case "<":
+			return count < want
+		case "<=":
+			return count <= want
+		default:
+			return count == want
+		}
+	}
+
+	want, _ := parseQueryBool(t.Value)
+	var actual bool
+	switch t.Key {
+	case "public":
+		actual = resource.HasPublicIP()
+	case "private":
+		actual = !resource.HasPublicIP()
+	case "stopped":
+		actual = resourceStopped(resource)
+	case "open":
+		actual = openToWorld(resource)
+	case "replica":
+		_, actual = replicationGroup(resource)
+	}
+	return actual == want
+}
+
+// resourceStopped reports whether a resource is stopped or suspended rather than serving
+func resourceStopped(resource CloudResource) bool {
+	if instance, ok := resource.(SQLInstance); ok && instance.ActivationPolicy == "NEVER" {
+		return true
+	}
+	switch strings.ToUpper(resourceState(resource)) {
+	case "STOPPED", "STOPPING", "SUSPENDED", "DISABLED":
+		return true
+	}
+	return false
+}
+
+// openToWorld reports whether any IP can reach a resource: its public endpoint allows
+// 0.0.0.0/0 (or ::/0), or it is a GKE control plane with authorized networks disabled
+func openToWorld(resource CloudResource) bool {
+	if !resource.HasPublicIP() {
+		return false
+	}
+	if cluster, ok := resource.(GKECluster); ok && !cluster.MasterAuthorizedNetworksEnabled {
+		return true
+	}
+	for _, network := range resourceNetworks(resource) {
+		if strings.HasSuffix(network.Value, "/0") {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,179 @@
+package main
+
+import (
+	"fmt"
+	"net"
+	"sort"
+	"strings"
+)
+
+// NetworkGroup is a named set of CIDRs, e.g. an office's egress ranges, added together
+type NetworkGroup struct {
+	Name        string   `json:"name"`
+	Description string   `json:"description,omitempty"`
+	CIDRs       []string `json:"cidrs"`
+}
+
+// NetworkGroups holds the configured groups; a nil *NetworkGroups has no groups
+type NetworkGroups struct {
+	groups []NetworkGroup
+	byCIDR map[string][]int // Normalised CIDR to indexes of the groups containing it
+}
+
+// NewNetworkGroups validates the configured groups, normalising their CIDRs
+func NewNetworkGroups(configs []NetworkGroup) (*NetworkGroups, error) {
+	g := &NetworkGroups{byCIDR: make(map[string][]int)}
+	names := make(map[string]bool)
+	for i, config := range configs {
+		name := strings.TrimSpace(config.Name)
+		if name == "" {
+			return nil, fmt.Errorf("groups[%d]: name is required", i)
+		}
+		if names[name] {
+			return nil, fmt.Errorf("group %q is defined twice", name)
+		}
+		names[name] = true
+		if len(config.CIDRs) == 0 {
+			return nil, fmt.Errorf("group %q has no CIDRs", name)
+		}
+
+		group := NetworkGroup{Name: name, Description: config.Description}
+		seen := make(map[string]bool)
+		for _, cidr := range config.CIDRs {
+			normalized, _ := normalizeIP(strings.TrimSpace(cidr))
+			if _, _, err := net.ParseCIDR(normalized); err != nil {
+				return nil, fmt.Errorf("group %q: invalid CIDR %q", name, cidr)
+			}
+			if seen[normalized] {
+				continue
+			}
+			seen[normalized] = true
+			group.CIDRs = append(group.CIDRs, normalized)
+			g.byCIDR[normalized] = append(g.byCIDR[normalized], len(g.groups))
+		}
+		g.groups = append(g.groups, group)
+	}
+	return g, nil
+}
+
+// All returns the groups in config order
+func (g *NetworkGroups) All() []NetworkGroup {
+	if g == nil {
+		return nil
+	}
+	return g.groups
+}
+
+// GroupsOf returns the names of the groups a CIDR belongs to
+func (g *NetworkGroups) GroupsOf(cidr string) []string {
+	if g == nil {
+		return nil
+	}
+	normalized, _ := normalizeIP(cidr)
+	var names []string
+	for _, i := range g.byCIDR[normalized] {
+		names = append(names, g.groups[i].Name)
+	}
+	return names
+}
+
+// memberName is the entry name used for a group's i-th CIDR, so every resource uses the same names.
+// Single-CIDR groups use the group name; larger groups number their members from 1.
+func (g NetworkGroup) memberName(i int) string {
+	if len(g.CIDRs) == 1 {
+		return g.Name
+	}
+	return fmt.Sprintf("%s-%d", g.Name, i+1)
+}
+
+// Networks returns the group's members as named networks
+func (g NetworkGroup) Networks() []AuthorizedNetwork {
+	networks := make([]AuthorizedNetwork, len(g.CIDRs))
+	for i, cidr := range g.CIDRs {
+		networks[i] = AuthorizedNetwork{Name: g.memberName(i), Value: cidr}
+	}
+	return networks
+}
+
+// Missing returns the group's members that aren't in a network list
+func (g NetworkGroup) Missing(networks []AuthorizedNetwork) []AuthorizedNetwork {
+	present := make(map[string]bool)
+	for _, network := range networks {
+		value, _ := normalizeIP(network.Value)
+		present[value] = true
+	}
+	var missing []AuthorizedNetwork
+	for _, member := range g.Networks() {
+		if !present[member.Value] {
+			missing = append(missing, member)
+		}
+	}
+	return missing
+}
+
+// GroupCoverage is how much of a group a resource's network list contains
+type GroupCoverage struct {
+	Group   NetworkGroup
+	Present int
+	Missing []AuthorizedNetwork
+}
+
+// Partial reports whether some but not all of the group's members are present
+func (c GroupCoverage) Partial() bool {
+	return len(c.Missing) > 0
+}
+
+// Coverage returns the groups with at least one member in a network list, sorted by name
+func (g *NetworkGroups) Coverage(networks []AuthorizedNetwork) []GroupCoverage {
+	var coverage []GroupCoverage
+	for _, group := range g.All() {
+		missing := group.Missing(networks)
+		if len(missing) == len(group.CIDRs) {
+			continue
+		}
+		coverage = append(coverage, GroupCoverage{
+			Group:   group,
+			Present: len(group.CIDRs) - len(missing),
+			Missing: missing,
+		})
+	}
+	sort.Slice(coverage, func(i, j int) bool { return coverage[i].Group.Name < coverage[j].Group.Name })
+	return coverage
+}
+
+// groupNetworksToAdd returns a resource's network list with a group's missing members appended,
+// or an error if they can't be added
+func groupNetworksToAdd(resource CloudResource, group NetworkGroup, policy *Policy) ([]AuthorizedNetwork, NetworkDiff, error) {
+	current := resourceNetworks(resource)
+	desired := append(append([]AuthorizedNetwork{}, current...), group.Missing(current)...)
+	diff := diffNetworks(current, desired)
+	return desired, diff, checkPlannedNetworks(resource, desired, diff, policy)
+}
+
+// AddGroupToResource adds the members of a group a resource doesn't already have, in a single change
+func (nm *NetworkManager) AddGroupToResource(resource CloudResource, group NetworkGroup) error {
+	// Work from the live list, since the whole list is replaced
+	resource, err := nm.GetResourceDetails(resource)
+	if err != nil {
+		return err
+	}
+
+	desired, diff, err := groupNetworksToAdd(resource, group, nm.policy)
+	if err != nil {
+		return err
+	}
+	if diff.Empty() {
+		return nil
+	}
+
+	return nm.audited("add_group", resource, fmt.Sprintf("%s, %d networks", group.Name, len(diff.Added)), func(CloudResource) (string, error) {
+		return nm.setResourceNetworks(resource, desired)
+	})
+}
+
+// AddGroupToResources adds a group to several resources in parallel
+func (nm *NetworkManager) AddGroupToResources(resources []CloudResource, group NetworkGroup) []ResourceResult {
+	return nm.forEachResource(resources, func(resource CloudResource) error {
+		return nm.AddGroupToResource(resource, group)
+	})
+}
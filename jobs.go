@@ -0,0 +1,353 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+	"unicode"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// Job states
+const (
+	JobQueued  = "queued"  // Waiting for an earlier job on the same resource
+	JobRunning = "running" // Sending the change, after waiting for a busy resource
+	JobDone    = "done"
+	JobFailed  = "failed"
+)
+
+// How many jobs the panel lists, and how long toasts stay up
+const (
+	jobPanelRows       = 4
+	toastDuration      = 5 * time.Second
+	errorToastDuration = 15 * time.Second
+)
+
+// Job is a confirmed change running in the background while the user keeps browsing
+type Job struct {
+	ID        int
+	Kind      string          // e.g. "Add Authorized Network"
+	Resources []CloudResource // Resources it changes, refreshed when it finishes
+	State     string
+	Message   string // Result, once finished
+	Started   time.Time
+	Finished  time.Time
+	action    jobAction
+}
+
+// jobAction sends a job's change with the job's NetworkManager and reports the outcome
+type jobAction func(nm *NetworkManager) tea.Msg
+
+// Active reports whether a job hasn't finished yet
+func (j Job) Active() bool {
+	return j.State == JobQueued || j.State == JobRunning
+}
+
+// Elapsed is how long a job has run, or ran for
+func (j Job) Elapsed(now time.Time) time.Duration {
+	switch {
+	case j.Started.IsZero():
+		return 0
+	case !j.Finished.IsZero():
+		return j.Finished.Sub(j.Started)
+	default:
+		return now.Sub(j.Started)
+	}
+}
+
+// Subject names the resource a job changes, e.g. "my-db (+2 replicas)"
+func (j Job) Subject() string {
+	switch len(j.Resources) {
+	case 0:
+		return "-"
+	case 1:
+		return j.Resources[0].GetName()
+	default:
+		return fmt.Sprintf("%s (+%d replicas)", j.Resources[0].GetName(), len(j.Resources)-1)
+	}
+}
+
+// toast is a short-lived notice that a job finished
+type toast struct {
+	message string
+	isError bool
+	expires time.Time
+}
+
+// jobFinishedMsg reports a job's outcome with fresh details of the resources it changed
+type jobFinishedMsg struct {
+	id        int
+	success   bool
+	message   string
+	result    tea.Msg
+	resources []CloudResource
+}
+
+// jobTickMsg updates elapsed times and expires toasts
+type jobTickMsg time.Time
+
+// jobKind turns a confirmation title such as "➕ Add Authorized Network" into a job kind
+func jobKind(title string) string {
+	kind := strings.TrimLeftFunc(title, func(r rune) bool { return !unicode.IsLetter(r) })
+	return strings.TrimSuffix(kind, "?")
+}
+
+// enqueueJob queues a confirmed change and starts it unless an earlier job holds its resources
+func (m *Model) enqueueJob(kind string, resources []CloudResource, action jobAction) tea.Cmd {
+	m.nextJobID++
+	m.jobs = append(m.jobs, Job{
+		ID:        m.nextJobID,
+		Kind:      kind,
+		Resources: resources,
+		State:     JobQueued,
+		action:    action,
+	})
+	m.showJobs = true
+	return tea.Batch(m.startJobs(), m.tickJobs())
+}
+
+// startJobs starts queued jobs in order. Jobs on the same resource run one at a time, as
+// each reads the resource's networks and writes them back.
+func (m *Model) startJobs() tea.Cmd {
+	held := map[string]bool{}
+	var cmds []tea.Cmd
+	for i := range m.jobs {
+		job := &m.jobs[i]
+		if !job.Active() {
+			continue
+		}
+		free := true
+		for _, resource := range job.Resources {
+			if held[resourceKey(resource)] {
+				free = false
+			}
+			held[resourceKey(resource)] = true
+		}
+		if job.State == JobQueued && free {
+			job.State = JobRunning
+			job.Started = time.Now()
+			cmds = append(cmds, runJob(*job))
+		}
+	}
+	return tea.Batch(cmds...)
+}
+
+// runJob waits for a job's resources to be ready, sends the change and fetches the resources again.
+// Like the forms, a job waits for a busy resource whether or not --wait was given.
+func runJob(job Job) tea.Cmd {
+	return func() tea.Msg {
+		nm, err := NewNetworkManager(context.Background())
+		if err != nil {
+			return jobFinishedMsg{id: job.ID, message: err.Error()}
+		}
+		// An earlier job may have left the resource busy, e.g. a GKE cluster RECONCILING
+		nm.waitForReady = true
+		for _, resource := range job.Resources {
+			current, err := nm.GetResourceDetails(resource)
+			if err != nil {
+				return jobFinishedMsg{id: job.ID, message: err.Error()}
+			}
+			if _, err := nm.waitUntilPatchable(current, readyWaitTimeout); err != nil {
+				return jobFinishedMsg{id: job.ID, message: err.Error()}
+			}
+		}
+
+		result := job.action(nm)
+		success, message := jobResult(result)
+
+		// Refresh even after a failure: a change to several replicas may have partly gone through
+		var refreshed []CloudResource
+		for _, resource := range job.Resources {
+			if current, err := nm.GetResourceDetails(resource); err == nil {
+				refreshed = append(refreshed, current)
+			}
+		}
+		return jobFinishedMsg{id: job.ID, success: success, message: message, result: result, resources: refreshed}
+	}
+}
+
+// jobResult reads the outcome of a change command
+func jobResult(msg tea.Msg) (bool, string) {
+	switch msg := msg.(type) {
+	case networkAddedMsg:
+		return msg.success, msg.message
+	case networkRemovedMsg:
+		return msg.success, msg.message
+	case pscUpdatedMsg:
+		return msg.success, msg.message
+	case settingsUpdatedMsg:
+		return msg.success, msg.message
+	case requestDecidedMsg:
+		return msg.success, msg.message
+	case errorMsg:
+		return false, msg.err.Error()
+	}
+	return false, fmt.Sprintf("unexpected result %T", msg)
+}
+
+// finishJob records a job's outcome, raises a toast, swaps in the refreshed resources and
+// starts any job that was queued behind it
+func (m Model) finishJob(msg jobFinishedMsg) (Model, tea.Cmd) {
+	var job *Job
+	for i := range m.jobs {
+		if m.jobs[i].ID == msg.id {
+			job = &m.jobs[i]
+		}
+	}
+	if job == nil {
+		return m, nil
+	}
+	job.Finished = time.Now()
+	job.Message = msg.message
+	job.State = JobDone
+	if !msg.success {
+		job.State = JobFailed
+	}
+	m.addToast(fmt.Sprintf("%s on %s: %s", job.Kind, job.Subject(), msg.message), !msg.success)
+
+	cmds := []tea.Cmd{m.startJobs()}
+	for _, resource := range msg.resources {
+		cmds = append(cmds, m.replaceResource(resource))
+		if m.selectedResource != nil && resourceKey(resource) == resourceKey(m.selectedResource) {
+			m.selectedResource = resource
+			m.table.clamp(len(m.tableRows()), m.networkTableHeight())
+			cmds = append(cmds, loadAttribution(resource))
+		}
+	}
+	if m.access != nil {
+		m.access = CheckAccess(m.resources, m.accessIP, m.accessNames)
+		m.accessCursor, m.accessOffset = clampWindow(m.accessCursor, m.accessOffset, len(m.access), m.accessTableHeight())
+	}
+	if _, ok := msg.result.(requestDecidedMsg); ok {
+		cmds = append(cmds, loadRequests)
+	}
+	m.jobs = trimJobs(m.jobs)
+	m.resizeResourceList()
+	return m, tea.Batch(cmds...)
+}
+
+// trimJobs drops the oldest finished jobs the panel has no room for; active jobs always stay
+func trimJobs(jobs []Job) []Job {
+	excess := len(jobs) - jobPanelRows
+	kept := make([]Job, 0, len(jobs))
+	for _, job := range jobs {
+		if excess > 0 && !job.Active() {
+			excess--
+			continue
+		}
+		kept = append(kept, job)
+	}
+	return kept
+}
+
+// addToast raises a notice that disappears on its own
+func (m *Model) addToast(message string, isError bool) {
+	duration := toastDuration
+	if isError {
+		duration = errorToastDuration
+	}
+	m.toasts = append(m.toasts, toast{message: message, isError: isError, expires: time.Now().Add(duration)})
+}
+
+// activeJobs counts the jobs that haven't finished
+func (m Model) activeJobs() int {
+	count := 0
+	for _, job := range m.jobs {
+		if job.Active() {
+			count++
+		}
+	}
+	return count
+}
+
+// tickJobs starts the once-a-second tick that runs while jobs are active or toasts are up
+func (m *Model) tickJobs() tea.Cmd {
+	if m.jobsTicking {
+		return nil
+	}
+	m.jobsTicking = true
+	return tea.Tick(time.Second, func(t time.Time) tea.Msg {
+		return jobTickMsg(t)
+	})
+}
+
+// expireToasts drops toasts whose time is up and keeps ticking while anything is left to update
+func (m Model) expireToasts(now time.Time) (Model, tea.Cmd) {
+	var kept []toast
+	for _, t := range m.toasts {
+		if now.Before(t.expires) {
+			kept = append(kept, t)
+		}
+	}
+	m.toasts = kept
+	m.jobsTicking = false
+	m.resizeResourceList()
+	if len(m.toasts) > 0 || m.activeJobs() > 0 {
+		return m, m.tickJobs()
+	}
+	return m, nil
+}
+
+// renderJobs renders the job panel and any toasts, or "" when there is nothing to show
+func (m Model) renderJobs() string {
+	var sections []string
+	if m.showJobs && len(m.jobs) > 0 {
+		sections = append(sections, RenderJobPanel(m.jobs, time.Now(), m.width-4))
+	}
+	for _, t := range m.toasts {
+		if t.isError {
+			sections = append(sections, ErrorStyle.Render("✗ "+truncate(oneLine(t.message), m.width-8)))
+		} else {
+			sections = append(sections, SuccessStyle.Render("✓ "+truncate(oneLine(t.message), m.width-8)))
+		}
+	}
+	return lipgloss.JoinVertical(lipgloss.Left, sections...)
+}
+
+// jobsHeight is how many lines the job panel and toasts take up
+func (m Model) jobsHeight() int {
+	if jobs := m.renderJobs(); jobs != "" {
+		return lipgloss.Height(jobs) + 1
+	}
+	return 0
+}
+
+// bodyHeight is the terminal height left for a view once the job panel and toasts are drawn
+func (m Model) bodyHeight() int {
+	return m.height - m.jobsHeight()
+}
+
+// resizeResourceList fits the resource list into the height the job panel leaves
+func (m *Model) resizeResourceList() {
+	if m.height == 0 {
+		return
+	}
+	// Leave room for the tab bar and the grouping/sort line
+	m.resourceList.SetHeight(m.bodyHeight() - 13)
+}
+
+// jobsHint is the footer hint for the job panel, once there are jobs to show
+func (m Model) jobsHint() string {
+	if len(m.jobs) == 0 {
+		return ""
+	}
+	return hint("Jobs", m.keys.Jobs)
+}
+
+// oneLine joins a multi-line result, such as the per-replica summary of a change, into one line
+func oneLine(text string) string {
+	lines := strings.Split(strings.TrimSpace(text), "\n")
+	rest := make([]string, 0, len(lines)-1)
+	for _, line := range lines[1:] {
+		if line = strings.TrimSpace(line); line != "" {
+			rest = append(rest, line)
+		}
+	}
+	if len(rest) == 0 {
+		return lines[0]
+	}
+	return lines[0] + " " + strings.Join(rest, ", ")
+}
@@ -0,0 +1,471 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/list"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// KeyConfig is the "keys" section of the config file: a preset, then bindings replacing
+// the preset's keys for single actions
+type KeyConfig struct {
+	Preset   string              `json:"preset,omitempty"`   // default, vim or emacs
+	Bindings map[string][]string `json:"bindings,omitempty"` // Action name to keys; an empty list unbinds
+}
+
+// KeyMap holds every key binding of the TUI. Help text is generated from the bindings, so
+// the help screen, --help and the footers always show the keys in use.
+type KeyMap struct {
+	// Navigation
+	Up       key.Binding
+	Down     key.Binding
+	PageUp   key.Binding
+	PageDown key.Binding
+	Home     key.Binding
+	End      key.Binding
+	Select   key.Binding
+	Back     key.Binding
+	NextTab  key.Binding
+	PrevTab  key.Binding
+	Filter   key.Binding
+	Help     key.Binding
+	Quit     key.Binding
+
+	// Resource list
+	GroupBy key.Binding
+	SortBy  key.Binding
+	Refresh key.Binding
+
+	// Network view
+	SortTable       key.Binding
+	Copy            key.Binding
+	Info            key.Binding
+	Edit            key.Binding
+	Details         key.Binding
+	Add             key.Binding
+	Remove          key.Binding
+	ToggleNetworks  key.Binding
+	ToggleGoogleIPs key.Binding
+	Undo            key.Binding
+	RequestAccess   key.Binding
+	Console         key.Binding
+
+	// Views reachable from the resource list and the network view
+	History  key.Binding
+	Requests key.Binding
+	SearchIP key.Binding
+	MyAccess key.Binding
+	Jobs     key.Binding
+
+	// Forms and dialogs
+	Replicas     key.Binding
+	NetworkGroup key.Binding
+	Confirm      key.Binding
+	Cancel       key.Binding
+	Reject       key.Binding
+	FixAccess    key.Binding
+}
+
+// keyBinding names a binding of a KeyMap for the config file and places it in the help
+type keyBinding struct {
+	name    string // Config name
+	section string
+	binding *key.Binding
+	notes   []string // Extra help lines
+}
+
+// bindings lists a KeyMap's bindings in help order
+func (k *KeyMap) bindings() []keyBinding {
+	return []keyBinding{
+		{"up", "NAVIGATION", &k.Up, nil},
+		{"down", "NAVIGATION", &k.Down, nil},
+		{"page_up", "NAVIGATION", &k.PageUp, nil},
+		{"page_down", "NAVIGATION", &k.PageDown, nil},
+		{"home", "NAVIGATION", &k.Home, nil},
+		{"end", "NAVIGATION", &k.End, nil},
+		{"select", "NAVIGATION", &k.Select, nil},
+		{"back", "NAVIGATION", &k.Back, nil},
+		{"next_tab", "NAVIGATION", &k.NextTab, nil},
+		{"prev_tab", "NAVIGATION", &k.PrevTab, nil},
+		{"filter", "NAVIGATION", &k.Filter, []string{
+			"Resource searches take terms: type:sql public:true",
+			"private:true stopped:true open:true replica:true",
+			"networks>20 state:RUNNABLE label:env=prod -type:gke",
+		}},
+		{"help", "NAVIGATION", &k.Help, nil},
+		{"quit", "NAVIGATION", &k.Quit, nil},
+
+		{"group_by", "RESOURCE LIST", &k.GroupBy, nil},
+		{"sort_by", "RESOURCE LIST", &k.SortBy, []string{
+			"States are green when ready, yellow while busy and",
+			"red when stopped; forms wait for busy resources",
+		}},
+		{"refresh", "RESOURCE LIST", &k.Refresh, nil},
+
+		{"sort_table", "NETWORK VIEW", &k.SortTable, nil},
+		{"copy", "NETWORK VIEW", &k.Copy, nil},
+		{"info", "NETWORK VIEW", &k.Info, nil},
+		{"edit", "NETWORK VIEW", &k.Edit, nil},
+		{"details", "NETWORK VIEW", &k.Details, nil},
+		{"add", "NETWORK VIEW", &k.Add, nil},
+		{"remove", "NETWORK VIEW", &k.Remove, nil},
+		{"toggle_networks", "NETWORK VIEW", &k.ToggleNetworks, nil},
+		{"toggle_google_ips", "NETWORK VIEW", &k.ToggleGoogleIPs, nil},
+		{"undo", "NETWORK VIEW", &k.Undo, nil},
+		{"request_access", "NETWORK VIEW", &k.RequestAccess, nil},
+		{"console", "NETWORK VIEW", &k.Console, nil},
+
+		{"history", "VIEWS", &k.History, nil},
+		{"requests", "VIEWS", &k.Requests, nil},
+		{"search_ip", "VIEWS", &k.SearchIP, nil},
+		{"my_access", "VIEWS", &k.MyAccess, nil},
+		{"jobs", "VIEWS", &k.Jobs, nil},
+
+		{"replicas", "FORMS AND DIALOGS", &k.Replicas, nil},
+		{"network_group", "FORMS AND DIALOGS", &k.NetworkGroup, nil},
+		{"confirm", "FORMS AND DIALOGS", &k.Confirm, nil},
+		{"cancel", "FORMS AND DIALOGS", &k.Cancel, nil},
+		{"reject", "FORMS AND DIALOGS", &k.Reject, nil},
+		{"fix_access", "FORMS AND DIALOGS", &k.FixAccess, nil},
+	}
+}
+
+// newKeyBinding returns a binding whose help shows its keys
+func newKeyBinding(description string, keys ...string) key.Binding {
+	return key.NewBinding(key.WithKeys(keys...), key.WithHelp(keyLabel(keys), description))
+}
+
+// DefaultKeyMap returns the default bindings
+func DefaultKeyMap() KeyMap {
+	return KeyMap{
+		Up:       newKeyBinding("Move up", "up", "k"),
+		Down:     newKeyBinding("Move down", "down", "j"),
+		PageUp:   newKeyBinding("Page up", "pgup"),
+		PageDown: newKeyBinding("Page down", "pgdown"),
+		Home:     newKeyBinding("Jump to the first row", "home"),
+		End:      newKeyBinding("Jump to the last row", "end"),
+		Select:   newKeyBinding("Select resource, open an entry's actions, or submit a form", "enter"),
+		Back:     newKeyBinding("Go back / cancel", "esc"),
+		NextTab:  newKeyBinding("Next resource type tab, form field, or SQL networks/PSC tab", "tab"),
+		PrevTab:  newKeyBinding("Previous resource type tab", "shift+tab"),
+		Filter:   newKeyBinding("Search resources, or filter the network table", "/"),
+		Help:     newKeyBinding("Show/hide this help", "?"),
+		Quit:     newKeyBinding("Quit", "q", "ctrl+c"),
+
+		GroupBy: newKeyBinding("Group by project or region (select collapses a group)", "p"),
+		SortBy:  newKeyBinding("Sort by type, name, project, network count or state", "o"),
+		Refresh: newKeyBinding("Refresh resources, or re-detect your IP (my access)", "r"),
+
+		SortTable:       newKeyBinding("Sort the network table by API order, name or CIDR", "s"),
+		Copy:            newKeyBinding("Copy the highlighted entry's CIDR", "Y"),
+		Info:            newKeyBinding("Show the highlighted entry's details", "i"),
+		Edit:            newKeyBinding("Edit the highlighted entry (in its actions)", "E"),
+		Details:         newKeyBinding("Show/hide resource details (beside the table when wide)", "I"),
+		Add:             newKeyBinding("Add authorized network or PSC consumer project", "a"),
+		Remove:          newKeyBinding("Remove authorized network or PSC consumer project", "d"),
+		ToggleNetworks:  newKeyBinding("Enable/disable GKE master authorized networks", "e"),
+		ToggleGoogleIPs: newKeyBinding("Allow/block Google Cloud public IPs (GKE)", "g"),
+		Undo:            newKeyBinding("Undo the last change to the selected resource", "u"),
+		RequestAccess:   newKeyBinding("Request access to the selected resource from an approver", "R"),
+		Console:         newKeyBinding("Open the resource in the cloud console", "c"),
+
+		History:  newKeyBinding("Show change history (all, or the selected resource)", "h"),
+		Requests: newKeyBinding("Review pending access requests", "A"),
+		SearchIP: newKeyBinding("Search every resource for entries matching an IP or CIDR", "S"),
+		MyAccess: newKeyBinding("Where do I have access: what your public IP reaches", "M"),
+		Jobs:     newKeyBinding("Show/hide the panel of changes running in the background", "J"),
+
+		Replicas:     newKeyBinding("Apply add/remove to the SQL primary and all replicas", "ctrl+r"),
+		NetworkGroup: newKeyBinding("Pick a configured network group in the add form", "ctrl+g"),
+		Confirm:      newKeyBinding("Send a previewed change", "y"),
+		Cancel:       newKeyBinding("Cancel a previewed change", "n"),
+		Reject:       newKeyBinding("Reject the highlighted access request", "x"),
+		FixAccess:    newKeyBinding("Add your IP where it is missing (my access)", "f"),
+	}
+}
+
+// keyPresets change some of the default bindings
+var keyPresets = map[string]map[string][]string{
+	"default": {},
+	"vim": {
+		"page_up":   {"ctrl+b", "ctrl+u", "pgup"},
+		"page_down": {"ctrl+f", "ctrl+d", "pgdown"},
+		"home":      {"home"},
+		"end":       {"G", "end"},
+	},
+	"emacs": {
+		"up":            {"ctrl+p", "up"},
+		"down":          {"ctrl+n", "down"},
+		"page_up":       {"alt+v", "pgup"},
+		"page_down":     {"ctrl+v", "pgdown"},
+		"home":          {"alt+<", "home"},
+		"end":           {"alt+>", "end"},
+		"back":          {"esc", "ctrl+g"},
+		"filter":        {"/", "ctrl+s"},
+		"network_group": {"alt+g"},
+	},
+}
+
+// keyMap is the active key map, set from the config file by configureKeys
+var keyMap = DefaultKeyMap()
+
+// configureKeys applies the "keys" section of the config file. On error the default
+// bindings are kept, so a typo never stops piam-anc starting. Keys bound to more than
+// one action are applied but reported, as only one of the actions will get them.
+func configureKeys(config KeyConfig) error {
+	keys, err := NewKeyMap(config)
+	if err != nil {
+		return err
+	}
+	keyMap = keys
+	if conflicts := keys.conflicts(); len(conflicts) > 0 {
+		return fmt.Errorf("keys bound to more than one action: %s", strings.Join(conflicts, "; "))
+	}
+	return nil
+}
+
+// NewKeyMap builds a key map from a preset and per-action overrides
+func NewKeyMap(config KeyConfig) (KeyMap, error) {
+	keys := DefaultKeyMap()
+
+	preset := strings.ToLower(config.Preset)
+	if preset == "" {
+		preset = "default"
+	}
+	overrides, ok := keyPresets[preset]
+	if !ok {
+		return keys, fmt.Errorf("unknown key preset %q (choose from default, vim, emacs)", config.Preset)
+	}
+	if err := keys.rebind(overrides); err != nil {
+		return keys, err
+	}
+	if err := keys.rebind(config.Bindings); err != nil {
+		return keys, err
+	}
+	return keys, nil
+}
+
+// rebind replaces the keys of the named actions
+func (k *KeyMap) rebind(overrides map[string][]string) error {
+	byName := map[string]*key.Binding{}
+	for _, b := range k.bindings() {
+		byName[b.name] = b.binding
+	}
+
+	var unknown []string
+	for name, keys := range overrides {
+		binding, ok := byName[name]
+		if !ok {
+			unknown = append(unknown, name)
+			continue
+		}
+		*binding = newKeyBinding(binding.Help().Desc, keys...)
+		binding.SetEnabled(len(keys) > 0)
+	}
+	if len(unknown) > 0 {
+		sort.Strings(unknown)
+		return fmt.Errorf("unknown key actions: %s", strings.Join(unknown, ", "))
+	}
+	return nil
+}
+
+// conflicts lists keys bound to more than one action, e.g. "y: copy, confirm", in help order
+func (k KeyMap) conflicts() []string {
+	var order []string
+	actions := map[string][]string{}
+	for _, b := range k.bindings() {
+		if !b.binding.Enabled() {
+			continue
+		}
+		for _, bound := range b.binding.Keys() {
+			if len(actions[bound]) == 0 {
+				order = append(order, bound)
+			}
+			actions[bound] = append(actions[bound], b.name)
+		}
+	}
+
+	var conflicts []string
+	for _, bound := range order {
+		if len(actions[bound]) > 1 {
+			conflicts = append(conflicts, fmt.Sprintf("%s: %s", bound, strings.Join(actions[bound], ", ")))
+		}
+	}
+	return conflicts
+}
+
+// binding returns the binding of a named action
+func (k KeyMap) binding(name string) key.Binding {
+	for _, b := range k.bindings() {
+		if b.name == name {
+			return *b.binding
+		}
+	}
+	return key.Binding{}
+}
+
+// keyNames are how keys are written in help
+var keyNames = map[string]string{
+	"up":        "↑",
+	"down":      "↓",
+	"left":      "←",
+	"right":     "→",
+	"enter":     "Enter",
+	"esc":       "Esc",
+	"tab":       "Tab",
+	"shift+tab": "Shift+Tab",
+	"pgup":      "PgUp",
+	"pgdown":    "PgDn",
+	"home":      "Home",
+	"end":       "End",
+	" ":         "Space",
+	"backspace": "Backspace",
+}
+
+// keyLabel writes keys for help, e.g. "↑/k" or "Ctrl+R"
+func keyLabel(keys []string) string {
+	labels := make([]string, len(keys))
+	for i, k := range keys {
+		labels[i] = keyName(k)
+	}
+	return strings.Join(labels, "/")
+}
+
+// keyName writes one key for help
+func keyName(k string) string {
+	if name, ok := keyNames[k]; ok {
+		return name
+	}
+	for _, modifier := range []string{"ctrl+", "alt+"} {
+		if rest, ok := strings.CutPrefix(k, modifier); ok {
+			if len(rest) == 1 {
+				rest = strings.ToUpper(rest)
+			}
+			return strings.ToUpper(modifier[:1]) + modifier[1:len(modifier)-1] + "+" + keyName(rest)
+		}
+	}
+	return k
+}
+
+// firstKey writes a binding's first key for hints and messages, or "" when it is unbound
+func firstKey(b key.Binding) string {
+	if !b.Enabled() || len(b.Keys()) == 0 {
+		return ""
+	}
+	return keyName(b.Keys()[0])
+}
+
+// hint writes a footer hint such as "↑/↓ Navigate", showing the first key of each binding
+func hint(label string, bindings ...key.Binding) string {
+	var keys []string
+	for _, b := range bindings {
+		if k := firstKey(b); k != "" {
+			keys = append(keys, k)
+		}
+	}
+	if len(keys) == 0 {
+		return ""
+	}
+	return strings.Join(keys, "/") + " " + label
+}
+
+// hints writes several footer hints, skipping unbound ones
+func hints(items ...string) []string {
+	var kept []string
+	for _, item := range items {
+		if item != "" {
+			kept = append(kept, item)
+		}
+	}
+	return kept
+}
+
+// typedKey reports whether a key is text when an input has focus rather than a command
+func typedKey(msg tea.KeyMsg) bool {
+	return (msg.Type == tea.KeyRunes && !msg.Alt) || msg.Type == tea.KeySpace
+}
+
+// commandKey reports whether a key matches a binding and, in an input, isn't text
+func commandKey(msg tea.KeyMsg, typing bool, bindings ...key.Binding) bool {
+	return key.Matches(msg, bindings...) && !(typing && typedKey(msg))
+}
+
+// applyListKeys gives the resource list the key map's navigation keys. Quitting is left
+// to the key map so that it can be rebound.
+func (k KeyMap) applyListKeys(l *list.Model) {
+	withKeys := func(b key.Binding, extra ...string) key.Binding {
+		keys := append(append([]string{}, b.Keys()...), extra...)
+		binding := key.NewBinding(key.WithKeys(keys...), key.WithHelp(keyLabel(keys), strings.ToLower(b.Help().Desc)))
+		binding.SetEnabled(b.Enabled())
+		return binding
+	}
+	l.KeyMap.CursorUp = withKeys(k.Up)
+	l.KeyMap.CursorDown = withKeys(k.Down)
+	l.KeyMap.PrevPage = withKeys(k.PageUp, "left")
+	l.KeyMap.NextPage = withKeys(k.PageDown, "right")
+	l.KeyMap.GoToStart = withKeys(k.Home)
+	l.KeyMap.GoToEnd = withKeys(k.End)
+	l.KeyMap.Filter = withKeys(k.Filter)
+	l.KeyMap.ClearFilter = withKeys(k.Back)
+	l.KeyMap.CancelWhileFiltering = withKeys(k.Back)
+	l.DisableQuitKeybindings()
+}
+
+// keyHelp renders the key map as help text: a heading per section followed by each
+// binding's keys and description, wrapped to width
+func (k KeyMap) keyHelp(headingSuffix string, width int) string {
+	bindings := k.bindings()
+	keyWidth := 14
+	for _, b := range bindings {
+		if w := len([]rune(b.binding.Help().Key)) + 1; b.binding.Enabled() && w > keyWidth {
+			keyWidth = w
+		}
+	}
+
+	var lines []string
+	section := ""
+	for _, b := range bindings {
+		if !b.binding.Enabled() {
+			continue
+		}
+		if b.section != section {
+			if section != "" {
+				lines = append(lines, "")
+			}
+			section = b.section
+			lines = append(lines, section+headingSuffix)
+		}
+		indent := strings.Repeat(" ", keyWidth+3)
+		description := wrapWords(b.binding.Help().Desc, width-keyWidth-3)
+		label := fmt.Sprintf("  %-*s ", keyWidth, b.binding.Help().Key)
+		lines = append(lines, label+description[0])
+		for _, line := range description[1:] {
+			lines = append(lines, indent+line)
+		}
+		for _, note := range b.notes {
+			lines = append(lines, indent+note)
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// wrapWords splits text into lines of at most width characters, breaking between words
+func wrapWords(text string, width int) []string {
+	var lines []string
+	line := ""
+	for _, word := range strings.Fields(text) {
+		if line != "" && len([]rune(line))+1+len([]rune(word)) > width {
+			lines = append(lines, line)
+			line = word
+		} else if line == "" {
+			line = word
+		} else {
+			line += " " + word
+		}
+	}
+	return append(lines, line)
+}
@@ -0,0 +1,120 @@
+package main
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestNewKeyMap(t *testing.T) {
+	tests := []struct {
+		name        string
+		config      KeyConfig
+		action      string
+		wantKeys    []string
+		wantEnabled bool
+	}{
+		{name: "default", action: "up", wantKeys: []string{"up", "k"}, wantEnabled: true},
+		{name: "preset is case-insensitive", config: KeyConfig{Preset: "VIM"}, action: "page_up", wantKeys: []string{"ctrl+b", "ctrl+u", "pgup"}, wantEnabled: true},
+		{name: "emacs preset", config: KeyConfig{Preset: "emacs"}, action: "up", wantKeys: []string{"ctrl+p", "up"}, wantEnabled: true},
+		{name: "preset leaves other actions", config: KeyConfig{Preset: "emacs"}, action: "quit", wantKeys: []string{"q", "ctrl+c"}, wantEnabled: true},
+		{
+			name:        "binding replaces default",
+			config:      KeyConfig{Bindings: map[string][]string{"quit": {"ctrl+q"}}},
+			action:      "quit",
+			wantKeys:    []string{"ctrl+q"},
+			wantEnabled: true,
+		},
+		{
+			name:        "binding wins over preset",
+			config:      KeyConfig{Preset: "emacs", Bindings: map[string][]string{"up": {"alt+p"}}},
+			action:      "up",
+			wantKeys:    []string{"alt+p"},
+			wantEnabled: true,
+		},
+		{
+			name:     "empty list unbinds",
+			config:   KeyConfig{Bindings: map[string][]string{"quit": {}}},
+			action:   "quit",
+			wantKeys: []string{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			keys, err := NewKeyMap(tt.config)
+			if err != nil {
+				t.Fatalf("NewKeyMap: %v", err)
+			}
+			binding := keys.binding(tt.action)
+			got := binding.Keys()
+			if got == nil {
+				got = []string{}
+			}
+			if !reflect.DeepEqual(got, tt.wantKeys) {
+				t.Errorf("%s keys = %q, want %q", tt.action, got, tt.wantKeys)
+			}
+			if binding.Enabled() != tt.wantEnabled {
+				t.Errorf("%s enabled = %v, want %v", tt.action, binding.Enabled(), tt.wantEnabled)
+			}
+			if binding.Help().Desc == "" {
+				t.Errorf("%s lost its help text", tt.action)
+			}
+		})
+	}
+}
+
+func TestNewKeyMapErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  KeyConfig
+		wantErr string
+	}{
+		{name: "unknown preset", config: KeyConfig{Preset: "nano"}, wantErr: `unknown key preset "nano"`},
+		{
+			name:    "unknown actions are listed in order",
+			config:  KeyConfig{Bindings: map[string][]string{"zoom": {"z"}, "quit": {"ctrl+q"}, "explode": {"x"}}},
+			wantErr: "unknown key actions: explode, zoom",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewKeyMap(tt.config)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("NewKeyMap error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestKeyMapConflicts(t *testing.T) {
+	tests := []struct {
+		name   string
+		config KeyConfig
+		want   []string
+	}{
+		{name: "default"},
+		{name: "vim preset", config: KeyConfig{Preset: "vim"}},
+		{name: "emacs preset", config: KeyConfig{Preset: "emacs"}},
+		{
+			name:   "binding takes another action's key",
+			config: KeyConfig{Bindings: map[string][]string{"edit": {"e"}, "add": {"a", "q"}}},
+			want:   []string{"q: quit, add", "e: edit, toggle_networks"},
+		},
+		{
+			name:   "unbound actions don't conflict",
+			config: KeyConfig{Bindings: map[string][]string{"edit": {"e"}, "toggle_networks": {}}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			keys, err := NewKeyMap(tt.config)
+			if err != nil {
+				t.Fatalf("NewKeyMap: %v", err)
+			}
+			if got := keys.conflicts(); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("conflicts() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
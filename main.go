@@ -1,6 +1,8 @@
 package main
 
 import (
+	"errors"
+	"flag"
 	"fmt"
 	"log"
 	"os"
@@ -9,17 +11,68 @@ import (
 )
 
 func main() {
+	// Global flags come before the subcommand
+	args := os.Args[1:]
+	for len(args) > 0 && (args[0] == "--dry-run" || args[0] == "--wait") {
+		switch args[0] {
+		case "--dry-run":
+			dryRunMode = true
+		case "--wait":
+			waitForReadyMode = true
+		}
+		args = args[1:]
+	}
+
+	// Config errors are reported where the rest of the config is used; the theme and keys fall back to the defaults
+	config, err := LoadConfig()
+	if err != nil {
+		config = &Config{}
+	}
+	if err := configureTheme(config.Theme); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+	}
+	if err := configureKeys(config.Keys); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+	}
+
 	// Parse command line arguments
-	if len(os.Args) > 1 {
-		switch os.Args[1] {
+	if len(args) > 0 {
+		switch args[0] {
 		case "-h", "--help", "help":
 			printHelp()
 			return
 		case "-v", "--version", "version":
 			printVersion()
 			return
+		case "history":
+			exitOnError(runHistory(args[1:]))
+			return
+		case "restore":
+			exitOnError(runRestore(args[1:]))
+			return
+		case "plan":
+			exitOnError(runPlan(args[1:]))
+			return
+		case "apply":
+			exitOnError(runApply(args[1:]))
+			return
+		case "check":
+			exitOnError(runCheck(args[1:]))
+			return
+		case "list":
+			exitOnError(runList(args[1:]))
+			return
+		case "search":
+			exitOnError(runSearch(args[1:]))
+			return
+		case "export":
+			exitOnError(runExport(args[1:]))
+			return
+		case "import":
+			exitOnError(runImport(args[1:]))
+			return
 		default:
-			fmt.Printf("Unknown argument: %s\n", os.Args[1])
+			fmt.Printf("Unknown argument: %s\n", args[0])
 			fmt.Println("Use --help for usage information.")
 			os.Exit(1)
 		}
@@ -38,52 +91,101 @@ func main() {
 	}
 }
 
+// exitError makes a subcommand exit with a specific code, printing message if set
+type exitError struct {
+	code    int
+	message string
+}
+
+func (e *exitError) Error() string {
+	return e.message
+}
+
+// exitOnError reports a subcommand error and exits non-zero
+func exitOnError(err error) {
+	if err == nil || errors.Is(err, flag.ErrHelp) {
+		return
+	}
+	var exit *exitError
+	if errors.As(err, &exit) {
+		if exit.message != "" {
+			fmt.Fprintln(os.Stderr, exit.message)
+		}
+		os.Exit(exit.code)
+	}
+	fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+	os.Exit(1)
+}
+
 func printVersion() {
 	fmt.Println("piam-anc version 1.0.0")
 	fmt.Println("PIAM Admin Network Configurator")
-	fmt.Println("Built with ❤️ using Charm Bracelet Bubble Tea")
+	if plainMode {
+		fmt.Println("Built using Charm Bracelet Bubble Tea")
+	} else {
+		fmt.Println("Built with ❤️ using Charm Bracelet Bubble Tea")
+	}
 }
 
 func printHelp() {
-	fmt.Println(`
+	help := `
 🔐 PIAM Admin Network Configurator (piam-anc)
 
-A beautiful TUI for managing Cloud SQL and GKE authorized networks across all your Google Cloud projects.
+A beautiful TUI for managing Cloud SQL, GKE, AWS security group and Azure database
+authorized networks across all your cloud projects.
 
 USAGE:
   piam-anc [FLAGS]
+  piam-anc history [--resource NAME] [--user USER] [--since DATE] [--until DATE] [--json]
+  piam-anc restore [--yes] SNAPSHOT_ID
+  piam-anc restore --list [--resource NAME]
+  piam-anc plan [--detailed-exitcode] FILE
+  piam-anc apply [--yes] FILE
+  piam-anc check [--format text|junit|sarif] [--output FILE] [--include-unmanaged] BASELINE
+  piam-anc list [--json] [QUERY...]
+  piam-anc search [--json] [--filter QUERY] IP_OR_CIDR
+  piam-anc export [--output FILE] [--filter QUERY]
+  piam-anc import [--dry-run] [--yes] [--progress FILE] [--restart] FILE
 
 FLAGS:
   -h, --help     Show this help message
   -v, --version  Show version information
+  --dry-run      Preview every change without sending it (before any command)
+  --wait         Wait for busy resources (e.g. RECONCILING) instead of failing (before any command)
 
 EXAMPLES:
   piam-anc                    # Launch the application
   piam-anc --help            # Show this help
   piam-anc --version         # Show version information
+  piam-anc history --resource my-project/my-db --since 2024-01-01
+  piam-anc restore --list --resource my-project/my-db
+  piam-anc plan access.yaml   # Show what apply would change
+  piam-anc --dry-run          # Browse and preview changes; nothing is sent
+  piam-anc --wait apply access.yaml   # Wait up to 10 minutes for busy resources
+  piam-anc check --format junit --output drift.xml access.yaml
+  piam-anc import --dry-run networks.csv   # CSV of project,resource,name,cidr
+  piam-anc search 198.51.100.23            # Every entry that lets this IP in
+  piam-anc list type:sql public:true networks>20   # Quote terms with > or < in your shell
+  piam-anc list open:true                  # Everything reachable from any IP
 
 FEATURES:
   🗄️  SQL Instance Management - View and manage authorized networks
   ☸️  GKE Cluster Support - Manage master authorized networks  
+  🛡️  AWS Security Groups - Manage tagged ingress CIDR rules
+  🔷 Azure Databases - Manage SQL and PostgreSQL flexible server firewall rules
   🔍 Multi-Project Discovery - Finds ALL resources across your projects
   🔒 Smart Access Detection - Shows which resources accept external networks
-  🎨 Beautiful Interface - Catppuccin Mocha themed TUI
+  🎨 Beautiful Interface - Catppuccin and high-contrast themes, or plain with NO_COLOR
   ⚡ Fast Parallel Discovery - Lightning-fast resource scanning
+  ⏱️  Background Jobs - Changes run in the background while you keep browsing
 
-NAVIGATION:
-  ↑/↓     Navigate lists
-  Enter   Select resource
-  /       Search/filter resources
-  a       Add authorized network (when available)
-  c       Open resource in Google Cloud Console
-  r       Refresh resource list
-  Esc     Go back
-  ?       Show help
-  q       Quit
+` + keyMap.keyHelp(":", 78) + `
 
 RESOURCE INDICATORS:
   🗄️      SQL Database instance
   ☸️      GKE Kubernetes cluster  
+  🛡️      AWS security group
+  🔷      Azure SQL server or PostgreSQL flexible server
   🔒      Resource cannot accept external networks (private)
 
 REQUIREMENTS:
@@ -92,11 +194,70 @@ REQUIREMENTS:
     - cloudsql.instances.list/get/update
     - container.clusters.list/get/update
     - resourcemanager.projects.list
+    - logging.logEntries.list (optional, for "Added By" columns)
 
 AUTHENTICATION:
   Before using, ensure you're authenticated:
     gcloud auth application-default login
 
+CONFIGURATION:
+  Optional settings, including the network policy checked before every add,
+  are read from ~/.config/piam-anc/config.json (override with PIAM_ANC_CONFIG).
+  Every change is recorded in ~/.config/piam-anc/audit.jsonl unless the
+  config sets "audit": {"disabled": true}; "audit": {"path": ...} moves it.
+  The full network list is saved to ~/.config/piam-anc/snapshots/ before
+  every change ("snapshots": {"dir": ...} moves it), for undo and restore.
+  "groups" names sets of CIDRs (e.g. an office's egress IPs) to add together.
+  Access requests are queued in ~/.config/piam-anc/requests/; point
+  "requests": {"dir": ...} at a directory shared with your approvers.
+
+THEMES:
+  "theme": {"name": ...} picks auto (Latte on light terminals, otherwise
+  Mocha), mocha, macchiato, frappe, latte, high-contrast or plain.
+  "theme": {"file": ...} loads a JSON palette of Catppuccin colour names,
+  e.g. {"extends": "latte", "mauve": "#8839ef"}; unset colours are inherited.
+    PIAM_ANC_THEME  Theme name, overriding the config file
+    NO_COLOR        Any value turns off colours and emoji
+
+KEY BINDINGS:
+  The keys above are the ones in use. "keys": {"preset": ...} picks default,
+  vim (Ctrl+F/Ctrl+B pages, G jumps to the end) or emacs (Ctrl+N/Ctrl+P,
+  Ctrl+G cancels); "keys": {"bindings": {"quit": ["ctrl+q"]}} rebinds single
+  actions by the names in the README, and an empty list unbinds one.
+
+ATTRIBUTION:
+  "Added By" and "Added At" come from Cloud Audit Logs (Admin Activity).
+    PIAM_ANC_LOGGING_ENDPOINT  Cloud Logging endpoint override, e.g. a local stub
+
+DESIRED STATE:
+  plan and apply read a YAML (or .json) file listing the exact named CIDRs for
+  resources by project/name or label selector. Unlisted resources are untouched.
+  check compares live networks with a desired-state or snapshot file and
+  exits 1 when they differ. Resources the baseline doesn't mention count as
+  drift when open to the world (0.0.0.0/0, or GKE authorized networks off);
+  --include-unmanaged reports all of them. export writes every resource's networks to a
+  versioned JSON file; import adds networks from such a file or a CSV and
+  resumes where it left off after a failure.
+
+AWS (optional):
+  Security groups tagged "piam-anc" are listed when regions are configured.
+  The tag value is the list of managed ports, e.g. "5432" or "5432,6432".
+  Credentials come from the standard AWS chain (env, profile, SSO).
+    PIAM_ANC_AWS_REGIONS   Comma-separated regions, e.g. us-east-1,eu-west-1
+    PIAM_ANC_AWS_ENDPOINT  EC2 endpoint override, e.g. http://localhost:4566
+    PIAM_ANC_AWS_TAG       Tag key marking managed groups (default: piam-anc)
+
+AZURE (optional):
+  SQL servers and PostgreSQL flexible servers are listed when subscriptions
+  are configured. Firewall rule ranges are shown as CIDR blocks.
+    PIAM_ANC_AZURE_SUBSCRIPTIONS  Comma-separated subscription IDs, or "*" for all
+    PIAM_ANC_AZURE_ENDPOINT       ARM endpoint override, e.g. http://localhost:8080
+    PIAM_ANC_AZURE_TOKEN          Bearer token (default: az account get-access-token)
+
 For more information, visit: https://github.com/ExclamationLabs/piam-anc
-`)
+`
+	if plainMode {
+		help = plainText(help)
+	}
+	fmt.Print(help)
 }
\ No newline at end of file
@@ -3,7 +3,9 @@ package main
 import (
 	"context"
 	"fmt"
+	"net"
 	"os/exec"
+	"regexp"
 	"sort"
 	"strings"
 	"sync"
@@ -17,8 +19,10 @@ import (
 type ResourceType string
 
 const (
-	ResourceTypeSQL ResourceType = "SQL"
-	ResourceTypeGKE ResourceType = "GKE"
+	ResourceTypeSQL   ResourceType = "SQL"
+	ResourceTypeGKE   ResourceType = "GKE"
+	ResourceTypeAWS   ResourceType = "AWS"
+	ResourceTypeAzure ResourceType = "AZURE"
 )
 
 // CloudResource is the interface for all manageable resources
@@ -31,6 +35,7 @@ type CloudResource interface {
 	HasPublicIP() bool
 	CanAddNetwork() bool
 	GetNetworkRestrictions() string
+	GetLabels() map[string]string
 }
 
 // SQLInstance represents a Cloud SQL instance
@@ -40,20 +45,45 @@ type SQLInstance struct {
 	Region             string
 	DatabaseVersion    string
 	State              string
+	ActivationPolicy   string // NEVER when the instance is stopped
 	AuthorizedNetworks []AuthorizedNetwork
 	ConnectionName     string
 	PublicIPEnabled    bool
 	PrivateIP          string
+	IPAddresses        []IPAddress
+	Labels             map[string]string
+	Tier               string
+	AvailabilityType   string // ZONAL or REGIONAL
+	MaintenanceWindow  string
+
+	// Replication topology; names are instance names in the same project
+	InstanceType        string // CLOUD_SQL_INSTANCE, READ_REPLICA_INSTANCE, ...
+	MasterInstanceName  string
+	ReplicaNames        []string
+	FailoverReplicaName string
+
+	// Private Service Connect settings
+	PSCEnabled               bool
+	AllowedConsumerProjects  []string
+	PSCServiceAttachmentLink string
 }
 
-func (s SQLInstance) GetName() string        { return s.Name }
-func (s SQLInstance) GetProject() string     { return s.Project }
-func (s SQLInstance) GetRegion() string      { return s.Region }
-func (s SQLInstance) GetType() ResourceType  { return ResourceTypeSQL }
-func (s SQLInstance) GetDisplayName() string { return fmt.Sprintf("%s (%s)", s.Name, s.Project) }
-func (s SQLInstance) HasPublicIP() bool      { return s.PublicIPEnabled }
-func (s SQLInstance) CanAddNetwork() bool    { return s.PublicIPEnabled }
+func (s SQLInstance) GetName() string              { return s.Name }
+func (s SQLInstance) GetProject() string           { return s.Project }
+func (s SQLInstance) GetRegion() string            { return s.Region }
+func (s SQLInstance) GetType() ResourceType        { return ResourceTypeSQL }
+func (s SQLInstance) GetDisplayName() string       { return fmt.Sprintf("%s (%s)", s.Name, s.Project) }
+func (s SQLInstance) HasPublicIP() bool            { return s.PublicIPEnabled }
+func (s SQLInstance) CanAddNetwork() bool          { return s.PublicIPEnabled }
+func (s SQLInstance) GetLabels() map[string]string { return s.Labels }
+
+// IsReplica reports whether the instance replicates from a primary
+func (s SQLInstance) IsReplica() bool { return s.MasterInstanceName != "" }
+
 func (s SQLInstance) GetNetworkRestrictions() string {
+	if !s.PublicIPEnabled && s.PSCEnabled {
+		return "Private IP only - access is governed by PSC allowed consumer projects"
+	}
 	if !s.PublicIPEnabled {
 		return "Private IP only - cannot add external networks"
 	}
@@ -62,44 +92,155 @@ func (s SQLInstance) GetNetworkRestrictions() string {
 
 // GKECluster represents a GKE cluster
 type GKECluster struct {
-	Name                      string
-	Project                   string
-	Location                  string // Can be zone or region
-	State                     string
-	Endpoint                  string
-	MasterAuthorizedNetworks  []AuthorizedNetwork
-	PrivateClusterEnabled     bool
-	PrivateEndpoint           string
-	PublicEndpoint            string
-}
-
-func (g GKECluster) GetName() string        { return g.Name }
-func (g GKECluster) GetProject() string     { return g.Project }
-func (g GKECluster) GetRegion() string      { return g.Location }
-func (g GKECluster) GetType() ResourceType  { return ResourceTypeGKE }
-func (g GKECluster) GetDisplayName() string { return fmt.Sprintf("%s (%s)", g.Name, g.Project) }
-func (g GKECluster) HasPublicIP() bool      { return g.PublicEndpoint != "" }
-func (g GKECluster) CanAddNetwork() bool    { return true } // GKE always allows master authorized networks
+	Name                     string
+	Project                  string
+	Location                 string // Can be zone or region
+	State                    string
+	Endpoint                 string
+	MasterAuthorizedNetworks []AuthorizedNetwork
+	PrivateClusterEnabled    bool
+	PrivateEndpoint          string
+	PublicEndpoint           string
+	Labels                   map[string]string
+	MasterVersion            string
+	NodeVersion              string
+	NodeCount                int64
+	NodePools                []NodePool
+	Autopilot                bool
+	MaintenanceWindow        string
+
+	// When disabled the control plane accepts connections from any IP,
+	// regardless of the entries in MasterAuthorizedNetworks
+	MasterAuthorizedNetworksEnabled bool
+	GCPPublicCidrsAccessEnabled     bool
+}
+
+func (g GKECluster) GetName() string              { return g.Name }
+func (g GKECluster) GetProject() string           { return g.Project }
+func (g GKECluster) GetRegion() string            { return g.Location }
+func (g GKECluster) GetType() ResourceType        { return ResourceTypeGKE }
+func (g GKECluster) GetDisplayName() string       { return fmt.Sprintf("%s (%s)", g.Name, g.Project) }
+func (g GKECluster) HasPublicIP() bool            { return g.PublicEndpoint != "" }
+func (g GKECluster) CanAddNetwork() bool          { return g.MasterAuthorizedNetworksEnabled }
+func (g GKECluster) GetLabels() map[string]string { return g.Labels }
 func (g GKECluster) GetNetworkRestrictions() string {
+	if !g.MasterAuthorizedNetworksEnabled {
+		return "Master authorized networks disabled - control plane open to all IPs"
+	}
 	if g.PrivateClusterEnabled && g.PublicEndpoint == "" {
 		return "Private cluster - access via private endpoint only"
 	}
 	return ""
 }
 
+// IPAddress is one of a resource's addresses, with its type (PRIMARY, PRIVATE, OUTGOING, ...)
+type IPAddress struct {
+	Type    string
+	Address string
+}
+
+// NodePool summarizes a GKE node pool
+type NodePool struct {
+	Name        string
+	MachineType string
+	Version     string
+	NodeCount   int64 // Initial nodes per zone
+	MinNodes    int64 // Autoscaling limits per zone; both 0 when autoscaling is off
+	MaxNodes    int64
+	Zones       int
+}
+
 // AuthorizedNetwork represents an authorized network entry
 type AuthorizedNetwork struct {
 	Kind        string `json:"kind,omitempty"`
 	Name        string `json:"name"`
 	Value       string `json:"value"`
 	DisplayName string `json:"displayName,omitempty"` // For GKE
+	Port        string `json:"port,omitempty"`        // For AWS
+}
+
+// resourceNetworks returns the authorized networks of any resource type
+func resourceNetworks(resource CloudResource) []AuthorizedNetwork {
+	switch r := resource.(type) {
+	case SQLInstance:
+		return r.AuthorizedNetworks
+	case GKECluster:
+		return r.MasterAuthorizedNetworks
+	case AWSSecurityGroup:
+		return r.AuthorizedNetworks
+	case AzureDatabaseServer:
+		return r.AuthorizedNetworks
+	default:
+		return nil
+	}
+}
+
+// ResourceRef identifies a resource independently of its current state, for files written to disk
+type ResourceRef struct {
+	Type     ResourceType `json:"type"`
+	Project  string       `json:"project"`
+	Location string       `json:"location,omitempty"`
+	Name     string       `json:"name"`
+	ID       string       `json:"id,omitempty"`   // AWS group ID or Azure resource ID
+	Kind     string       `json:"kind,omitempty"` // Azure server kind
+}
+
+// resourceRefOf returns the reference for a resource
+func resourceRefOf(resource CloudResource) ResourceRef {
+	ref := ResourceRef{
+		Type:     resource.GetType(),
+		Project:  resource.GetProject(),
+		Location: resource.GetRegion(),
+		Name:     resource.GetName(),
+	}
+	switch r := resource.(type) {
+	case AWSSecurityGroup:
+		ref.ID = r.GroupID
+	case AzureDatabaseServer:
+		ref.ID = r.ID
+		ref.Kind = string(r.Kind)
+	}
+	return ref
+}
+
+// Resource returns a placeholder resource carrying just enough to look up its current details
+func (ref ResourceRef) Resource() (CloudResource, error) {
+	switch ref.Type {
+	case ResourceTypeSQL:
+		return SQLInstance{Project: ref.Project, Region: ref.Location, Name: ref.Name}, nil
+	case ResourceTypeGKE:
+		return GKECluster{Project: ref.Project, Location: ref.Location, Name: ref.Name}, nil
+	case ResourceTypeAWS:
+		return AWSSecurityGroup{Account: ref.Project, Region: ref.Location, GroupName: ref.Name, GroupID: ref.ID}, nil
+	case ResourceTypeAzure:
+		return AzureDatabaseServer{Subscription: ref.Project, Location: ref.Location, Name: ref.Name, ID: ref.ID, Kind: AzureServerKind(ref.Kind)}, nil
+	default:
+		return nil, fmt.Errorf("unknown resource type %q", ref.Type)
+	}
+}
+
+// resourceKey identifies a resource uniquely within one discovery
+func resourceKey(resource CloudResource) string {
+	return fmt.Sprintf("%s/%s/%s/%s", resource.GetType(), resource.GetProject(), resource.GetRegion(), resource.GetName())
+}
+
+// String returns "TYPE project/name"
+func (ref ResourceRef) String() string {
+	return fmt.Sprintf("%s %s/%s", ref.Type, ref.Project, ref.Name)
 }
 
 // NetworkManager handles cloud resource operations
 type NetworkManager struct {
-	sqlService *sqladmin.Service
-	gkeService *container.Service
-	ctx        context.Context
+	sqlService   *sqladmin.Service
+	gkeService   *container.Service
+	policy       *Policy
+	audit        *AuditLog // nil when auditing is disabled
+	snapshots    *SnapshotStore
+	aws          *awsProvider   // nil unless PIAM_ANC_AWS_REGIONS is set
+	azure        *azureProvider // nil unless PIAM_ANC_AZURE_SUBSCRIPTIONS is set
+	dryRun       bool           // Changes are previewed but never sent
+	waitForReady bool           // Changes wait for busy resources instead of failing
+	ctx          context.Context
 }
 
 // NewNetworkManager creates a new NetworkManager
@@ -114,10 +255,41 @@ func NewNetworkManager(ctx context.Context) (*NetworkManager, error) {
 		return nil, fmt.Errorf("failed to create Container service: %v", err)
 	}
 
+	config, err := LoadConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	policy, err := NewPolicy(config.Policy)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := NewNetworkGroups(config.Groups); err != nil {
+		return nil, err
+	}
+
+	awsProvider, err := newAWSProvider(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	azureProvider, err := newAzureProvider(ctx)
+	if err != nil {
+		return nil, err
+	}
+
 	return &NetworkManager{
-		sqlService: sqlService,
-		gkeService: gkeService,
-		ctx:        ctx,
+		sqlService:   sqlService,
+		gkeService:   gkeService,
+		policy:       policy,
+		audit:        NewAuditLog(config.Audit),
+		snapshots:    NewSnapshotStore(config.Snapshots),
+		aws:          awsProvider,
+		azure:        azureProvider,
+		dryRun:       dryRunMode,
+		waitForReady: waitForReadyMode,
+		ctx:          ctx,
 	}, nil
 }
 
@@ -188,9 +360,12 @@ func (nm *NetworkManager) ListAllResources() ([]CloudResource, error) {
 	if len(projects) < maxConcurrent {
 		maxConcurrent = len(projects)
 	}
+	if maxConcurrent == 0 {
+		maxConcurrent = 1
+	}
 	semaphore := make(chan struct{}, maxConcurrent)
 	
-	resultChan := make(chan result, len(projects)*2) // SQL + GKE per project
+	resultChan := make(chan result, len(projects)*2+awsRegionCount(nm.aws)+azureListCount(nm.azure)) // SQL + GKE per project, plus AWS and Azure
 	var wg sync.WaitGroup
 	
 	// Launch goroutines for each project to get both SQL and GKE resources
@@ -229,6 +404,48 @@ func (nm *NetworkManager) ListAllResources() ([]CloudResource, error) {
 			}
 		}(project)
 	}
+
+	// AWS security groups, one lookup per configured region
+	if nm.aws != nil {
+		for _, region := range nm.aws.regions {
+			wg.Add(1)
+			go func(r string) {
+				defer wg.Done()
+
+				semaphore <- struct{}{}
+				defer func() { <-semaphore }()
+
+				resources, err := nm.aws.listSecurityGroups(nm.ctx, r)
+				resultChan <- result{
+					resources: resources,
+					project:   "aws:" + r,
+					err:       err,
+				}
+			}(region)
+		}
+	}
+
+	// Azure SQL servers and PostgreSQL flexible servers, per subscription
+	if nm.azure != nil {
+		for _, subscription := range nm.azure.subscriptions {
+			for _, kind := range []AzureServerKind{AzureServerKindSQL, AzureServerKindPostgres} {
+				wg.Add(1)
+				go func(s string, k AzureServerKind) {
+					defer wg.Done()
+
+					semaphore <- struct{}{}
+					defer func() { <-semaphore }()
+
+					resources, err := nm.azure.listServers(nm.ctx, s, k)
+					resultChan <- result{
+						resources: resources,
+						project:   "azure:" + s,
+						err:       err,
+					}
+				}(subscription, kind)
+			}
+		}
+	}
 	
 	// Close the channel when all goroutines complete
 	go func() {
@@ -248,6 +465,10 @@ func (nm *NetworkManager) ListAllResources() ([]CloudResource, error) {
 				resourceType = "SQL"
 			} else if strings.Contains(res.err.Error(), "GKE") {
 				resourceType = "GKE"
+			} else if strings.Contains(res.err.Error(), "AWS") {
+				resourceType = "AWS"
+			} else if strings.Contains(res.err.Error(), "Azure") {
+				resourceType = "AZURE"
 			}
 			failedProjects[res.project] = append(failedProjects[res.project], resourceType)
 			continue
@@ -271,47 +492,7 @@ func (nm *NetworkManager) listSQLInstancesInProject(project string) ([]CloudReso
 
 	var resources []CloudResource
 	for _, instance := range resp.Items {
-		// Check if instance has public IP
-		hasPublicIP := false
-		privateIP := ""
-		
-		if instance.IpAddresses != nil {
-			for _, ip := range instance.IpAddresses {
-				if ip.Type == "PRIMARY" {
-					hasPublicIP = true
-				} else if ip.Type == "PRIVATE" {
-					privateIP = ip.IpAddress
-				}
-			}
-		}
-		
-		// Alternative check via settings
-		if instance.Settings != nil && instance.Settings.IpConfiguration != nil {
-			hasPublicIP = instance.Settings.IpConfiguration.Ipv4Enabled
-		}
-
-		sqlInstance := SQLInstance{
-			Name:            instance.Name,
-			Project:         project,
-			Region:          instance.Region,
-			DatabaseVersion: instance.DatabaseVersion,
-			State:           instance.State,
-			ConnectionName:  instance.ConnectionName,
-			PublicIPEnabled: hasPublicIP,
-			PrivateIP:       privateIP,
-		}
-
-		// Convert authorized networks
-		if instance.Settings != nil && instance.Settings.IpConfiguration != nil {
-			for _, network := range instance.Settings.IpConfiguration.AuthorizedNetworks {
-				sqlInstance.AuthorizedNetworks = append(sqlInstance.AuthorizedNetworks, AuthorizedNetwork{
-					Kind:  network.Kind,
-					Name:  network.Name,
-					Value: network.Value,
-				})
-			}
-		}
-
+		sqlInstance := convertSQLInstance(project, instance)
 		resources = append(resources, sqlInstance)
 	}
 
@@ -330,34 +511,7 @@ func (nm *NetworkManager) listGKEClustersInProject(project string) ([]CloudResou
 
 	var resources []CloudResource
 	for _, cluster := range resp.Clusters {
-		gkeCluster := GKECluster{
-			Name:     cluster.Name,
-			Project:  project,
-			Location: cluster.Location,
-			State:    cluster.Status,
-			Endpoint: cluster.Endpoint,
-		}
-
-		// Check private cluster configuration
-		if cluster.PrivateClusterConfig != nil {
-			gkeCluster.PrivateClusterEnabled = cluster.PrivateClusterConfig.EnablePrivateNodes
-			gkeCluster.PrivateEndpoint = cluster.PrivateClusterConfig.PrivateEndpoint
-			gkeCluster.PublicEndpoint = cluster.PrivateClusterConfig.PublicEndpoint
-		} else {
-			gkeCluster.PublicEndpoint = cluster.Endpoint
-		}
-
-		// Convert master authorized networks
-		if cluster.MasterAuthorizedNetworksConfig != nil && cluster.MasterAuthorizedNetworksConfig.Enabled {
-			for _, network := range cluster.MasterAuthorizedNetworksConfig.CidrBlocks {
-				gkeCluster.MasterAuthorizedNetworks = append(gkeCluster.MasterAuthorizedNetworks, AuthorizedNetwork{
-					Name:        network.DisplayName,
-					DisplayName: network.DisplayName,
-					Value:       network.CidrBlock,
-				})
-			}
-		}
-
+		gkeCluster := convertGKECluster(project, cluster.Location, cluster)
 		resources = append(resources, gkeCluster)
 	}
 
@@ -371,6 +525,16 @@ func (nm *NetworkManager) GetResourceDetails(resource CloudResource) (CloudResou
 		return nm.getSQLInstanceDetails(r.Project, r.Name)
 	case GKECluster:
 		return nm.getGKEClusterDetails(r.Project, r.Location, r.Name)
+	case AWSSecurityGroup:
+		if nm.aws == nil {
+			return nil, fmt.Errorf("AWS provider is not configured")
+		}
+		return nm.aws.getSecurityGroupDetails(nm.ctx, r.Region, r.GroupID)
+	case AzureDatabaseServer:
+		if nm.azure == nil {
+			return nil, fmt.Errorf("Azure provider is not configured")
+		}
+		return nm.azure.getServerDetails(nm.ctx, r)
 	default:
 		return nil, fmt.Errorf("unknown resource type")
 	}
@@ -383,12 +547,19 @@ func (nm *NetworkManager) getSQLInstanceDetails(project, instanceName string) (C
 		return nil, fmt.Errorf("failed to get SQL instance details: %v", err)
 	}
 
+	return convertSQLInstance(project, instance), nil
+}
+
+// convertSQLInstance maps a Cloud SQL API instance onto SQLInstance
+func convertSQLInstance(project string, instance *sqladmin.DatabaseInstance) SQLInstance {
 	// Check if instance has public IP
 	hasPublicIP := false
 	privateIP := ""
 	
+	var addresses []IPAddress
 	if instance.IpAddresses != nil {
 		for _, ip := range instance.IpAddresses {
+			addresses = append(addresses, IPAddress{Type: ip.Type, Address: ip.IpAddress})
 			if ip.Type == "PRIMARY" {
 				hasPublicIP = true
 			} else if ip.Type == "PRIVATE" {
@@ -397,23 +568,43 @@ func (nm *NetworkManager) getSQLInstanceDetails(project, instanceName string) (C
 		}
 	}
 	
+	// Alternative check via settings
 	if instance.Settings != nil && instance.Settings.IpConfiguration != nil {
 		hasPublicIP = instance.Settings.IpConfiguration.Ipv4Enabled
 	}
 
 	sqlInstance := SQLInstance{
-		Name:            instance.Name,
-		Project:         project,
-		Region:          instance.Region,
-		DatabaseVersion: instance.DatabaseVersion,
-		State:           instance.State,
-		ConnectionName:  instance.ConnectionName,
-		PublicIPEnabled: hasPublicIP,
-		PrivateIP:       privateIP,
+		Name:                     instance.Name,
+		Project:                  project,
+		Region:                   instance.Region,
+		DatabaseVersion:          instance.DatabaseVersion,
+		State:                    instance.State,
+		ConnectionName:           instance.ConnectionName,
+		PublicIPEnabled:          hasPublicIP,
+		PrivateIP:                privateIP,
+		IPAddresses:              addresses,
+		PSCServiceAttachmentLink: instance.PscServiceAttachmentLink,
+		InstanceType:             instance.InstanceType,
+		MasterInstanceName:       stripProjectPrefix(instance.MasterInstanceName),
+	}
+
+	for _, replica := range instance.ReplicaNames {
+		sqlInstance.ReplicaNames = append(sqlInstance.ReplicaNames, stripProjectPrefix(replica))
+	}
+	if instance.FailoverReplica != nil {
+		sqlInstance.FailoverReplicaName = stripProjectPrefix(instance.FailoverReplica.Name)
+	}
+
+	if instance.Settings != nil {
+		sqlInstance.Labels = instance.Settings.UserLabels
+		sqlInstance.ActivationPolicy = instance.Settings.ActivationPolicy
+		sqlInstance.Tier = instance.Settings.Tier
+		sqlInstance.AvailabilityType = instance.Settings.AvailabilityType
+		sqlInstance.MaintenanceWindow = sqlMaintenanceWindow(instance.Settings.MaintenanceWindow)
 	}
 
-	// Convert authorized networks
 	if instance.Settings != nil && instance.Settings.IpConfiguration != nil {
+		// Convert authorized networks
 		for _, network := range instance.Settings.IpConfiguration.AuthorizedNetworks {
 			sqlInstance.AuthorizedNetworks = append(sqlInstance.AuthorizedNetworks, AuthorizedNetwork{
 				Kind:  network.Kind,
@@ -421,9 +612,15 @@ func (nm *NetworkManager) getSQLInstanceDetails(project, instanceName string) (C
 				Value: network.Value,
 			})
 		}
+
+		// Private Service Connect allowlist
+		if psc := instance.Settings.IpConfiguration.PscConfig; psc != nil {
+			sqlInstance.PSCEnabled = psc.PscEnabled
+			sqlInstance.AllowedConsumerProjects = psc.AllowedConsumerProjects
+		}
 	}
 
-	return sqlInstance, nil
+	return sqlInstance
 }
 
 // getGKEClusterDetails gets detailed info for a GKE cluster
@@ -434,12 +631,41 @@ func (nm *NetworkManager) getGKEClusterDetails(project, location, clusterName st
 		return nil, fmt.Errorf("failed to get GKE cluster details: %v", err)
 	}
 
+	return convertGKECluster(project, location, cluster), nil
+}
+
+// convertGKECluster maps a GKE API cluster onto GKECluster
+func convertGKECluster(project, location string, cluster *container.Cluster) GKECluster {
 	gkeCluster := GKECluster{
 		Name:     cluster.Name,
 		Project:  project,
 		Location: location,
 		State:    cluster.Status,
 		Endpoint: cluster.Endpoint,
+		Labels:   cluster.ResourceLabels,
+
+		MasterVersion:     cluster.CurrentMasterVersion,
+		NodeVersion:       cluster.CurrentNodeVersion,
+		NodeCount:         cluster.CurrentNodeCount,
+		Autopilot:         cluster.Autopilot != nil && cluster.Autopilot.Enabled,
+		MaintenanceWindow: gkeMaintenanceWindow(cluster.MaintenancePolicy),
+	}
+
+	for _, pool := range cluster.NodePools {
+		nodePool := NodePool{
+			Name:      pool.Name,
+			Version:   pool.Version,
+			NodeCount: pool.InitialNodeCount,
+			Zones:     len(pool.Locations),
+		}
+		if pool.Config != nil {
+			nodePool.MachineType = pool.Config.MachineType
+		}
+		if pool.Autoscaling != nil && pool.Autoscaling.Enabled {
+			nodePool.MinNodes = pool.Autoscaling.MinNodeCount
+			nodePool.MaxNodes = pool.Autoscaling.MaxNodeCount
+		}
+		gkeCluster.NodePools = append(gkeCluster.NodePools, nodePool)
 	}
 
 	// Check private cluster configuration
@@ -451,9 +677,11 @@ func (nm *NetworkManager) getGKEClusterDetails(project, location, clusterName st
 		gkeCluster.PublicEndpoint = cluster.Endpoint
 	}
 
-	// Convert master authorized networks
-	if cluster.MasterAuthorizedNetworksConfig != nil && cluster.MasterAuthorizedNetworksConfig.Enabled {
-		for _, network := range cluster.MasterAuthorizedNetworksConfig.CidrBlocks {
+	// Convert master authorized networks, keeping entries even when the feature is disabled
+	if config := cluster.MasterAuthorizedNetworksConfig; config != nil {
+		gkeCluster.MasterAuthorizedNetworksEnabled = config.Enabled
+		gkeCluster.GCPPublicCidrsAccessEnabled = config.GcpPublicCidrsAccessEnabled
+		for _, network := range config.CidrBlocks {
 			gkeCluster.MasterAuthorizedNetworks = append(gkeCluster.MasterAuthorizedNetworks, AuthorizedNetwork{
 				Name:        network.DisplayName,
 				DisplayName: network.DisplayName,
@@ -462,36 +690,329 @@ func (nm *NetworkManager) getGKEClusterDetails(project, location, clusterName st
 		}
 	}
 
-	return gkeCluster, nil
+	return gkeCluster
+}
+
+// sqlMaintenanceWindow describes a Cloud SQL maintenance window, e.g. "Sunday 03:00 UTC"
+func sqlMaintenanceWindow(window *sqladmin.MaintenanceWindow) string {
+	if window == nil {
+		return ""
+	}
+	day := "Any day"
+	if window.Day >= 1 && window.Day <= 7 {
+		// The API numbers days from Monday = 1
+		day = time.Weekday(window.Day % 7).String()
+	}
+	description := fmt.Sprintf("%s %02d:00 UTC", day, window.Hour)
+	if window.UpdateTrack != "" {
+		description += fmt.Sprintf(" (%s track)", window.UpdateTrack)
+	}
+	return description
 }
 
-// AddNetworkToResource adds an authorized network to a resource
+// gkeMaintenanceWindow describes a GKE maintenance window, e.g. "Daily 03:00 UTC"
+func gkeMaintenanceWindow(policy *container.MaintenancePolicy) string {
+	if policy == nil || policy.Window == nil {
+		return ""
+	}
+	if daily := policy.Window.DailyMaintenanceWindow; daily != nil {
+		return fmt.Sprintf("Daily %s UTC", daily.StartTime)
+	}
+	if recurring := policy.Window.RecurringWindow; recurring != nil && recurring.Window != nil {
+		start, errStart := time.Parse(time.RFC3339, recurring.Window.StartTime)
+		end, errEnd := time.Parse(time.RFC3339, recurring.Window.EndTime)
+		if errStart != nil || errEnd != nil {
+			return recurring.Recurrence
+		}
+		return fmt.Sprintf("%s %s-%s UTC", recurring.Recurrence, start.UTC().Format("15:04"), end.UTC().Format("15:04"))
+	}
+	return ""
+}
+
+// AddNetworkToResource adds an authorized network to a resource after checking it against policy
 func (nm *NetworkManager) AddNetworkToResource(resource CloudResource, networkName, networkIP string) error {
+	return nm.audited("add_network", resource, fmt.Sprintf("%s %s", networkName, networkIP), func(current CloudResource) (string, error) {
+		// Check against the live list, since another change may have landed since it was loaded
+		if err := nm.policy.Check(current, networkName, networkIP, resourceNetworks(current)); err != nil {
+			return "", err
+		}
+		return nm.addNetwork(current, networkName, networkIP)
+	})
+}
+
+// addNetwork adds an authorized network without policy checks or auditing, returning the operation ID
+func (nm *NetworkManager) addNetwork(resource CloudResource, networkName, networkIP string) (string, error) {
 	switch r := resource.(type) {
 	case SQLInstance:
 		if !r.PublicIPEnabled {
-			return fmt.Errorf("cannot add network to SQL instance without public IP")
+			return "", fmt.Errorf("cannot add network to SQL instance without public IP")
 		}
 		return nm.addNetworkToSQLInstance(r.Project, r.Name, networkName, networkIP)
 	case GKECluster:
 		return nm.addNetworkToGKECluster(r.Project, r.Location, r.Name, networkName, networkIP)
+	default:
+		return "", nm.addProviderNetwork(resource, networkName, networkIP)
+	}
+}
+
+// ResourceResult is the outcome of an operation on one resource of a batch
+type ResourceResult struct {
+	Resource CloudResource
+	Err      error
+}
+
+// AddNetworkToResources adds an authorized network to several resources in parallel
+func (nm *NetworkManager) AddNetworkToResources(resources []CloudResource, networkName, networkIP string) []ResourceResult {
+	return nm.forEachResource(resources, func(resource CloudResource) error {
+		return nm.AddNetworkToResource(resource, networkName, networkIP)
+	})
+}
+
+// RemoveNetworkFromResources removes an authorized network from several resources in parallel
+func (nm *NetworkManager) RemoveNetworkFromResources(resources []CloudResource, networkIP string) []ResourceResult {
+	return nm.forEachResource(resources, func(resource CloudResource) error {
+		return nm.RemoveNetworkFromResource(resource, networkIP)
+	})
+}
+
+// forEachResource runs an operation on each resource concurrently and collects the results in order
+func (nm *NetworkManager) forEachResource(resources []CloudResource, operation func(CloudResource) error) []ResourceResult {
+	results := make([]ResourceResult, len(resources))
+	var wg sync.WaitGroup
+	for i, resource := range resources {
+		wg.Add(1)
+		go func(i int, resource CloudResource) {
+			defer wg.Done()
+			results[i] = ResourceResult{Resource: resource, Err: operation(resource)}
+		}(i, resource)
+	}
+	wg.Wait()
+	return results
+}
+
+// RemoveNetworkFromResource removes an authorized network from a resource
+func (nm *NetworkManager) RemoveNetworkFromResource(resource CloudResource, networkIP string) error {
+	return nm.audited("remove_network", resource, networkIP, func(CloudResource) (string, error) {
+		switch r := resource.(type) {
+		case SQLInstance:
+			return nm.removeNetworkFromSQLInstance(r.Project, r.Name, networkIP)
+		case GKECluster:
+			return nm.removeNetworkFromGKECluster(r.Project, r.Location, r.Name, networkIP)
+		default:
+			return "", nm.removeProviderNetwork(resource, networkIP)
+		}
+	})
+}
+
+// editedNetworks returns a resource's network list with one entry renamed or moved to a new CIDR
+func editedNetworks(resource CloudResource, oldIP, name, ip string, policy *Policy) ([]AuthorizedNetwork, NetworkDiff, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return nil, NetworkDiff{}, fmt.Errorf("network name is required")
+	}
+	oldValue, _ := normalizeIP(oldIP)
+	newValue, _ := normalizeIP(strings.TrimSpace(ip))
+	if _, _, err := net.ParseCIDR(newValue); err != nil {
+		return nil, NetworkDiff{}, fmt.Errorf("invalid IP format: %v", err)
+	}
+
+	current := resourceNetworks(resource)
+	var desired, others []AuthorizedNetwork
+	found := false
+	for _, network := range current {
+		value, _ := normalizeIP(network.Value)
+		if value == oldValue {
+			// AWS lists one entry per port; they all move together
+			found = true
+			network.Name = name
+			network.DisplayName = name
+			network.Value = newValue
+		} else if value == newValue {
+			return nil, NetworkDiff{}, fmt.Errorf("network %s already exists with name %s", newValue, networkName(network))
+		} else {
+			others = append(others, network)
+		}
+		desired = append(desired, network)
+	}
+	if !found {
+		return nil, NetworkDiff{}, fmt.Errorf("network %s not found", oldValue)
+	}
+
+	diff := diffNetworks(current, desired)
+	if diff.Empty() {
+		return desired, diff, nil
+	}
+	if newValue != oldValue && !resource.CanAddNetwork() {
+		return nil, diff, fmt.Errorf("cannot add networks: %s", resource.GetNetworkRestrictions())
+	}
+	return desired, diff, policy.Check(resource, name, newValue, others)
+}
+
+// EditNetwork renames an entry or changes its CIDR, in a single patch where the API allows it
+func (nm *NetworkManager) EditNetwork(resource CloudResource, oldIP, name, ip string) error {
+	// Work from the live list, since the whole list is replaced
+	resource, err := nm.GetResourceDetails(resource)
+	if err != nil {
+		return err
+	}
+
+	desired, diff, err := editedNetworks(resource, oldIP, name, ip, nm.policy)
+	if err != nil {
+		return err
+	}
+	if diff.Empty() {
+		return nil
+	}
+
+	return nm.audited("edit_network", resource, fmt.Sprintf("%s → %s %s", oldIP, name, ip), func(CloudResource) (string, error) {
+		return nm.setResourceNetworks(resource, desired)
+	})
+}
+
+// setResourceNetworks replaces a resource's networks in a single patch where the API allows it
+func (nm *NetworkManager) setResourceNetworks(resource CloudResource, networks []AuthorizedNetwork) (string, error) {
+	targetByValue, order := indexNetworks(networks)
+
+	switch r := resource.(type) {
+	case SQLInstance:
+		instance, err := nm.sqlService.Instances.Get(r.Project, r.Name).Context(nm.ctx).Do()
+		if err != nil {
+			return "", fmt.Errorf("failed to get instance: %v", err)
+		}
+		if instance.Settings == nil || instance.Settings.IpConfiguration == nil {
+			return "", fmt.Errorf("instance has no IP configuration")
+		}
+
+		entries := make([]*sqladmin.AclEntry, 0, len(order))
+		for _, value := range order {
+			entries = append(entries, &sqladmin.AclEntry{
+				Kind:  "sql#aclEntry",
+				Name:  networkName(targetByValue[value]),
+				Value: value,
+			})
+		}
+		instance.Settings.IpConfiguration.AuthorizedNetworks = entries
+		// An empty list must be sent explicitly or the API ignores it
+		instance.Settings.IpConfiguration.ForceSendFields = append(instance.Settings.IpConfiguration.ForceSendFields, "AuthorizedNetworks")
+
+		operation, err := nm.sqlService.Instances.Patch(r.Project, r.Name, &sqladmin.DatabaseInstance{Settings: instance.Settings}).Context(nm.ctx).Do()
+		if err != nil {
+			return "", fmt.Errorf("failed to update instance: %v", err)
+		}
+		return operation.Name, nm.waitForSQLOperation(r.Project, operation.Name, 30*time.Second)
+	case GKECluster:
+		return nm.updateGKEMasterAuthorizedNetworksConfig(r, func(config *container.MasterAuthorizedNetworksConfig) error {
+			if !config.Enabled && len(order) > 0 {
+				return fmt.Errorf("master authorized networks are disabled on this cluster")
+			}
+			config.CidrBlocks = gkeCidrBlocks(networks)
+			return nil
+		})
+	case AWSSecurityGroup, AzureDatabaseServer:
+		// Rules are separate objects here, so apply the difference one rule at a time
+		current, err := nm.GetResourceDetails(resource)
+		if err != nil {
+			return "", err
+		}
+		diff := diffNetworks(resourceNetworks(current), networks)
+		for _, rename := range diff.Renamed {
+			diff.Removed = append(diff.Removed, AuthorizedNetwork{Value: rename.Value, Name: rename.From})
+			diff.Added = append(diff.Added, AuthorizedNetwork{Value: rename.Value, Name: rename.To})
+		}
+		for _, network := range diff.Removed {
+			if err := nm.removeProviderNetwork(current, network.Value); err != nil {
+				return "", err
+			}
+		}
+		for _, network := range diff.Added {
+			if err := nm.addProviderNetwork(current, networkName(network), network.Value); err != nil {
+				return "", err
+			}
+		}
+		return "", nil
+	default:
+		return "", fmt.Errorf("unknown resource type")
+	}
+}
+
+// gkeCidrBlocks converts a network list into a cluster's CIDR blocks, one per CIDR
+func gkeCidrBlocks(networks []AuthorizedNetwork) []*container.CidrBlock {
+	targetByValue, order := indexNetworks(networks)
+	blocks := make([]*container.CidrBlock, 0, len(order))
+	for _, value := range order {
+		blocks = append(blocks, &container.CidrBlock{
+			DisplayName: networkName(targetByValue[value]),
+			CidrBlock:   value,
+		})
+	}
+	return blocks
+}
+
+// addProviderNetwork adds a network to an AWS or Azure resource, which have no single-patch API
+func (nm *NetworkManager) addProviderNetwork(resource CloudResource, networkName, networkIP string) error {
+	switch r := resource.(type) {
+	case AWSSecurityGroup:
+		if nm.aws == nil {
+			return fmt.Errorf("AWS provider is not configured")
+		}
+		return nm.aws.addNetworkToSecurityGroup(nm.ctx, r.Region, r.GroupID, networkName, networkIP)
+	case AzureDatabaseServer:
+		if nm.azure == nil {
+			return fmt.Errorf("Azure provider is not configured")
+		}
+		return nm.azure.addNetworkToServer(nm.ctx, r, networkName, networkIP)
+	default:
+		return fmt.Errorf("unknown resource type")
+	}
+}
+
+// removeProviderNetwork removes a network from an AWS or Azure resource
+func (nm *NetworkManager) removeProviderNetwork(resource CloudResource, networkIP string) error {
+	switch r := resource.(type) {
+	case AWSSecurityGroup:
+		if nm.aws == nil {
+			return fmt.Errorf("AWS provider is not configured")
+		}
+		return nm.aws.removeNetworkFromSecurityGroup(nm.ctx, r.Region, r.GroupID, networkIP)
+	case AzureDatabaseServer:
+		if nm.azure == nil {
+			return fmt.Errorf("Azure provider is not configured")
+		}
+		return nm.azure.removeNetworkFromServer(nm.ctx, r, networkIP)
 	default:
 		return fmt.Errorf("unknown resource type")
 	}
 }
 
 // addNetworkToSQLInstance adds a network to a SQL instance
-func (nm *NetworkManager) addNetworkToSQLInstance(project, instanceName, networkName, networkIP string) error {
+func (nm *NetworkManager) addNetworkToSQLInstance(project, instanceName, networkName, networkIP string) (string, error) {
+	updateRequest, _, err := nm.sqlAddNetworkPatch(project, instanceName, networkName, networkIP)
+	if err != nil {
+		return "", err
+	}
+
+	operation, err := nm.sqlService.Instances.Patch(project, instanceName, updateRequest).Context(nm.ctx).Do()
+	if err != nil {
+		return "", fmt.Errorf("failed to update instance: %v", err)
+	}
+
+	// Wait for operation to complete (optional, could be async)
+	return operation.Name, nm.waitForSQLOperation(project, operation.Name, 30*time.Second)
+}
+
+// sqlAddNetworkPatch builds the patch that adds a network to a SQL instance, returning it
+// with the instance's current entries
+func (nm *NetworkManager) sqlAddNetworkPatch(project, instanceName, networkName, networkIP string) (*sqladmin.DatabaseInstance, []*sqladmin.AclEntry, error) {
 	// Normalize the IP
 	normalizedIP, err := normalizeIP(networkIP)
 	if err != nil {
-		return fmt.Errorf("invalid IP format: %v", err)
+		return nil, nil, fmt.Errorf("invalid IP format: %v", err)
 	}
 
 	// Get current instance configuration
 	instance, err := nm.sqlService.Instances.Get(project, instanceName).Context(nm.ctx).Do()
 	if err != nil {
-		return fmt.Errorf("failed to get instance: %v", err)
+		return nil, nil, fmt.Errorf("failed to get instance: %v", err)
 	}
 
 	// Initialize settings if needed
@@ -503,12 +1024,13 @@ func (nm *NetworkManager) addNetworkToSQLInstance(project, instanceName, network
 	}
 
 	// Check if network already exists
-	for _, network := range instance.Settings.IpConfiguration.AuthorizedNetworks {
+	current := instance.Settings.IpConfiguration.AuthorizedNetworks
+	for _, network := range current {
 		if network.Value == normalizedIP {
 			if network.Name == networkName {
-				return fmt.Errorf("network %s with name %s already exists", normalizedIP, networkName)
+				return nil, nil, fmt.Errorf("network %s with name %s already exists", normalizedIP, networkName)
 			} else {
-				return fmt.Errorf("network %s already exists with name %s", normalizedIP, network.Name)
+				return nil, nil, fmt.Errorf("network %s already exists with name %s", normalizedIP, network.Name)
 			}
 		}
 	}
@@ -521,53 +1043,63 @@ func (nm *NetworkManager) addNetworkToSQLInstance(project, instanceName, network
 	}
 
 	instance.Settings.IpConfiguration.AuthorizedNetworks = append(
-		instance.Settings.IpConfiguration.AuthorizedNetworks,
+		append([]*sqladmin.AclEntry{}, current...),
 		newNetwork,
 	)
 
 	// Update the instance
-	updateRequest := &sqladmin.DatabaseInstance{
+	return &sqladmin.DatabaseInstance{
 		Settings: instance.Settings,
+	}, current, nil
+}
+
+// addNetworkToGKECluster adds a network to a GKE cluster's master authorized networks
+func (nm *NetworkManager) addNetworkToGKECluster(project, location, clusterName, networkName, networkIP string) (string, error) {
+	updateRequest, _, err := nm.gkeAddNetworkUpdate(project, location, clusterName, networkName, networkIP)
+	if err != nil {
+		return "", err
 	}
 
-	operation, err := nm.sqlService.Instances.Patch(project, instanceName, updateRequest).Context(nm.ctx).Do()
+	// Update the cluster
+	name := fmt.Sprintf("projects/%s/locations/%s/clusters/%s", project, location, clusterName)
+	operation, err := nm.gkeService.Projects.Locations.Clusters.Update(name, updateRequest).Context(nm.ctx).Do()
 	if err != nil {
-		return fmt.Errorf("failed to update instance: %v", err)
+		return "", fmt.Errorf("failed to update cluster: %v", err)
 	}
 
 	// Wait for operation to complete (optional, could be async)
-	return nm.waitForSQLOperation(project, operation.Name, 30*time.Second)
+	return operation.Name, nm.waitForGKEOperation(project, location, operation.Name, 30*time.Second)
 }
 
-// addNetworkToGKECluster adds a network to a GKE cluster's master authorized networks
-func (nm *NetworkManager) addNetworkToGKECluster(project, location, clusterName, networkName, networkIP string) error {
+// gkeAddNetworkUpdate builds the update that adds a network to a GKE cluster, returning it
+// with the cluster's current entries
+func (nm *NetworkManager) gkeAddNetworkUpdate(project, location, clusterName, networkName, networkIP string) (*container.UpdateClusterRequest, []*container.CidrBlock, error) {
 	// Normalize the IP
 	normalizedIP, err := normalizeIP(networkIP)
 	if err != nil {
-		return fmt.Errorf("invalid IP format: %v", err)
+		return nil, nil, fmt.Errorf("invalid IP format: %v", err)
 	}
 
 	// Get current cluster configuration
 	name := fmt.Sprintf("projects/%s/locations/%s/clusters/%s", project, location, clusterName)
 	cluster, err := nm.gkeService.Projects.Locations.Clusters.Get(name).Context(nm.ctx).Do()
 	if err != nil {
-		return fmt.Errorf("failed to get cluster: %v", err)
+		return nil, nil, fmt.Errorf("failed to get cluster: %v", err)
 	}
 
-	// Initialize master authorized networks if needed
-	if cluster.MasterAuthorizedNetworksConfig == nil {
-		cluster.MasterAuthorizedNetworksConfig = &container.MasterAuthorizedNetworksConfig{
-			Enabled: true,
-		}
+	// Never enable the feature as a side effect: doing so would lock out everyone not in the list
+	if cluster.MasterAuthorizedNetworksConfig == nil || !cluster.MasterAuthorizedNetworksConfig.Enabled {
+		return nil, nil, fmt.Errorf("master authorized networks are disabled on cluster %s; enable them first", clusterName)
 	}
 
 	// Check if network already exists
-	for _, network := range cluster.MasterAuthorizedNetworksConfig.CidrBlocks {
+	current := cluster.MasterAuthorizedNetworksConfig.CidrBlocks
+	for _, network := range current {
 		if network.CidrBlock == normalizedIP {
 			if network.DisplayName == networkName {
-				return fmt.Errorf("network %s with name %s already exists", normalizedIP, networkName)
+				return nil, nil, fmt.Errorf("network %s with name %s already exists", normalizedIP, networkName)
 			} else {
-				return fmt.Errorf("network %s already exists with name %s", normalizedIP, network.DisplayName)
+				return nil, nil, fmt.Errorf("network %s already exists with name %s", normalizedIP, network.DisplayName)
 			}
 		}
 	}
@@ -579,23 +1111,239 @@ func (nm *NetworkManager) addNetworkToGKECluster(project, location, clusterName,
 	}
 
 	// Create update request
+	return &container.UpdateClusterRequest{
+		Update: &container.ClusterUpdate{
+			DesiredMasterAuthorizedNetworksConfig: &container.MasterAuthorizedNetworksConfig{
+				Enabled:                     true,
+				CidrBlocks:                  append(append([]*container.CidrBlock{}, current...), newNetwork),
+				GcpPublicCidrsAccessEnabled: cluster.MasterAuthorizedNetworksConfig.GcpPublicCidrsAccessEnabled,
+				ForceSendFields:             []string{"GcpPublicCidrsAccessEnabled"},
+			},
+		},
+	}, current, nil
+}
+
+// AddPSCConsumerProject allows a project to connect to a SQL instance through Private Service Connect
+func (nm *NetworkManager) AddPSCConsumerProject(instance SQLInstance, consumerProject string) error {
+	consumerProject = strings.TrimSpace(consumerProject)
+	if !isValidProjectID(consumerProject) {
+		return fmt.Errorf("invalid project ID or number: %q", consumerProject)
+	}
+
+	return nm.audited("add_psc_project", instance, consumerProject, func(CloudResource) (string, error) {
+		return nm.updateSQLPSCConfig(instance, func(psc *sqladmin.PscConfig) error {
+			for _, existing := range psc.AllowedConsumerProjects {
+				if existing == consumerProject {
+					return fmt.Errorf("project %s is already allowed", consumerProject)
+				}
+			}
+			psc.AllowedConsumerProjects = append(psc.AllowedConsumerProjects, consumerProject)
+			return nil
+		})
+	})
+}
+
+// RemovePSCConsumerProject stops a project from connecting through Private Service Connect
+func (nm *NetworkManager) RemovePSCConsumerProject(instance SQLInstance, consumerProject string) error {
+	consumerProject = strings.TrimSpace(consumerProject)
+
+	return nm.audited("remove_psc_project", instance, consumerProject, func(CloudResource) (string, error) {
+		return nm.updateSQLPSCConfig(instance, func(psc *sqladmin.PscConfig) error {
+			var remaining []string
+			for _, existing := range psc.AllowedConsumerProjects {
+				if existing != consumerProject {
+					remaining = append(remaining, existing)
+				}
+			}
+			if len(remaining) == len(psc.AllowedConsumerProjects) {
+				return fmt.Errorf("project %s is not in the allowed consumer projects", consumerProject)
+			}
+			psc.AllowedConsumerProjects = remaining
+			// An empty list must be sent explicitly or the API ignores it
+			psc.ForceSendFields = append(psc.ForceSendFields, "AllowedConsumerProjects")
+			return nil
+		})
+	})
+}
+
+// updateSQLPSCConfig applies a change to an instance's PSC config and waits for the patch to finish
+func (nm *NetworkManager) updateSQLPSCConfig(instance SQLInstance, change func(*sqladmin.PscConfig) error) (string, error) {
+	current, err := nm.sqlService.Instances.Get(instance.Project, instance.Name).Context(nm.ctx).Do()
+	if err != nil {
+		return "", fmt.Errorf("failed to get instance: %v", err)
+	}
+
+	if current.Settings == nil || current.Settings.IpConfiguration == nil ||
+		current.Settings.IpConfiguration.PscConfig == nil || !current.Settings.IpConfiguration.PscConfig.PscEnabled {
+		return "", fmt.Errorf("Private Service Connect is not enabled on instance %s", instance.Name)
+	}
+
+	if err := change(current.Settings.IpConfiguration.PscConfig); err != nil {
+		return "", err
+	}
+
+	updateRequest := &sqladmin.DatabaseInstance{
+		Settings: current.Settings,
+	}
+
+	operation, err := nm.sqlService.Instances.Patch(instance.Project, instance.Name, updateRequest).Context(nm.ctx).Do()
+	if err != nil {
+		return "", fmt.Errorf("failed to update instance: %v", err)
+	}
+
+	return operation.Name, nm.waitForSQLOperation(instance.Project, operation.Name, 30*time.Second)
+}
+
+// removeNetworkFromSQLInstance removes a network from a SQL instance
+func (nm *NetworkManager) removeNetworkFromSQLInstance(project, instanceName, networkIP string) (string, error) {
+	normalizedIP, err := normalizeIP(networkIP)
+	if err != nil {
+		return "", fmt.Errorf("invalid IP format: %v", err)
+	}
+
+	instance, err := nm.sqlService.Instances.Get(project, instanceName).Context(nm.ctx).Do()
+	if err != nil {
+		return "", fmt.Errorf("failed to get instance: %v", err)
+	}
+	if instance.Settings == nil || instance.Settings.IpConfiguration == nil {
+		return "", fmt.Errorf("network %s not found", normalizedIP)
+	}
+
+	// Keep every network except the one being removed
+	var remaining []*sqladmin.AclEntry
+	for _, network := range instance.Settings.IpConfiguration.AuthorizedNetworks {
+		if network.Value != normalizedIP {
+			remaining = append(remaining, network)
+		}
+	}
+	if len(remaining) == len(instance.Settings.IpConfiguration.AuthorizedNetworks) {
+		return "", fmt.Errorf("network %s not found", normalizedIP)
+	}
+
+	instance.Settings.IpConfiguration.AuthorizedNetworks = remaining
+	// An empty list must be sent explicitly or the API ignores it
+	instance.Settings.IpConfiguration.ForceSendFields = append(instance.Settings.IpConfiguration.ForceSendFields, "AuthorizedNetworks")
+
+	updateRequest := &sqladmin.DatabaseInstance{
+		Settings: instance.Settings,
+	}
+
+	operation, err := nm.sqlService.Instances.Patch(project, instanceName, updateRequest).Context(nm.ctx).Do()
+	if err != nil {
+		return "", fmt.Errorf("failed to update instance: %v", err)
+	}
+
+	return operation.Name, nm.waitForSQLOperation(project, operation.Name, 30*time.Second)
+}
+
+// removeNetworkFromGKECluster removes a network from a GKE cluster's master authorized networks
+func (nm *NetworkManager) removeNetworkFromGKECluster(project, location, clusterName, networkIP string) (string, error) {
+	normalizedIP, err := normalizeIP(networkIP)
+	if err != nil {
+		return "", fmt.Errorf("invalid IP format: %v", err)
+	}
+
+	name := fmt.Sprintf("projects/%s/locations/%s/clusters/%s", project, location, clusterName)
+	cluster, err := nm.gkeService.Projects.Locations.Clusters.Get(name).Context(nm.ctx).Do()
+	if err != nil {
+		return "", fmt.Errorf("failed to get cluster: %v", err)
+	}
+	if cluster.MasterAuthorizedNetworksConfig == nil {
+		return "", fmt.Errorf("network %s not found", normalizedIP)
+	}
+
+	// Keep every network except the one being removed
+	var remaining []*container.CidrBlock
+	for _, network := range cluster.MasterAuthorizedNetworksConfig.CidrBlocks {
+		if network.CidrBlock != normalizedIP {
+			remaining = append(remaining, network)
+		}
+	}
+	if len(remaining) == len(cluster.MasterAuthorizedNetworksConfig.CidrBlocks) {
+		return "", fmt.Errorf("network %s not found", normalizedIP)
+	}
+
 	updateRequest := &container.UpdateClusterRequest{
 		Update: &container.ClusterUpdate{
 			DesiredMasterAuthorizedNetworksConfig: &container.MasterAuthorizedNetworksConfig{
-				Enabled: true,
-				CidrBlocks: append(cluster.MasterAuthorizedNetworksConfig.CidrBlocks, newNetwork),
+				Enabled:                     cluster.MasterAuthorizedNetworksConfig.Enabled,
+				CidrBlocks:                  remaining,
+				GcpPublicCidrsAccessEnabled: cluster.MasterAuthorizedNetworksConfig.GcpPublicCidrsAccessEnabled,
+				ForceSendFields:             []string{"Enabled", "CidrBlocks", "GcpPublicCidrsAccessEnabled"},
 			},
 		},
 	}
 
-	// Update the cluster
 	operation, err := nm.gkeService.Projects.Locations.Clusters.Update(name, updateRequest).Context(nm.ctx).Do()
 	if err != nil {
-		return fmt.Errorf("failed to update cluster: %v", err)
+		return "", fmt.Errorf("failed to update cluster: %v", err)
 	}
 
-	// Wait for operation to complete (optional, could be async)
-	return nm.waitForGKEOperation(project, location, operation.Name, 30*time.Second)
+	return operation.Name, nm.waitForGKEOperation(project, location, operation.Name, 30*time.Second)
+}
+
+// SetGKEMasterAuthorizedNetworksEnabled enables or disables master authorized networks on a cluster.
+// Enabling restricts the control plane to the existing entries, which can lock people out.
+func (nm *NetworkManager) SetGKEMasterAuthorizedNetworksEnabled(cluster GKECluster, enabled bool) error {
+	return nm.audited("set_master_authorized_networks", cluster, enabledLabel(enabled), func(CloudResource) (string, error) {
+		return nm.updateGKEMasterAuthorizedNetworksConfig(cluster, func(config *container.MasterAuthorizedNetworksConfig) error {
+			if config.Enabled == enabled {
+				return fmt.Errorf("master authorized networks are already %s", enabledLabel(enabled))
+			}
+			config.Enabled = enabled
+			return nil
+		})
+	})
+}
+
+// SetGKEPublicCidrsAccessEnabled allows or blocks Google Cloud public IPs from reaching the control plane
+func (nm *NetworkManager) SetGKEPublicCidrsAccessEnabled(cluster GKECluster, enabled bool) error {
+	return nm.audited("set_gcp_public_cidrs_access", cluster, enabledLabel(enabled), func(CloudResource) (string, error) {
+		return nm.updateGKEMasterAuthorizedNetworksConfig(cluster, func(config *container.MasterAuthorizedNetworksConfig) error {
+			if !config.Enabled {
+				return fmt.Errorf("master authorized networks must be enabled to change Google Cloud public IP access")
+			}
+			if config.GcpPublicCidrsAccessEnabled == enabled {
+				return fmt.Errorf("Google Cloud public IP access is already %s", enabledLabel(enabled))
+			}
+			config.GcpPublicCidrsAccessEnabled = enabled
+			return nil
+		})
+	})
+}
+
+// updateGKEMasterAuthorizedNetworksConfig applies a change to a cluster's master authorized networks config
+func (nm *NetworkManager) updateGKEMasterAuthorizedNetworksConfig(cluster GKECluster, change func(*container.MasterAuthorizedNetworksConfig) error) (string, error) {
+	name := fmt.Sprintf("projects/%s/locations/%s/clusters/%s", cluster.Project, cluster.Location, cluster.Name)
+	current, err := nm.gkeService.Projects.Locations.Clusters.Get(name).Context(nm.ctx).Do()
+	if err != nil {
+		return "", fmt.Errorf("failed to get cluster: %v", err)
+	}
+
+	config := &container.MasterAuthorizedNetworksConfig{}
+	if current.MasterAuthorizedNetworksConfig != nil {
+		config.Enabled = current.MasterAuthorizedNetworksConfig.Enabled
+		config.CidrBlocks = current.MasterAuthorizedNetworksConfig.CidrBlocks
+		config.GcpPublicCidrsAccessEnabled = current.MasterAuthorizedNetworksConfig.GcpPublicCidrsAccessEnabled
+	}
+	if err := change(config); err != nil {
+		return "", err
+	}
+	// Booleans are omitted when false unless forced
+	config.ForceSendFields = []string{"Enabled", "GcpPublicCidrsAccessEnabled"}
+
+	updateRequest := &container.UpdateClusterRequest{
+		Update: &container.ClusterUpdate{
+			DesiredMasterAuthorizedNetworksConfig: config,
+		},
+	}
+
+	operation, err := nm.gkeService.Projects.Locations.Clusters.Update(name, updateRequest).Context(nm.ctx).Do()
+	if err != nil {
+		return "", fmt.Errorf("failed to update cluster: %v", err)
+	}
+
+	return operation.Name, nm.waitForGKEOperation(cluster.Project, cluster.Location, operation.Name, 30*time.Second)
 }
 
 // waitForSQLOperation waits for a SQL operation to complete
@@ -657,10 +1405,111 @@ func normalizeIP(ip string) (string, error) {
 		return ip, nil
 	}
 	
-	// Add /32 for single IP addresses
+	// Add /128 for single IPv6 addresses and /32 for IPv4
+	if strings.Contains(ip, ":") {
+		return ip + "/128", nil
+	}
 	return ip + "/32", nil
 }
 
+var (
+	projectIDPattern     = regexp.MustCompile(`^[a-z][a-z0-9-]{4,28}[a-z0-9]$`)
+	projectNumberPattern = regexp.MustCompile(`^[0-9]{6,20}$`)
+)
+
+// isValidProjectID reports whether a string is a GCP project ID or project number
+func isValidProjectID(project string) bool {
+	return projectIDPattern.MatchString(project) || projectNumberPattern.MatchString(project)
+}
+
+// stripProjectPrefix turns "project:instance" references into plain instance names
+func stripProjectPrefix(name string) string {
+	if i := strings.LastIndex(name, ":"); i >= 0 {
+		return name[i+1:]
+	}
+	return name
+}
+
+// relatedSQLInstances returns the primary of an instance followed by all of its replicas
+// and failover peers, as found in resources. The result always includes the instance itself.
+func relatedSQLInstances(resources []CloudResource, instance SQLInstance) []CloudResource {
+	find := func(name string) (SQLInstance, bool) {
+		for _, resource := range resources {
+			if r, ok := resource.(SQLInstance); ok && r.Project == instance.Project && r.Name == name {
+				return r, true
+			}
+		}
+		return SQLInstance{}, false
+	}
+
+	primary := instance
+	if instance.IsReplica() {
+		if p, ok := find(instance.MasterInstanceName); ok {
+			primary = p
+		}
+	}
+
+	related := []CloudResource{primary}
+	seen := map[string]bool{primary.Name: true}
+	add := func(name string) {
+		if name == "" || seen[name] {
+			return
+		}
+		seen[name] = true
+		if name == instance.Name {
+			related = append(related, instance)
+		} else if r, ok := find(name); ok {
+			related = append(related, r)
+		}
+	}
+
+	for _, name := range primary.ReplicaNames {
+		add(name)
+	}
+	add(primary.FailoverReplicaName)
+	// Replicas discovered in the list but missing from the primary's replicaNames
+	for _, resource := range resources {
+		if r, ok := resource.(SQLInstance); ok && r.Project == primary.Project && r.MasterInstanceName == primary.Name {
+			add(r.Name)
+		}
+	}
+	add(instance.Name)
+
+	return related
+}
+
+// enabledLabel formats a boolean setting for messages
+func enabledLabel(enabled bool) string {
+	if enabled {
+		return "enabled"
+	}
+	return "disabled"
+}
+
+// awsRegionCount returns the number of regions scanned by the AWS provider
+func awsRegionCount(p *awsProvider) int {
+	if p == nil {
+		return 0
+	}
+	return len(p.regions)
+}
+
+// azureListCount returns the number of list calls made by the Azure provider
+func azureListCount(p *azureProvider) int {
+	if p == nil {
+		return 0
+	}
+	return len(p.subscriptions) * 2
+}
+
+// replicationGroup returns the primary name a resource sorts under and whether it is a replica
+func replicationGroup(resource CloudResource) (string, bool) {
+	if r, ok := resource.(SQLInstance); ok && r.IsReplica() {
+		return r.MasterInstanceName, true
+	}
+	return resource.GetName(), false
+}
+
 // sortResources sorts resources by type, project, then name
 func sortResources(resources []CloudResource) {
 	sort.Slice(resources, func(i, j int) bool {
@@ -672,6 +1521,15 @@ func sortResources(resources []CloudResource) {
 		if resources[i].GetProject() != resources[j].GetProject() {
 			return resources[i].GetProject() < resources[j].GetProject()
 		}
+		// Keep SQL replicas directly below their primary
+		groupI, replicaI := replicationGroup(resources[i])
+		groupJ, replicaJ := replicationGroup(resources[j])
+		if groupI != groupJ {
+			return groupI < groupJ
+		}
+		if replicaI != replicaJ {
+			return !replicaI
+		}
 		// Finally by name
 		return resources[i].GetName() < resources[j].GetName()
 	})
@@ -0,0 +1,473 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"net"
+	"sort"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// networkSort is the order of the network table's rows
+type networkSort int
+
+const (
+	sortNetworksNone networkSort = iota // As the API returns them
+	sortNetworksName
+	sortNetworksCIDR
+)
+
+func (s networkSort) String() string {
+	switch s {
+	case sortNetworksName:
+		return "name"
+	case sortNetworksCIDR:
+		return "CIDR"
+	default:
+		return "API order"
+	}
+}
+
+// networkActions are offered on the selected entry of the network table, by the names of
+// their key bindings
+var networkActions = []struct {
+	name  string
+	label string
+}{
+	{"copy", "Copy CIDR"},
+	{"info", "Show details"},
+	{"edit", "Edit name or CIDR"},
+	{"remove", "Remove"},
+}
+
+// networkTable is the navigable entry list of the network view
+type networkTable struct {
+	cursor     int
+	offset     int // First visible row
+	sort       networkSort
+	filter     textinput.Model
+	filtering  bool // Typing into the filter
+	menu       bool // Actions menu open on the selected entry
+	menuCursor int
+	detail     bool // Details of the selected entry shown
+}
+
+// newNetworkTable creates an unfiltered network table in API order
+func newNetworkTable() networkTable {
+	filter := textinput.New()
+	filter.Prompt = "/ "
+	filter.Placeholder = "name, CIDR, port or an IP"
+	filter.CharLimit = 64
+	filter.Width = 30
+	return networkTable{filter: filter}
+}
+
+// capturesKeys reports whether the filter, menu or details are open and take every key
+func (t networkTable) capturesKeys() bool {
+	return t.filtering || t.menu || t.detail
+}
+
+// reset returns to the top of an unfiltered table, keeping the sort order
+func (t *networkTable) reset() {
+	t.cursor = 0
+	t.offset = 0
+	t.filter.SetValue("")
+	t.filter.Blur()
+	t.filtering = false
+	t.menu = false
+	t.detail = false
+}
+
+// rows returns the entries to show, filtered and sorted
+func (t networkTable) rows(networks []AuthorizedNetwork) []AuthorizedNetwork {
+	return sortNetworks(filterNetworks(networks, t.filter.Value()), t.sort)
+}
+
+// clamp keeps the cursor within count rows and the window of height rows around it
+func (t *networkTable) clamp(count, height int) {
+	t.cursor, t.offset = clampWindow(t.cursor, t.offset, count, height)
+}
+
+// clampWindow keeps a cursor within count rows and scrolls a window of height rows
+// from offset as little as possible to keep the cursor visible
+func clampWindow(cursor, offset, count, height int) (int, int) {
+	if cursor >= count {
+		cursor = count - 1
+	}
+	if cursor < 0 {
+		cursor = 0
+	}
+	if cursor < offset {
+		offset = cursor
+	}
+	if cursor >= offset+height {
+		offset = cursor - height + 1
+	}
+	if offset > count-height {
+		offset = count - height
+	}
+	if offset < 0 {
+		offset = 0
+	}
+	return cursor, offset
+}
+
+// move moves the cursor by delta rows, scrolling as needed
+func (t *networkTable) move(delta, count, height int) {
+	t.cursor += delta
+	t.clamp(count, height)
+}
+
+// status describes the visible rows, filter and sort order
+func (t networkTable) status(shown, total, offset, height int) string {
+	var parts []string
+	if shown > 0 {
+		end := offset + height
+		if end > shown {
+			end = shown
+		}
+		parts = append(parts, fmt.Sprintf("Rows %d-%d of %d", offset+1, end, shown))
+	}
+	if shown != total {
+		parts = append(parts, fmt.Sprintf("%d of %d entries match %q", shown, total, t.filter.Value()))
+	}
+	parts = append(parts, "Sorted by "+t.sort.String())
+	return strings.Join(parts, " • ")
+}
+
+// filterNetworks keeps the entries whose name, CIDR or port contains the query,
+// and when the query is an IP address, the entries whose range contains it
+func filterNetworks(networks []AuthorizedNetwork, query string) []AuthorizedNetwork {
+	query = strings.ToLower(strings.TrimSpace(query))
+	if query == "" {
+		return networks
+	}
+	ip := net.ParseIP(query)
+
+	var matches []AuthorizedNetwork
+	for _, network := range networks {
+		text := strings.ToLower(strings.Join([]string{networkName(network), network.Value, network.Port}, " "))
+		if strings.Contains(text, query) {
+			matches = append(matches, network)
+			continue
+		}
+		if ip == nil {
+			continue
+		}
+		value, _ := normalizeIP(network.Value)
+		if _, block, err := net.ParseCIDR(value); err == nil && block.Contains(ip) {
+			matches = append(matches, network)
+		}
+	}
+	return matches
+}
+
+// sortNetworks returns the entries in the given order, leaving the input untouched
+func sortNetworks(networks []AuthorizedNetwork, order networkSort) []AuthorizedNetwork {
+	if order == sortNetworksNone {
+		return networks
+	}
+	sorted := append([]AuthorizedNetwork{}, networks...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if order == sortNetworksName {
+			return strings.ToLower(networkName(sorted[i])) < strings.ToLower(networkName(sorted[j]))
+		}
+		return cidrLess(sorted[i].Value, sorted[j].Value)
+	})
+	return sorted
+}
+
+// cidrLess orders CIDRs by address and then prefix length, IPv4 before IPv6,
+// with anything unparseable last
+func cidrLess(a, b string) bool {
+	a, _ = normalizeIP(a)
+	b, _ = normalizeIP(b)
+	_, blockA, errA := net.ParseCIDR(a)
+	_, blockB, errB := net.ParseCIDR(b)
+	if errA != nil || errB != nil {
+		if errA != nil && errB != nil {
+			return a < b
+		}
+		return errB != nil
+	}
+	if len(blockA.IP) != len(blockB.IP) {
+		return len(blockA.IP) < len(blockB.IP)
+	}
+	if c := bytes.Compare(blockA.IP, blockB.IP); c != 0 {
+		return c < 0
+	}
+	onesA, _ := blockA.Mask.Size()
+	onesB, _ := blockB.Mask.Size()
+	return onesA < onesB
+}
+
+// networkTableHeight is how many rows of the network table fit below the view's header
+func (m Model) networkTableHeight() int {
+	if m.height == 0 {
+		// No window size yet
+		return 20
+	}
+	height := m.bodyHeight() - 24
+	if m.table.menu || m.table.detail {
+		height -= 10
+	}
+	if height < 5 {
+		height = 5
+	}
+	return height
+}
+
+// tableRows returns the selected resource's entries as the network table shows them
+func (m Model) tableRows() []AuthorizedNetwork {
+	if m.selectedResource == nil {
+		return nil
+	}
+	return m.table.rows(resourceNetworks(m.selectedResource))
+}
+
+// selectedNetwork returns the entry under the network table's cursor
+func (m Model) selectedNetwork() (AuthorizedNetwork, bool) {
+	rows := m.tableRows()
+	if m.table.cursor < 0 || m.table.cursor >= len(rows) {
+		return AuthorizedNetwork{}, false
+	}
+	return rows[m.table.cursor], true
+}
+
+// updateNetworkTable handles a key for the network table and whatever it has open
+func (m Model) updateNetworkTable(msg tea.KeyMsg) (Model, tea.Cmd) {
+	count, height := len(m.tableRows()), m.networkTableHeight()
+	t := &m.table
+	keys := m.keys
+
+	switch {
+	case t.detail:
+		if key.Matches(msg, keys.Back, keys.Select, keys.Info, keys.Quit) {
+			t.detail = false
+		}
+		return m, nil
+
+	case t.menu:
+		switch {
+		case key.Matches(msg, keys.Up):
+			if t.menuCursor > 0 {
+				t.menuCursor--
+			}
+		case key.Matches(msg, keys.Down):
+			if t.menuCursor < len(networkActions)-1 {
+				t.menuCursor++
+			}
+		case key.Matches(msg, keys.Back, keys.Quit):
+			t.menu = false
+		case key.Matches(msg, keys.Select):
+			return m.runNetworkAction(networkActions[t.menuCursor].name)
+		default:
+			for _, action := range networkActions {
+				if key.Matches(msg, keys.binding(action.name)) {
+					return m.runNetworkAction(action.name)
+				}
+			}
+		}
+		return m, nil
+
+	case t.filtering:
+		switch {
+		case commandKey(msg, true, keys.Select):
+			t.filtering = false
+			t.filter.Blur()
+		case commandKey(msg, true, keys.Back):
+			t.reset()
+		case commandKey(msg, true, keys.Up):
+			t.move(-1, count, height)
+		case commandKey(msg, true, keys.Down):
+			t.move(1, count, height)
+		default:
+			before := t.filter.Value()
+			var cmd tea.Cmd
+			t.filter, cmd = t.filter.Update(msg)
+			if t.filter.Value() != before {
+				t.cursor, t.offset = 0, 0
+			}
+			return m, cmd
+		}
+		return m, nil
+	}
+
+	switch {
+	case key.Matches(msg, keys.Up):
+		t.move(-1, count, height)
+	case key.Matches(msg, keys.Down):
+		t.move(1, count, height)
+	case key.Matches(msg, keys.PageUp):
+		t.move(-height, count, height)
+	case key.Matches(msg, keys.PageDown):
+		t.move(height, count, height)
+	case key.Matches(msg, keys.Home):
+		t.move(-count, count, height)
+	case key.Matches(msg, keys.End):
+		t.move(count, count, height)
+	case key.Matches(msg, keys.SortTable):
+		t.sort = (t.sort + 1) % 3
+		t.clamp(count, height)
+	case key.Matches(msg, keys.Filter):
+		if count > 0 || t.filter.Value() != "" {
+			t.filtering = true
+			return m, t.filter.Focus()
+		}
+	case key.Matches(msg, keys.Select):
+		if count > 0 {
+			t.menu = true
+			t.menuCursor = 0
+		}
+	case key.Matches(msg, keys.Copy):
+		return m.runNetworkAction("copy")
+	case key.Matches(msg, keys.Info):
+		return m.runNetworkAction("info")
+	}
+	return m, nil
+}
+
+// runNetworkAction runs one of networkActions on the selected entry
+func (m Model) runNetworkAction(name string) (Model, tea.Cmd) {
+	m.table.menu = false
+	network, ok := m.selectedNetwork()
+	if !ok {
+		return m, nil
+	}
+
+	switch name {
+	case "copy":
+		return m, copyToClipboard(network.Value)
+	case "info":
+		m.table.detail = true
+	case "edit":
+		if !m.selectedResource.CanAddNetwork() {
+			m.message = "Cannot edit networks on this resource: " + m.selectedResource.GetNetworkRestrictions()
+			m.isError = true
+			return m, nil
+		}
+		m.editing = &network
+		m.state = stateAddNetwork
+		m.addGroup = 0
+		m.applyToReplicas = false
+		m.nameInput.SetValue(networkName(network))
+		m.ipInput.SetValue(network.Value)
+		m.addFormFocus = 0
+		m.ipInput.Blur()
+		return m, m.nameInput.Focus()
+	case "remove":
+		resources := []CloudResource{m.selectedResource}
+		m.message = "Computing preview..."
+		m.isError = false
+		m.isSubmitting = true
+		return m, previewChange("➖ Remove Authorized Network", resources, stateNetworkView,
+			func(nm *NetworkManager, resource CloudResource) (*ChangePreview, error) {
+				return nm.PreviewRemoveNetwork(resource, network.Value)
+			},
+			submitRemoveNetwork(resources, network.Value))
+	}
+	return m, nil
+}
+
+// renderNetworkTable renders the visible rows of the network table with its filter,
+// position and any open menu or details
+func (m Model) renderNetworkTable(networks []AuthorizedNetwork) string {
+	rows := m.table.rows(networks)
+	height := m.networkTableHeight()
+	// The height shrinks while a menu is open, so recompute the window rather than trust the stored one
+	window := m.table
+	window.clamp(len(rows), height)
+
+	var parts []string
+	if m.table.filtering || m.table.filter.Value() != "" {
+		parts = append(parts, m.table.filter.View())
+	}
+	parts = append(parts, renderNetworkTableWindow(networks, rows, m.attribution, m.groups, window.cursor, window.offset, height))
+	if len(networks) > 0 {
+		parts = append(parts, SubtleTextStyle.Render(m.table.status(len(rows), len(networks), window.offset, height)))
+	}
+	if m.table.menu {
+		parts = append(parts, m.renderNetworkMenu())
+	}
+	if m.table.detail {
+		parts = append(parts, m.renderNetworkDetail())
+	}
+	return lipgloss.JoinVertical(lipgloss.Left, parts...)
+}
+
+// renderNetworkMenu renders the actions menu for the selected entry
+func (m Model) renderNetworkMenu() string {
+	network, ok := m.selectedNetwork()
+	if !ok {
+		return ""
+	}
+	lines := []string{LabelStyle.Render(fmt.Sprintf("%s (%s)", networkName(network), network.Value)), ""}
+	for i, action := range networkActions {
+		item := fmt.Sprintf("%s  %s", m.keys.binding(action.name).Help().Key, action.label)
+		if i == m.table.menuCursor {
+			lines = append(lines, SelectedListItemStyle.Render(item))
+		} else {
+			lines = append(lines, ListItemStyle.Render(item))
+		}
+	}
+	return FormBoxStyle.Render(lipgloss.JoinVertical(lipgloss.Left, lines...))
+}
+
+// renderNetworkDetail renders everything known about the selected entry
+func (m Model) renderNetworkDetail() string {
+	network, ok := m.selectedNetwork()
+	if !ok {
+		return ""
+	}
+	name := networkName(network)
+	if name == "" {
+		name = "(unnamed)"
+	}
+	value, _ := normalizeIP(network.Value)
+
+	lines := []string{LabelStyle.Render(name), ""}
+	field := func(label, value string) {
+		lines = append(lines, LabelStyle.Render(fmt.Sprintf("%-11s", label))+" "+value)
+	}
+	field("CIDR", value)
+	if _, block, err := net.ParseCIDR(value); err == nil {
+		first, last, size := cidrRange(block)
+		field("Range", fmt.Sprintf("%s - %s", first, last))
+		field("Addresses", size)
+	}
+	if network.Port != "" {
+		field("Port", network.Port)
+	}
+	if network.Kind != "" {
+		field("Kind", network.Kind)
+	}
+	if groups := m.groups.GroupsOf(value); len(groups) > 0 {
+		field("Groups", strings.Join(groups, ", "))
+	}
+	if a, ok := lookupAttribution(m.attribution, network); ok {
+		field("Added by", a.AddedBy())
+		field("Added at", a.AddedAt("2006-01-02 15:04:05"))
+	} else if m.attribution != nil {
+		field("Added by", "unknown")
+	}
+	return FormBoxStyle.Render(lipgloss.JoinVertical(lipgloss.Left, lines...))
+}
+
+// cidrRange returns a block's first and last addresses and how many addresses it holds
+func cidrRange(block *net.IPNet) (net.IP, net.IP, string) {
+	last := make(net.IP, len(block.IP))
+	for i := range block.IP {
+		last[i] = block.IP[i] | ^block.Mask[i]
+	}
+	ones, bits := block.Mask.Size()
+	size := fmt.Sprintf("2^%d", bits-ones)
+	if bits-ones < 63 {
+		size = fmt.Sprintf("%d", uint64(1)<<uint(bits-ones))
+	}
+	return block.IP, last, size
+}
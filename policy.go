@@ -0,0 +1,253 @@
+package main
+
+import (
+	"fmt"
+	"net"
+	"regexp"
+	"strings"
+)
+
+// PolicyRules are the checks applied to every network added through piam-anc.
+// Pointer and slice fields left unset in an override inherit the base value.
+type PolicyRules struct {
+	MinPrefixLength   *int     `json:"min_prefix_length,omitempty"`    // IPv4, e.g. 24 forbids anything broader than /24
+	MinPrefixLengthV6 *int     `json:"min_prefix_length_v6,omitempty"` // IPv6
+	ForbiddenRanges   []string `json:"forbidden_ranges,omitempty"`     // Entries may not overlap these
+	RequiredRanges    []string `json:"required_ranges,omitempty"`      // Entries must fall inside one of these
+	NameRegex         *string  `json:"name_regex,omitempty"`           // Entry names must match
+	MaxEntries        *int     `json:"max_entries,omitempty"`          // Per resource
+}
+
+// PolicyOverride replaces rules for resources in the given projects or with all the given labels
+type PolicyOverride struct {
+	Projects []string          `json:"projects,omitempty"`
+	Labels   map[string]string `json:"labels,omitempty"`
+	PolicyRules
+}
+
+// PolicyConfig is the "policy" section of the config file
+type PolicyConfig struct {
+	PolicyRules
+	Overrides []PolicyOverride `json:"overrides,omitempty"`
+}
+
+// PolicyViolation describes one rule an entry breaks
+type PolicyViolation struct {
+	Rule    string
+	Message string
+}
+
+func (v PolicyViolation) String() string {
+	return fmt.Sprintf("[%s] %s", v.Rule, v.Message)
+}
+
+// PolicyError is returned when a change is rejected by policy
+type PolicyError struct {
+	Violations []PolicyViolation
+}
+
+func (e *PolicyError) Error() string {
+	messages := make([]string, len(e.Violations))
+	for i, v := range e.Violations {
+		messages[i] = v.String()
+	}
+	return "policy violation: " + strings.Join(messages, "; ")
+}
+
+// Policy evaluates proposed networks against the configured rules
+type Policy struct {
+	config PolicyConfig
+}
+
+// NewPolicy validates a policy config and builds a Policy from it
+func NewPolicy(config PolicyConfig) (*Policy, error) {
+	all := append([]PolicyRules{config.PolicyRules}, overrideRules(config.Overrides)...)
+	for _, rules := range all {
+		for _, cidr := range append(append([]string{}, rules.ForbiddenRanges...), rules.RequiredRanges...) {
+			if _, _, err := net.ParseCIDR(cidr); err != nil {
+				return nil, fmt.Errorf("invalid policy range %q: %v", cidr, err)
+			}
+		}
+		if rules.NameRegex != nil {
+			if _, err := regexp.Compile(*rules.NameRegex); err != nil {
+				return nil, fmt.Errorf("invalid policy name_regex %q: %v", *rules.NameRegex, err)
+			}
+		}
+	}
+	return &Policy{config: config}, nil
+}
+
+// overrideRules extracts the rules of each override
+func overrideRules(overrides []PolicyOverride) []PolicyRules {
+	rules := make([]PolicyRules, len(overrides))
+	for i, o := range overrides {
+		rules[i] = o.PolicyRules
+	}
+	return rules
+}
+
+// RulesFor returns the effective rules for a resource, applying matching overrides in order
+func (p *Policy) RulesFor(resource CloudResource) PolicyRules {
+	rules := p.config.PolicyRules
+	for _, override := range p.config.Overrides {
+		if !override.matches(resource) {
+			continue
+		}
+		if override.MinPrefixLength != nil {
+			rules.MinPrefixLength = override.MinPrefixLength
+		}
+		if override.MinPrefixLengthV6 != nil {
+			rules.MinPrefixLengthV6 = override.MinPrefixLengthV6
+		}
+		if override.ForbiddenRanges != nil {
+			rules.ForbiddenRanges = override.ForbiddenRanges
+		}
+		if override.RequiredRanges != nil {
+			rules.RequiredRanges = override.RequiredRanges
+		}
+		if override.NameRegex != nil {
+			rules.NameRegex = override.NameRegex
+		}
+		if override.MaxEntries != nil {
+			rules.MaxEntries = override.MaxEntries
+		}
+	}
+	return rules
+}
+
+// matches reports whether an override applies to a resource
+func (o PolicyOverride) matches(resource CloudResource) bool {
+	if len(o.Projects) == 0 && len(o.Labels) == 0 {
+		return false
+	}
+
+	if len(o.Projects) > 0 {
+		found := false
+		for _, project := range o.Projects {
+			if project == resource.GetProject() {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+
+	labels := resource.GetLabels()
+	for key, value := range o.Labels {
+		if labels[key] != value {
+			return false
+		}
+	}
+	return true
+}
+
+// Evaluate checks a proposed network against the rules for a resource.
+// existing is the resource's current list, used for the entry limit.
+func (p *Policy) Evaluate(resource CloudResource, name, cidr string, existing []AuthorizedNetwork) []PolicyViolation {
+	if p == nil {
+		return nil
+	}
+	rules := p.RulesFor(resource)
+
+	var violations []PolicyViolation
+
+	if rules.NameRegex != nil {
+		if re := regexp.MustCompile(*rules.NameRegex); !re.MatchString(name) {
+			violations = append(violations, PolicyViolation{
+				Rule:    "name_regex",
+				Message: fmt.Sprintf("name %q does not match %s", name, *rules.NameRegex),
+			})
+		}
+	}
+
+	if rules.MaxEntries != nil && countUniqueNetworks(existing)+1 > *rules.MaxEntries {
+		violations = append(violations, PolicyViolation{
+			Rule:    "max_entries",
+			Message: fmt.Sprintf("resource already has %d of %d allowed entries", countUniqueNetworks(existing), *rules.MaxEntries),
+		})
+	}
+
+	normalized, _ := normalizeIP(strings.TrimSpace(cidr))
+	_, block, err := net.ParseCIDR(normalized)
+	if err != nil {
+		violations = append(violations, PolicyViolation{
+			Rule:    "cidr",
+			Message: fmt.Sprintf("%q is not a valid IP address or CIDR block", cidr),
+		})
+		return violations
+	}
+	prefix, bits := block.Mask.Size()
+
+	if bits == 32 && rules.MinPrefixLength != nil && prefix < *rules.MinPrefixLength {
+		violations = append(violations, PolicyViolation{
+			Rule:    "min_prefix_length",
+			Message: fmt.Sprintf("/%d is broader than the minimum /%d", prefix, *rules.MinPrefixLength),
+		})
+	}
+	if bits == 128 && rules.MinPrefixLengthV6 != nil && prefix < *rules.MinPrefixLengthV6 {
+		violations = append(violations, PolicyViolation{
+			Rule:    "min_prefix_length_v6",
+			Message: fmt.Sprintf("/%d is broader than the minimum /%d", prefix, *rules.MinPrefixLengthV6),
+		})
+	}
+
+	for _, forbidden := range rules.ForbiddenRanges {
+		_, forbiddenBlock, _ := net.ParseCIDR(forbidden)
+		if cidrsOverlap(block, forbiddenBlock) {
+			violations = append(violations, PolicyViolation{
+				Rule:    "forbidden_ranges",
+				Message: fmt.Sprintf("%s overlaps forbidden range %s", block, forbidden),
+			})
+		}
+	}
+
+	if len(rules.RequiredRanges) > 0 {
+		inside := false
+		for _, required := range rules.RequiredRanges {
+			_, requiredBlock, _ := net.ParseCIDR(required)
+			if cidrContains(requiredBlock, block) {
+				inside = true
+				break
+			}
+		}
+		if !inside {
+			violations = append(violations, PolicyViolation{
+				Rule:    "required_ranges",
+				Message: fmt.Sprintf("%s is not inside any of %s", block, strings.Join(rules.RequiredRanges, ", ")),
+			})
+		}
+	}
+
+	return violations
+}
+
+// Check evaluates a proposed network and returns a *PolicyError on violations
+func (p *Policy) Check(resource CloudResource, name, cidr string, existing []AuthorizedNetwork) error {
+	if violations := p.Evaluate(resource, name, cidr, existing); len(violations) > 0 {
+		return &PolicyError{Violations: violations}
+	}
+	return nil
+}
+
+// countUniqueNetworks counts distinct CIDRs (AWS lists one entry per port)
+func countUniqueNetworks(networks []AuthorizedNetwork) int {
+	seen := make(map[string]bool)
+	for _, network := range networks {
+		seen[network.Value] = true
+	}
+	return len(seen)
+}
+
+// cidrsOverlap reports whether two blocks share any address
+func cidrsOverlap(a, b *net.IPNet) bool {
+	return a.Contains(b.IP) || b.Contains(a.IP)
+}
+
+// cidrContains reports whether outer fully contains inner
+func cidrContains(outer, inner *net.IPNet) bool {
+	outerPrefix, outerBits := outer.Mask.Size()
+	innerPrefix, innerBits := inner.Mask.Size()
+	return outerBits == innerBits && outerPrefix <= innerPrefix && outer.Contains(inner.IP)
+}
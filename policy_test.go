@@ -0,0 +1,229 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func intPtr(n int) *int          { return &n }
+func stringPtr(s string) *string { return &s }
+
+func TestPolicyEvaluate(t *testing.T) {
+	instance := SQLInstance{Name: "db", Project: "prod-project", PublicIPEnabled: true, Labels: map[string]string{"env": "dev"}}
+
+	tests := []struct {
+		name     string
+		config   PolicyConfig
+		resource CloudResource
+		entry    string
+		cidr     string
+		existing []AuthorizedNetwork
+		want     []string // Rules violated, in order
+	}{
+		{
+			name:  "no rules",
+			entry: "office",
+			cidr:  "0.0.0.0/0",
+		},
+		{
+			name:   "prefix within minimum",
+			config: PolicyConfig{PolicyRules: PolicyRules{MinPrefixLength: intPtr(24)}},
+			entry:  "office",
+			cidr:   "203.0.113.0/24",
+		},
+		{
+			name:   "prefix broader than minimum",
+			config: PolicyConfig{PolicyRules: PolicyRules{MinPrefixLength: intPtr(24)}},
+			entry:  "office",
+			cidr:   "203.0.112.0/23",
+			want:   []string{"min_prefix_length"},
+		},
+		{
+			name:   "single IP gets /32",
+			config: PolicyConfig{PolicyRules: PolicyRules{MinPrefixLength: intPtr(32)}},
+			entry:  "laptop",
+			cidr:   "203.0.113.7",
+		},
+		{
+			name:   "IPv4 minimum ignores IPv6",
+			config: PolicyConfig{PolicyRules: PolicyRules{MinPrefixLength: intPtr(24)}},
+			entry:  "office",
+			cidr:   "2001:db8::/32",
+		},
+		{
+			name:   "IPv6 prefix broader than minimum",
+			config: PolicyConfig{PolicyRules: PolicyRules{MinPrefixLengthV6: intPtr(48)}},
+			entry:  "office",
+			cidr:   "2001:db8::/32",
+			want:   []string{"min_prefix_length_v6"},
+		},
+		{
+			name:   "single IPv6 address gets /128",
+			config: PolicyConfig{PolicyRules: PolicyRules{MinPrefixLengthV6: intPtr(128)}},
+			entry:  "laptop",
+			cidr:   "2001:db8::7",
+		},
+		{
+			name:   "overlaps forbidden range",
+			config: PolicyConfig{PolicyRules: PolicyRules{ForbiddenRanges: []string{"10.0.0.0/8"}}},
+			entry:  "vpn",
+			cidr:   "10.1.2.0/24",
+			want:   []string{"forbidden_ranges"},
+		},
+		{
+			name:   "contains forbidden range",
+			config: PolicyConfig{PolicyRules: PolicyRules{ForbiddenRanges: []string{"10.0.0.0/8"}}},
+			entry:  "anywhere",
+			cidr:   "0.0.0.0/0",
+			want:   []string{"forbidden_ranges"},
+		},
+		{
+			name:   "inside required range",
+			config: PolicyConfig{PolicyRules: PolicyRules{RequiredRanges: []string{"203.0.113.0/24"}}},
+			entry:  "office",
+			cidr:   "203.0.113.128/25",
+		},
+		{
+			name:   "outside required range",
+			config: PolicyConfig{PolicyRules: PolicyRules{RequiredRanges: []string{"203.0.113.0/24"}}},
+			entry:  "office",
+			cidr:   "198.51.100.0/24",
+			want:   []string{"required_ranges"},
+		},
+		{
+			name:   "name matches",
+			config: PolicyConfig{PolicyRules: PolicyRules{NameRegex: stringPtr(`^[a-z]+-[a-z]+$`)}},
+			entry:  "alice-laptop",
+			cidr:   "203.0.113.7/32",
+		},
+		{
+			name:   "name does not match",
+			config: PolicyConfig{PolicyRules: PolicyRules{NameRegex: stringPtr(`^[a-z]+-[a-z]+$`)}},
+			entry:  "Laptop",
+			cidr:   "203.0.113.7/32",
+			want:   []string{"name_regex"},
+		},
+		{
+			name:     "below entry limit",
+			config:   PolicyConfig{PolicyRules: PolicyRules{MaxEntries: intPtr(2)}},
+			entry:    "office",
+			cidr:     "203.0.113.7/32",
+			existing: []AuthorizedNetwork{{Name: "vpn", Value: "198.51.100.0/24"}},
+		},
+		{
+			name:   "at entry limit",
+			config: PolicyConfig{PolicyRules: PolicyRules{MaxEntries: intPtr(2)}},
+			entry:  "office",
+			cidr:   "203.0.113.7/32",
+			existing: []AuthorizedNetwork{
+				{Name: "vpn", Value: "198.51.100.0/24"},
+				{Name: "home", Value: "192.0.2.1/32"},
+			},
+			want: []string{"max_entries"},
+		},
+		{
+			name:   "entries repeated per port count once",
+			config: PolicyConfig{PolicyRules: PolicyRules{MaxEntries: intPtr(2)}},
+			entry:  "office",
+			cidr:   "203.0.113.7/32",
+			existing: []AuthorizedNetwork{
+				{Name: "vpn", Value: "198.51.100.0/24", Port: "5432"},
+				{Name: "vpn", Value: "198.51.100.0/24", Port: "6432"},
+			},
+		},
+		{
+			name:   "invalid CIDR",
+			config: PolicyConfig{PolicyRules: PolicyRules{MinPrefixLength: intPtr(24)}},
+			entry:  "office",
+			cidr:   "not-an-ip",
+			want:   []string{"cidr"},
+		},
+		{
+			name: "several violations",
+			config: PolicyConfig{PolicyRules: PolicyRules{
+				MinPrefixLength: intPtr(24),
+				ForbiddenRanges: []string{"10.0.0.0/8"},
+				NameRegex:       stringPtr(`^[a-z-]+$`),
+			}},
+			entry: "VPN",
+			cidr:  "10.0.0.0/16",
+			want:  []string{"name_regex", "min_prefix_length", "forbidden_ranges"},
+		},
+		{
+			name: "override for the project relaxes the minimum",
+			config: PolicyConfig{
+				PolicyRules: PolicyRules{MinPrefixLength: intPtr(32)},
+				Overrides:   []PolicyOverride{{Projects: []string{"prod-project"}, PolicyRules: PolicyRules{MinPrefixLength: intPtr(16)}}},
+			},
+			entry: "office",
+			cidr:  "203.0.0.0/16",
+		},
+		{
+			name: "override for other labels does not apply",
+			config: PolicyConfig{
+				PolicyRules: PolicyRules{MinPrefixLength: intPtr(32)},
+				Overrides:   []PolicyOverride{{Labels: map[string]string{"env": "prod"}, PolicyRules: PolicyRules{MinPrefixLength: intPtr(16)}}},
+			},
+			entry: "office",
+			cidr:  "203.0.0.0/16",
+			want:  []string{"min_prefix_length"},
+		},
+		{
+			name: "override inherits unset rules",
+			config: PolicyConfig{
+				PolicyRules: PolicyRules{MinPrefixLength: intPtr(32), ForbiddenRanges: []string{"10.0.0.0/8"}},
+				Overrides:   []PolicyOverride{{Labels: map[string]string{"env": "dev"}, PolicyRules: PolicyRules{MinPrefixLength: intPtr(8)}}},
+			},
+			entry: "vpn",
+			cidr:  "10.0.0.0/8",
+			want:  []string{"forbidden_ranges"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			policy, err := NewPolicy(tt.config)
+			if err != nil {
+				t.Fatalf("NewPolicy: %v", err)
+			}
+			resource := tt.resource
+			if resource == nil {
+				resource = instance
+			}
+
+			var got []string
+			for _, violation := range policy.Evaluate(resource, tt.entry, tt.cidr, tt.existing) {
+				got = append(got, violation.Rule)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Evaluate(%q, %q) violated %v, want %v", tt.entry, tt.cidr, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPolicyEvaluateNil(t *testing.T) {
+	var policy *Policy
+	if violations := policy.Evaluate(SQLInstance{}, "anywhere", "0.0.0.0/0", nil); violations != nil {
+		t.Errorf("nil policy returned %v", violations)
+	}
+}
+
+func TestNewPolicyInvalid(t *testing.T) {
+	tests := []struct {
+		name   string
+		config PolicyConfig
+	}{
+		{"bad forbidden range", PolicyConfig{PolicyRules: PolicyRules{ForbiddenRanges: []string{"10.0.0.0"}}}},
+		{"bad required range", PolicyConfig{PolicyRules: PolicyRules{RequiredRanges: []string{"nope"}}}},
+		{"bad name regex", PolicyConfig{PolicyRules: PolicyRules{NameRegex: stringPtr("(")}}},
+		{"bad override", PolicyConfig{Overrides: []PolicyOverride{{Projects: []string{"p"}, PolicyRules: PolicyRules{ForbiddenRanges: []string{"x"}}}}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := NewPolicy(tt.config); err == nil {
+				t.Error("NewPolicy succeeded, want an error")
+			}
+		})
+	}
+}
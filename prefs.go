@@ -0,0 +1,49 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// Prefs are the TUI choices remembered between sessions. Unlike the config file,
+// piam-anc writes this file itself.
+type Prefs struct {
+	ResourceTab ResourceType `json:"resource_tab,omitempty"` // Empty for all types
+	GroupBy     string       `json:"group_by,omitempty"`     // "project", "region" or empty
+	SortBy      string       `json:"sort_by,omitempty"`      // "name", "project", "networks", "state" or empty for type
+}
+
+// prefsPath returns the path of the preferences file
+func prefsPath() string {
+	return filepath.Join(configDir(), "prefs.json")
+}
+
+// LoadPrefs reads the saved preferences, returning defaults when there are none or they can't be read
+func LoadPrefs() Prefs {
+	var prefs Prefs
+	data, err := os.ReadFile(prefsPath())
+	if err != nil {
+		return Prefs{}
+	}
+	if err := json.Unmarshal(data, &prefs); err != nil {
+		return Prefs{}
+	}
+	return prefs
+}
+
+// Save writes the preferences
+func (p Prefs) Save() error {
+	data, err := json.MarshalIndent(p, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode preferences: %v", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(prefsPath()), 0o700); err != nil {
+		return fmt.Errorf("failed to create config directory: %v", err)
+	}
+	if err := os.WriteFile(prefsPath(), data, 0o600); err != nil {
+		return fmt.Errorf("failed to write preferences: %v", err)
+	}
+	return nil
+}
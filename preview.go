@@ -0,0 +1,153 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+
+	"google.golang.org/api/container/v1"
+	"google.golang.org/api/sqladmin/v1beta4"
+)
+
+// dryRunMode is set by the global --dry-run flag; NetworkManagers created while it is set never send changes
+var dryRunMode bool
+
+// dryRunNotice is printed where a command stops instead of making changes
+const dryRunNotice = "Dry run: no changes were sent."
+
+// errDryRun is returned by every mutation of a NetworkManager in dry-run mode
+var errDryRun = errors.New("dry run: no change was sent")
+
+// ChangePreview is the full network list a change would send to a resource
+type ChangePreview struct {
+	Resource CloudResource
+	Before   []AuthorizedNetwork
+	After    []AuthorizedNetwork
+}
+
+// Diff returns what the change adds, removes and renames
+func (p ChangePreview) Diff() NetworkDiff {
+	return diffNetworks(p.Before, p.After)
+}
+
+// PreviewAddNetwork returns the list AddNetworkToResource would send. For Cloud SQL and GKE
+// it is built by the same code as the real request.
+func (nm *NetworkManager) PreviewAddNetwork(resource CloudResource, networkName, networkIP string) (*ChangePreview, error) {
+	if err := nm.policy.Check(resource, networkName, networkIP, resourceNetworks(resource)); err != nil {
+		return nil, err
+	}
+
+	switch r := resource.(type) {
+	case SQLInstance:
+		if !r.PublicIPEnabled {
+			return nil, fmt.Errorf("cannot add network to SQL instance without public IP")
+		}
+		patch, current, err := nm.sqlAddNetworkPatch(r.Project, r.Name, networkName, networkIP)
+		if err != nil {
+			return nil, err
+		}
+		return &ChangePreview{
+			Resource: resource,
+			Before:   aclEntryNetworks(current),
+			After:    aclEntryNetworks(patch.Settings.IpConfiguration.AuthorizedNetworks),
+		}, nil
+	case GKECluster:
+		update, current, err := nm.gkeAddNetworkUpdate(r.Project, r.Location, r.Name, networkName, networkIP)
+		if err != nil {
+			return nil, err
+		}
+		return &ChangePreview{
+			Resource: resource,
+			Before:   cidrBlockNetworks(current),
+			After:    cidrBlockNetworks(update.Update.DesiredMasterAuthorizedNetworksConfig.CidrBlocks),
+		}, nil
+	}
+
+	// AWS and Azure add a single rule rather than sending a list
+	current, err := nm.GetResourceDetails(resource)
+	if err != nil {
+		return nil, err
+	}
+	normalizedIP, err := normalizeIP(networkIP)
+	if err != nil {
+		return nil, fmt.Errorf("invalid IP format: %v", err)
+	}
+	before := resourceNetworks(current)
+	for _, network := range before {
+		if value, _ := normalizeIP(network.Value); value == normalizedIP {
+			return nil, fmt.Errorf("network %s already exists with name %s", normalizedIP, network.Name)
+		}
+	}
+	after := append(append([]AuthorizedNetwork{}, before...), AuthorizedNetwork{Name: networkName, Value: normalizedIP})
+	return &ChangePreview{Resource: current, Before: before, After: after}, nil
+}
+
+// PreviewRemoveNetwork returns the list RemoveNetworkFromResource would leave
+func (nm *NetworkManager) PreviewRemoveNetwork(resource CloudResource, networkIP string) (*ChangePreview, error) {
+	normalizedIP, err := normalizeIP(networkIP)
+	if err != nil {
+		return nil, fmt.Errorf("invalid IP format: %v", err)
+	}
+	current, err := nm.GetResourceDetails(resource)
+	if err != nil {
+		return nil, err
+	}
+
+	before := resourceNetworks(current)
+	var after []AuthorizedNetwork
+	for _, network := range before {
+		if value, _ := normalizeIP(network.Value); value != normalizedIP {
+			after = append(after, network)
+		}
+	}
+	if len(after) == len(before) {
+		return nil, fmt.Errorf("network %s not found", normalizedIP)
+	}
+	return &ChangePreview{Resource: current, Before: before, After: after}, nil
+}
+
+// PreviewAddGroup returns the list AddGroupToResource would send
+func (nm *NetworkManager) PreviewAddGroup(resource CloudResource, group NetworkGroup) (*ChangePreview, error) {
+	current, err := nm.GetResourceDetails(resource)
+	if err != nil {
+		return nil, err
+	}
+	desired, _, err := groupNetworksToAdd(current, group, nm.policy)
+	if err != nil {
+		return nil, err
+	}
+	return &ChangePreview{Resource: current, Before: resourceNetworks(current), After: desired}, nil
+}
+
+// PreviewEditNetwork returns the list EditNetwork would send
+func (nm *NetworkManager) PreviewEditNetwork(resource CloudResource, oldIP, name, ip string) (*ChangePreview, error) {
+	current, err := nm.GetResourceDetails(resource)
+	if err != nil {
+		return nil, err
+	}
+	desired, diff, err := editedNetworks(current, oldIP, name, ip, nm.policy)
+	if err != nil {
+		return nil, err
+	}
+	if diff.Empty() {
+		return nil, fmt.Errorf("nothing to change")
+	}
+	return &ChangePreview{Resource: current, Before: resourceNetworks(current), After: desired}, nil
+}
+
+// aclEntryNetworks converts Cloud SQL ACL entries to AuthorizedNetworks
+func aclEntryNetworks(entries []*sqladmin.AclEntry) []AuthorizedNetwork {
+	networks := make([]AuthorizedNetwork, 0, len(entries))
+	for _, entry := range entries {
+		networks = append(networks, AuthorizedNetwork{Kind: entry.Kind, Name: entry.Name, Value: entry.Value})
+	}
+	return networks
+}
+
+// cidrBlockNetworks converts GKE CIDR blocks to AuthorizedNetworks
+func cidrBlockNetworks(blocks []*container.CidrBlock) []AuthorizedNetwork {
+	networks := make([]AuthorizedNetwork, 0, len(blocks))
+	for _, block := range blocks {
+		networks = append(networks, AuthorizedNetwork{Name: block.DisplayName, DisplayName: block.DisplayName, Value: block.CidrBlock})
+	}
+	return networks
+}
@@ -0,0 +1,400 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/list"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// Sort modes of the resource list
+const (
+	sortResourcesType     = "" // By type, project and name, as discovered
+	sortResourcesName     = "name"
+	sortResourcesProject  = "project"
+	sortResourcesNetworks = "networks"
+	sortResourcesState    = "state"
+)
+
+var resourceSorts = []string{sortResourcesType, sortResourcesName, sortResourcesProject, sortResourcesNetworks, sortResourcesState}
+
+// Groupings of the resource list
+const (
+	groupResourcesNone    = ""
+	groupResourcesProject = "project"
+	groupResourcesRegion  = "region"
+)
+
+var resourceGroupings = []string{groupResourcesNone, groupResourcesProject, groupResourcesRegion}
+
+// resourceTypeOrder is the order of the resource list's tabs; types not listed follow alphabetically
+var resourceTypeOrder = []ResourceType{ResourceTypeSQL, ResourceTypeGKE, ResourceTypeAWS, ResourceTypeAzure}
+
+// nextChoice returns the choice after current, wrapping around
+func nextChoice(choices []string, current string) string {
+	for i, choice := range choices {
+		if choice == current {
+			return choices[(i+1)%len(choices)]
+		}
+	}
+	return choices[0]
+}
+
+// sortLabel describes a sort mode
+func sortLabel(mode string) string {
+	switch mode {
+	case sortResourcesType:
+		return "type"
+	case sortResourcesNetworks:
+		return "network count"
+	default:
+		return mode
+	}
+}
+
+// resourceTypeLabel names a resource list tab
+func resourceTypeLabel(resourceType ResourceType) string {
+	switch resourceType {
+	case "":
+		return "All"
+	case ResourceTypeAzure:
+		return "Azure"
+	default:
+		return string(resourceType)
+	}
+}
+
+// resourceTabs returns the resource list's tabs: all types, then each type that was discovered
+func resourceTabs(resources []CloudResource) []ResourceType {
+	present := make(map[ResourceType]bool)
+	for _, resource := range resources {
+		present[resource.GetType()] = true
+	}
+
+	tabs := []ResourceType{""}
+	for _, resourceType := range resourceTypeOrder {
+		if present[resourceType] {
+			tabs = append(tabs, resourceType)
+			delete(present, resourceType)
+		}
+	}
+	var others []ResourceType
+	for resourceType := range present {
+		others = append(others, resourceType)
+	}
+	sort.Slice(others, func(i, j int) bool { return others[i] < others[j] })
+	return append(tabs, others...)
+}
+
+// resourceState returns a resource's lifecycle state as its API reports it, or "" when it has none
+func resourceState(resource CloudResource) string {
+	switch r := resource.(type) {
+	case SQLInstance:
+		return r.State
+	case GKECluster:
+		return r.State
+	case AzureDatabaseServer:
+		return r.State
+	default:
+		return ""
+	}
+}
+
+// orderResources sorts resources for the list, keeping SQL replicas directly below their primary
+func orderResources(resources []CloudResource, mode string) {
+	if mode == sortResourcesType {
+		sortResources(resources)
+		return
+	}
+
+	familyKey := func(resource CloudResource, name string) string {
+		return fmt.Sprintf("%s/%s/%s", resource.GetType(), resource.GetProject(), name)
+	}
+	primaries := make(map[string]CloudResource)
+	for _, resource := range resources {
+		if _, isReplica := replicationGroup(resource); !isReplica {
+			primaries[familyKey(resource, resource.GetName())] = resource
+		}
+	}
+	head := func(resource CloudResource) CloudResource {
+		if group, isReplica := replicationGroup(resource); isReplica {
+			if primary, ok := primaries[familyKey(resource, group)]; ok {
+				return primary
+			}
+		}
+		return resource
+	}
+
+	sort.SliceStable(resources, func(i, j int) bool {
+		headI, headJ := head(resources[i]), head(resources[j])
+		if resourceKey(headI) != resourceKey(headJ) {
+			return resourceLess(headI, headJ, mode)
+		}
+		_, replicaI := replicationGroup(resources[i])
+		_, replicaJ := replicationGroup(resources[j])
+		if replicaI != replicaJ {
+			return !replicaI
+		}
+		return resources[i].GetName() < resources[j].GetName()
+	})
+}
+
+// resourceLess compares two resources by a sort mode, breaking ties by project and name
+func resourceLess(a, b CloudResource, mode string) bool {
+	switch mode {
+	case sortResourcesName:
+		if x, y := strings.ToLower(a.GetName()), strings.ToLower(b.GetName()); x != y {
+			return x < y
+		}
+	case sortResourcesNetworks:
+		// Most entries first
+		if x, y := len(resourceNetworks(a)), len(resourceNetworks(b)); x != y {
+			return x > y
+		}
+	case sortResourcesState:
+		if x, y := resourceState(a), resourceState(b); x != y {
+			return x < y
+		}
+	}
+	if a.GetProject() != b.GetProject() {
+		return a.GetProject() < b.GetProject()
+	}
+	if a.GetName() != b.GetName() {
+		return a.GetName() < b.GetName()
+	}
+	return resourceKey(a) < resourceKey(b)
+}
+
+// groupKey returns the group a resource is listed under
+func groupKey(resource CloudResource, grouping string) string {
+	switch grouping {
+	case groupResourcesProject:
+		return resource.GetProject()
+	case groupResourcesRegion:
+		return resource.GetRegion()
+	default:
+		return ""
+	}
+}
+
+// groupHeaderItem heads a project or region group in the resource list; Enter collapses or expands it
+type groupHeaderItem struct {
+	key       string // Collapse key: grouping and group name
+	name      string
+	count     int
+	collapsed bool
+}
+
+// Headers never match a search, so they drop out of filtered results
+func (h groupHeaderItem) FilterValue() string { return "" }
+
+func (h groupHeaderItem) Title() string {
+	if h.collapsed {
+		return "▸ " + h.name
+	}
+	return "▾ " + h.name
+}
+
+func (h groupHeaderItem) Description() string {
+	desc := fmt.Sprintf("%d resources", h.count)
+	if h.count == 1 {
+		desc = "1 resource"
+	}
+	if h.collapsed {
+		desc += " (collapsed - Enter to expand)"
+	}
+	return desc
+}
+
+// buildResourceItems returns the resource list's items for a tab, grouping and sort mode.
+// Without headers (while searching) every resource of the tab is listed, collapsed or not.
+func buildResourceItems(resources []CloudResource, tab ResourceType, grouping, mode string, collapsed map[string]bool, headers bool) []list.Item {
+	var shown []CloudResource
+	for _, resource := range resources {
+		if tab == "" || resource.GetType() == tab {
+			shown = append(shown, resource)
+		}
+	}
+	orderResources(shown, mode)
+
+	items := make([]list.Item, 0, len(shown))
+	if grouping == groupResourcesNone || !headers {
+		for _, resource := range shown {
+			items = append(items, resourceItem{resource: resource})
+		}
+		return items
+	}
+
+	// Groups in name order, each keeping the sort order of its members
+	members := make(map[string][]CloudResource)
+	var names []string
+	for _, resource := range shown {
+		name := groupKey(resource, grouping)
+		if _, seen := members[name]; !seen {
+			names = append(names, name)
+		}
+		members[name] = append(members[name], resource)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		key := grouping + ":" + name
+		items = append(items, groupHeaderItem{key: key, name: name, count: len(members[name]), collapsed: collapsed[key]})
+		if collapsed[key] {
+			continue
+		}
+		for _, resource := range members[name] {
+			items = append(items, resourceItem{resource: resource})
+		}
+	}
+	return items
+}
+
+// itemKey identifies a resource list item across rebuilds
+func itemKey(item list.Item) string {
+	switch i := item.(type) {
+	case resourceItem:
+		return resourceKey(i.resource)
+	case groupHeaderItem:
+		return i.key
+	default:
+		return ""
+	}
+}
+
+// refreshResourceList rebuilds the resource list for the current tab, grouping and sort,
+// keeping the cursor on the same item
+func (m *Model) refreshResourceList() tea.Cmd {
+	tabs := resourceTabs(m.resources)
+	known := false
+	for _, tab := range tabs {
+		known = known || tab == m.activeTab
+	}
+	if !known && len(m.resources) > 0 {
+		// The remembered tab's type wasn't discovered this time
+		m.activeTab = ""
+	}
+
+	selected := itemKey(m.resourceList.SelectedItem())
+	headers := m.resourceList.FilterState() == list.Unfiltered
+	items := buildResourceItems(m.resources, m.activeTab, m.groupBy, m.sortBy, m.collapsed, headers)
+	m.resourceList.Filter = filterResourceItems(items)
+	cmd := m.resourceList.SetItems(items)
+	if headers {
+		for i, item := range items {
+			if itemKey(item) == selected {
+				m.resourceList.Select(i)
+				break
+			}
+		}
+	}
+	return cmd
+}
+
+// filterResourceItems returns the resource list's filter function: the query's structured terms
+// (type:sql networks>20 ...) narrow the list down and the remaining text is fuzzy matched.
+// Filter functions only see each item's FilterValue, so they're built per set of items.
+func filterResourceItems(items []list.Item) list.FilterFunc {
+	return func(term string, targets []string) []list.Rank {
+		query, _ := ParseResourceQuery(term)
+
+		var indexes []int
+		var subset []string
+		for i, target := range targets {
+			item, ok := items[i].(resourceItem)
+			if !ok || !query.Matches(item.resource) {
+				continue
+			}
+			indexes = append(indexes, i)
+			subset = append(subset, target)
+		}
+
+		if query.Text == "" {
+			ranks := make([]list.Rank, len(indexes))
+			for i, index := range indexes {
+				ranks[i] = list.Rank{Index: index}
+			}
+			return ranks
+		}
+		ranks := list.DefaultFilter(query.Text, subset)
+		for i := range ranks {
+			ranks[i].Index = indexes[ranks[i].Index]
+		}
+		return ranks
+	}
+}
+
+// renderQueryChips shows the structured terms of the resource list's filter, or why one is invalid
+func (m Model) renderQueryChips() string {
+	if m.resourceList.FilterState() == list.Unfiltered {
+		return ""
+	}
+	query, err := ParseResourceQuery(m.resourceList.FilterValue())
+	chips := make([]string, 0, len(query.Terms)+1)
+	for _, term := range query.Terms {
+		chips = append(chips, ChipStyle.Render(term.String()))
+	}
+	if err != nil {
+		chips = append(chips, ErrorMessageStyle.Render(err.Error()))
+	}
+	return lipgloss.JoinHorizontal(lipgloss.Top, chips...)
+}
+
+// switchResourceTab moves to the next (step 1) or previous (step -1) resource list tab
+func (m *Model) switchResourceTab(step int) tea.Cmd {
+	tabs := resourceTabs(m.resources)
+	current := 0
+	for i, tab := range tabs {
+		if tab == m.activeTab {
+			current = i
+		}
+	}
+	m.activeTab = tabs[(current+step+len(tabs))%len(tabs)]
+	m.resourceList.ResetSelected()
+	return tea.Batch(m.refreshResourceList(), m.savePrefs())
+}
+
+// savePrefs remembers the resource list's tab, grouping and sort for the next session
+func (m Model) savePrefs() tea.Cmd {
+	prefs := Prefs{ResourceTab: m.activeTab, GroupBy: m.groupBy, SortBy: m.sortBy}
+	return func() tea.Msg {
+		// Not worth interrupting the user over; the defaults come back next time
+		_ = prefs.Save()
+		return nil
+	}
+}
+
+// renderResourceTabs renders the resource list's tab bar, with the resource count of each tab
+func (m Model) renderResourceTabs() string {
+	tabs := resourceTabs(m.resources)
+	counts := make(map[ResourceType]int)
+	for _, resource := range m.resources {
+		counts[resource.GetType()]++
+	}
+
+	labels := make([]string, len(tabs))
+	active := 0
+	for i, tab := range tabs {
+		count := len(m.resources)
+		if tab != "" {
+			count = counts[tab]
+		}
+		labels[i] = fmt.Sprintf("%s (%d)", resourceTypeLabel(tab), count)
+		if tab == m.activeTab {
+			active = i
+		}
+	}
+	return RenderTabs(labels, active)
+}
+
+// resourceListStatus describes the resource list's grouping and sort
+func (m Model) resourceListStatus() string {
+	status := "Not grouped"
+	if m.groupBy != groupResourcesNone {
+		status = "Grouped by " + m.groupBy
+	}
+	return status + " • Sorted by " + sortLabel(m.sortBy)
+}
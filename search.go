@@ -0,0 +1,94 @@
+package main
+
+import (
+	"fmt"
+	"net"
+	"sort"
+	"strings"
+)
+
+// Search match types, from most to least specific
+const (
+	MatchExact    = "exact"    // The entry is the searched range
+	MatchContains = "contains" // The entry's range contains the searched IP or CIDR
+	MatchWithin   = "within"   // The entry lies inside the searched CIDR
+	MatchOpen     = "open"     // A GKE control plane accepting any IP because authorized networks are disabled
+)
+
+// matchRank orders match types for display
+var matchRank = map[string]int{MatchExact: 0, MatchContains: 1, MatchWithin: 2, MatchOpen: 3}
+
+// SearchMatch is an entry of a resource that authorizes a searched IP or CIDR
+type SearchMatch struct {
+	Resource ResourceRef `json:"resource"`
+	Name     string      `json:"name"`
+	CIDR     string      `json:"cidr"`
+	Port     string      `json:"port,omitempty"`
+	Match    string      `json:"match"`
+}
+
+// ParseSearchQuery parses the IP address or CIDR to search for
+func ParseSearchQuery(query string) (*net.IPNet, error) {
+	normalized, _ := normalizeIP(strings.TrimSpace(query))
+	_, block, err := net.ParseCIDR(normalized)
+	if err != nil {
+		return nil, fmt.Errorf("invalid IP address or CIDR %q", query)
+	}
+	return block, nil
+}
+
+// SearchNetworks finds every entry across the resources that equals, contains or lies within
+// the searched range, most specific matches first
+func SearchNetworks(resources []CloudResource, query *net.IPNet) []SearchMatch {
+	var matches []SearchMatch
+	for _, resource := range resources {
+		ref := resourceRefOf(resource)
+		if cluster, ok := resource.(GKECluster); ok && !cluster.MasterAuthorizedNetworksEnabled && cluster.PublicEndpoint != "" {
+			matches = append(matches, SearchMatch{Resource: ref, Name: "(networks disabled)", CIDR: "any", Match: MatchOpen})
+			continue
+		}
+
+		for _, network := range resourceNetworks(resource) {
+			value, _ := normalizeIP(network.Value)
+			_, block, err := net.ParseCIDR(value)
+			if err != nil {
+				continue
+			}
+			match, ok := matchNetwork(block, query)
+			if !ok {
+				continue
+			}
+			matches = append(matches, SearchMatch{
+				Resource: ref,
+				Name:     networkName(network),
+				CIDR:     value,
+				Port:     network.Port,
+				Match:    match,
+			})
+		}
+	}
+
+	sort.SliceStable(matches, func(i, j int) bool {
+		if matchRank[matches[i].Match] != matchRank[matches[j].Match] {
+			return matchRank[matches[i].Match] < matchRank[matches[j].Match]
+		}
+		if a, b := matches[i].Resource.String(), matches[j].Resource.String(); a != b {
+			return a < b
+		}
+		return cidrLess(matches[i].CIDR, matches[j].CIDR)
+	})
+	return matches
+}
+
+// matchNetwork classifies how an entry relates to the searched range
+func matchNetwork(entry, query *net.IPNet) (string, bool) {
+	switch {
+	case cidrContains(entry, query) && cidrContains(query, entry):
+		return MatchExact, true
+	case cidrContains(entry, query):
+		return MatchContains, true
+	case cidrContains(query, entry):
+		return MatchWithin, true
+	}
+	return "", false
+}
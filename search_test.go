@@ -0,0 +1,109 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSearchNetworks(t *testing.T) {
+	orders := SQLInstance{
+		Name: "orders", Project: "prod-project", PublicIPEnabled: true,
+		AuthorizedNetworks: []AuthorizedNetwork{
+			{Name: "office", Value: "203.0.113.0/24"},
+			{Name: "laptop", Value: "203.0.113.7"},
+			{Name: "vpn", Value: "198.51.100.0/24"},
+		},
+	}
+	cluster := GKECluster{
+		Name: "apps", Project: "prod-project", PublicEndpoint: "34.1.2.3", MasterAuthorizedNetworksEnabled: true,
+		MasterAuthorizedNetworks: []AuthorizedNetwork{{DisplayName: "corp", Value: "203.0.0.0/16"}},
+	}
+	openCluster := GKECluster{Name: "sandbox", Project: "dev-project", PublicEndpoint: "34.1.2.4"}
+	privateCluster := GKECluster{Name: "internal", Project: "dev-project", PrivateClusterEnabled: true}
+	resources := []CloudResource{orders, cluster, openCluster, privateCluster}
+
+	tests := []struct {
+		query string
+		want  []string // "resource entry match", most specific first
+	}{
+		{
+			query: "203.0.113.7",
+			want: []string{
+				"orders laptop exact",
+				"apps corp contains",
+				"orders office contains",
+				"sandbox (networks disabled) open",
+			},
+		},
+		{
+			query: "203.0.113.0/24",
+			want: []string{
+				"orders office exact",
+				"apps corp contains",
+				"orders laptop within",
+				"sandbox (networks disabled) open",
+			},
+		},
+		{
+			query: "198.51.0.0/16",
+			want: []string{
+				"orders vpn within",
+				"sandbox (networks disabled) open",
+			},
+		},
+		{
+			query: "192.0.2.1",
+			want: []string{
+				"sandbox (networks disabled) open",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			query, err := ParseSearchQuery(tt.query)
+			if err != nil {
+				t.Fatalf("ParseSearchQuery(%q): %v", tt.query, err)
+			}
+			var got []string
+			for _, match := range SearchNetworks(resources, query) {
+				got = append(got, match.Resource.Name+" "+match.Name+" "+match.Match)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("SearchNetworks(%s) = %q, want %q", tt.query, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseSearchQuery(t *testing.T) {
+	tests := []struct {
+		query   string
+		want    string
+		wantErr bool
+	}{
+		{query: "203.0.113.7", want: "203.0.113.7/32"},
+		{query: " 203.0.113.0/24 ", want: "203.0.113.0/24"},
+		{query: "203.0.113.77/24", want: "203.0.113.0/24"},
+		{query: "2001:db8::/32", want: "2001:db8::/32"},
+		{query: "office", wantErr: true},
+		{query: "", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			block, err := ParseSearchQuery(tt.query)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("ParseSearchQuery(%q) = %s, want an error", tt.query, block)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseSearchQuery(%q): %v", tt.query, err)
+			}
+			if block.String() != tt.want {
+				t.Errorf("ParseSearchQuery(%q) = %s, want %s", tt.query, block, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,242 @@
+package main
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"google.golang.org/api/container/v1"
+	"google.golang.org/api/sqladmin/v1beta4"
+)
+
+// SnapshotConfig is the "snapshots" section of the config file
+type SnapshotConfig struct {
+	Dir string `json:"dir,omitempty"` // Defaults to snapshots/ next to the config file
+}
+
+// Snapshot is a resource's full network list captured right before a change
+type Snapshot struct {
+	ID        string              `json:"id"`
+	Timestamp time.Time           `json:"timestamp"`
+	Account   string              `json:"account"`
+	OSUser    string              `json:"os_user"`
+	Operation string              `json:"operation"` // The change about to be made
+	Detail    string              `json:"detail,omitempty"`
+	Resource  ResourceRef         `json:"resource"`
+	Networks  []AuthorizedNetwork `json:"networks"`
+	PSC       *PSCSnapshot        `json:"psc,omitempty"` // SQL instances with PSC enabled
+	GKE       *GKEAccessSnapshot  `json:"gke,omitempty"` // GKE clusters
+}
+
+// PSCSnapshot is a SQL instance's Private Service Connect allow list at the time of a snapshot
+type PSCSnapshot struct {
+	AllowedConsumerProjects []string `json:"allowed_consumer_projects"`
+}
+
+// GKEAccessSnapshot is a cluster's control plane access switches at the time of a snapshot
+type GKEAccessSnapshot struct {
+	MasterAuthorizedNetworksEnabled bool `json:"master_authorized_networks_enabled"`
+	GCPPublicCidrsAccessEnabled     bool `json:"gcp_public_cidrs_access_enabled"`
+}
+
+// recordSettings adds the access settings besides the network list that a change can touch
+func (s *Snapshot) recordSettings(resource CloudResource) {
+	if projects, ok := pscConsumerProjects(resource); ok {
+		s.PSC = &PSCSnapshot{AllowedConsumerProjects: projects}
+	}
+	if cluster, ok := resource.(GKECluster); ok {
+		s.GKE = &GKEAccessSnapshot{
+			MasterAuthorizedNetworksEnabled: cluster.MasterAuthorizedNetworksEnabled,
+			GCPPublicCidrsAccessEnabled:     cluster.GCPPublicCidrsAccessEnabled,
+		}
+	}
+}
+
+// SettingChanges lists what restoring a snapshot changes besides the networks, in the
+// "+ / - / ~" form of NetworkDiff.Lines
+func (s *Snapshot) SettingChanges(current CloudResource) []string {
+	var lines []string
+	if projects, ok := pscConsumerProjects(current); ok && s.PSC != nil {
+		for _, change := range projectChanges(projects, s.PSC.AllowedConsumerProjects) {
+			lines = append(lines, strings.Replace(change, "psc:", " PSC consumer project ", 1))
+		}
+	}
+	if cluster, ok := current.(GKECluster); ok && s.GKE != nil {
+		if cluster.MasterAuthorizedNetworksEnabled != s.GKE.MasterAuthorizedNetworksEnabled {
+			lines = append(lines, fmt.Sprintf("~ master authorized networks %s → %s",
+				enabledLabel(cluster.MasterAuthorizedNetworksEnabled), enabledLabel(s.GKE.MasterAuthorizedNetworksEnabled)))
+		}
+		if cluster.GCPPublicCidrsAccessEnabled != s.GKE.GCPPublicCidrsAccessEnabled {
+			lines = append(lines, fmt.Sprintf("~ Google Cloud public IP access %s → %s",
+				enabledLabel(cluster.GCPPublicCidrsAccessEnabled), enabledLabel(s.GKE.GCPPublicCidrsAccessEnabled)))
+		}
+	}
+	return lines
+}
+
+// SnapshotStore keeps one JSON file per snapshot in a directory
+type SnapshotStore struct {
+	dir string
+}
+
+// NewSnapshotStore creates a snapshot store from config
+func NewSnapshotStore(config SnapshotConfig) *SnapshotStore {
+	dir := config.Dir
+	if dir == "" {
+		dir = filepath.Join(configDir(), "snapshots")
+	}
+	return &SnapshotStore{dir: expandHome(dir)}
+}
+
+// newSnapshotID returns a sortable, unique snapshot ID
+func newSnapshotID(t time.Time) string {
+	suffix := make([]byte, 3)
+	rand.Read(suffix)
+	return t.UTC().Format("20060102T150405Z") + "-" + hex.EncodeToString(suffix)
+}
+
+// Save writes a snapshot, filling in its ID and timestamp
+func (s *SnapshotStore) Save(snapshot *Snapshot) error {
+	if snapshot.Timestamp.IsZero() {
+		snapshot.Timestamp = time.Now().UTC()
+	}
+	if snapshot.ID == "" {
+		snapshot.ID = newSnapshotID(snapshot.Timestamp)
+	}
+
+	data, err := json.MarshalIndent(snapshot, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode snapshot: %v", err)
+	}
+	if err := os.MkdirAll(s.dir, 0o700); err != nil {
+		return fmt.Errorf("failed to create snapshot directory: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(s.dir, snapshot.ID+".json"), data, 0o600); err != nil {
+		return fmt.Errorf("failed to write snapshot: %v", err)
+	}
+	return nil
+}
+
+// Load reads a snapshot by ID
+func (s *SnapshotStore) Load(id string) (*Snapshot, error) {
+	if id == "" || strings.ContainsAny(id, `/\`) {
+		return nil, fmt.Errorf("invalid snapshot ID %q", id)
+	}
+
+	data, err := os.ReadFile(filepath.Join(s.dir, id+".json"))
+	if os.IsNotExist(err) {
+		return nil, fmt.Errorf("snapshot %s not found in %s", id, s.dir)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read snapshot: %v", err)
+	}
+
+	var snapshot Snapshot
+	if err := json.Unmarshal(data, &snapshot); err != nil {
+		return nil, fmt.Errorf("failed to parse snapshot %s: %v", id, err)
+	}
+	return &snapshot, nil
+}
+
+// List returns snapshots, newest first, limited to one resource unless ref is nil
+func (s *SnapshotStore) List(ref *ResourceRef) ([]Snapshot, error) {
+	files, err := filepath.Glob(filepath.Join(s.dir, "*.json"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list snapshots: %v", err)
+	}
+
+	var snapshots []Snapshot
+	for _, file := range files {
+		snapshot, err := s.Load(strings.TrimSuffix(filepath.Base(file), ".json"))
+		if err != nil {
+			continue
+		}
+		if ref != nil && !sameResource(snapshot.Resource, *ref) {
+			continue
+		}
+		snapshots = append(snapshots, *snapshot)
+	}
+
+	sort.Slice(snapshots, func(i, j int) bool {
+		return snapshots[i].ID > snapshots[j].ID
+	})
+	return snapshots, nil
+}
+
+// LatestChange returns the snapshot taken before the most recent change to a resource, or nil
+// if there is none. Snapshots taken before a restore are skipped, so undoing twice doesn't
+// re-apply the change the first undo reverted.
+func (s *SnapshotStore) LatestChange(ref ResourceRef) (*Snapshot, error) {
+	snapshots, err := s.List(&ref)
+	if err != nil {
+		return nil, err
+	}
+	for i := range snapshots {
+		if snapshots[i].Operation != "restore" {
+			return &snapshots[i], nil
+		}
+	}
+	return nil, nil
+}
+
+// sameResource reports whether two references point at the same resource
+func sameResource(a, b ResourceRef) bool {
+	if a.ID != "" || b.ID != "" {
+		return a.Type == b.Type && a.ID == b.ID
+	}
+	return a.Type == b.Type && a.Project == b.Project && a.Location == b.Location && a.Name == b.Name
+}
+
+// RestoreSnapshot sets a resource's networks, and any PSC allow list or GKE access switches
+// it recorded, back to exactly those in a snapshot. Policy is not checked, since the point
+// is to get back to a known-good state.
+func (nm *NetworkManager) RestoreSnapshot(snapshot *Snapshot) error {
+	resource, err := snapshot.Resource.Resource()
+	if err != nil {
+		return err
+	}
+	return nm.audited("restore", resource, "snapshot "+snapshot.ID, func(CloudResource) (string, error) {
+		return nm.restoreResource(resource, snapshot)
+	})
+}
+
+// restoreResource applies a snapshot, only patching what differs from the live resource
+func (nm *NetworkManager) restoreResource(resource CloudResource, snapshot *Snapshot) (string, error) {
+	if cluster, ok := resource.(GKECluster); ok && snapshot.GKE != nil {
+		// One update sets the list and both switches, so a list can go back onto a cluster
+		// whose authorized networks were disabled since
+		return nm.updateGKEMasterAuthorizedNetworksConfig(cluster, func(config *container.MasterAuthorizedNetworksConfig) error {
+			config.Enabled = snapshot.GKE.MasterAuthorizedNetworksEnabled
+			config.GcpPublicCidrsAccessEnabled = snapshot.GKE.GCPPublicCidrsAccessEnabled
+			config.CidrBlocks = gkeCidrBlocks(snapshot.Networks)
+			return nil
+		})
+	}
+
+	current, err := nm.GetResourceDetails(resource)
+	if err != nil {
+		return "", err
+	}
+	var operationID string
+	if !diffNetworks(resourceNetworks(current), snapshot.Networks).Empty() {
+		if operationID, err = nm.setResourceNetworks(current, snapshot.Networks); err != nil {
+			return operationID, err
+		}
+	}
+	if instance, ok := current.(SQLInstance); ok && snapshot.PSC != nil &&
+		len(projectChanges(instance.AllowedConsumerProjects, snapshot.PSC.AllowedConsumerProjects)) > 0 {
+		return nm.updateSQLPSCConfig(instance, func(psc *sqladmin.PscConfig) error {
+			psc.AllowedConsumerProjects = snapshot.PSC.AllowedConsumerProjects
+			// An empty list must be sent explicitly or the API ignores it
+			psc.ForceSendFields = append(psc.ForceSendFields, "AllowedConsumerProjects")
+			return nil
+		})
+	}
+	return operationID, nil
+}
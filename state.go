@@ -0,0 +1,173 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// Lifecycle state classes: whether a resource's networks can be changed right now
+const (
+	StateReady       = "ready"       // Changes go through
+	StateBusy        = "busy"        // An operation is in progress; changes fail until it finishes
+	StateUnavailable = "unavailable" // Stopped, suspended or failed; changes fail until someone fixes it
+)
+
+// waitForReadyMode is set by the global --wait flag; changes then wait for busy resources
+// instead of failing straight away
+var waitForReadyMode bool
+
+// How long and how often changes wait for a busy resource
+const (
+	readyWaitTimeout  = 10 * time.Minute
+	readyPollInterval = 10 * time.Second
+)
+
+// displayState returns the state to show for a resource; stopped SQL instances still report RUNNABLE
+func displayState(resource CloudResource) string {
+	if instance, ok := resource.(SQLInstance); ok && instance.ActivationPolicy == "NEVER" {
+		return "STOPPED"
+	}
+	return resourceState(resource)
+}
+
+// stateClass classifies a resource's lifecycle state. States piam-anc doesn't know are
+// treated as ready, so a new API value never blocks changes.
+func stateClass(resource CloudResource) string {
+	if resourceStopped(resource) {
+		return StateUnavailable
+	}
+	switch strings.ToUpper(resourceState(resource)) {
+	case "RECONCILING", "PROVISIONING", "PENDING_CREATE", "MAINTENANCE", "ONLINE_MAINTENANCE",
+		"REPAIRING", "UPDATING", "STARTING", "RESTARTING":
+		return StateBusy
+	case "FAILED", "ERROR", "DEGRADED", "PENDING_DELETE", "STOPPING", "DROPPING":
+		return StateUnavailable
+	default:
+		return StateReady
+	}
+}
+
+// ResourceStateError reports a resource whose lifecycle state rules out changing its networks
+type ResourceStateError struct {
+	Resource CloudResource
+	State    string
+	Busy     bool // The state will pass on its own
+}
+
+func (e *ResourceStateError) Error() string {
+	name := fmt.Sprintf("%s %s", e.Resource.GetType(), e.Resource.GetName())
+	if e.Busy {
+		return fmt.Sprintf("%s is %s; networks can't be changed until it finishes", name, e.State)
+	}
+	return fmt.Sprintf("%s is %s; networks can't be changed until it is running again", name, e.State)
+}
+
+// checkPatchable returns a ResourceStateError when a resource's networks can't be changed in its current state
+func checkPatchable(resource CloudResource) error {
+	switch stateClass(resource) {
+	case StateBusy:
+		return &ResourceStateError{Resource: resource, State: displayState(resource), Busy: true}
+	case StateUnavailable:
+		return &ResourceStateError{Resource: resource, State: displayState(resource)}
+	}
+	return nil
+}
+
+// waitUntilPatchable returns the resource once its networks can be changed. A busy resource
+// is polled for up to timeout; with no timeout, or in any other state, it fails straight away.
+func (nm *NetworkManager) waitUntilPatchable(resource CloudResource, timeout time.Duration) (CloudResource, error) {
+	deadline := time.Now().Add(timeout)
+	for {
+		err := checkPatchable(resource)
+		var stateErr *ResourceStateError
+		if err == nil || !errors.As(err, &stateErr) || !stateErr.Busy || !time.Now().Before(deadline) {
+			return resource, err
+		}
+
+		select {
+		case <-nm.ctx.Done():
+			return resource, nm.ctx.Err()
+		case <-time.After(readyPollInterval):
+		}
+		current, err := nm.GetResourceDetails(resource)
+		if err != nil {
+			return resource, err
+		}
+		resource = current
+	}
+}
+
+// WaitUntilReady waits up to readyWaitTimeout for busy resources to finish, returning their fresh details
+func (nm *NetworkManager) WaitUntilReady(resources []CloudResource) ([]CloudResource, error) {
+	ready := make([]CloudResource, len(resources))
+	for i, resource := range resources {
+		current, err := nm.waitUntilPatchable(resource, readyWaitTimeout)
+		if err != nil {
+			return nil, err
+		}
+		ready[i] = current
+	}
+	return ready, nil
+}
+
+// resourcesReadyMsg carries fresh details of resources a form waited for
+type resourcesReadyMsg struct {
+	resources []CloudResource
+	err       error
+}
+
+// waitUntilReady waits for busy resources to finish their operation
+func waitUntilReady(resources []CloudResource) tea.Cmd {
+	return func() tea.Msg {
+		nm, err := NewNetworkManager(context.Background())
+		if err != nil {
+			return resourcesReadyMsg{err: err}
+		}
+		ready, err := nm.WaitUntilReady(resources)
+		return resourcesReadyMsg{resources: ready, err: err}
+	}
+}
+
+// submitsOnEnter reports whether Enter submits the add or remove form rather than moving between fields
+func (m Model) submitsOnEnter() bool {
+	if m.isSubmitting {
+		return false
+	}
+	switch m.state {
+	case stateRemoveNetwork:
+		return true
+	case stateAddNetwork:
+		_, group := m.selectedGroup()
+		return group || m.addFormFocus == 1
+	}
+	return false
+}
+
+// unreadyTarget returns why the first resource a form applies to can't be changed, or nil
+// when they all can
+func (m Model) unreadyTarget() *ResourceStateError {
+	for _, resource := range m.targetResources() {
+		var stateErr *ResourceStateError
+		if errors.As(checkPatchable(resource), &stateErr) {
+			return stateErr
+		}
+	}
+	return nil
+}
+
+// renderStateWarning warns in a form when a resource it applies to can't be changed right now
+func (m Model) renderStateWarning() string {
+	stateErr := m.unreadyTarget()
+	if stateErr == nil {
+		return ""
+	}
+	if stateErr.Busy {
+		return RenderWarning(fmt.Sprintf("%s is %s - Enter waits for it to finish", stateErr.Resource.GetName(), stateErr.State))
+	}
+	return RenderError(fmt.Sprintf("%s is %s - start it before changing its networks", stateErr.Resource.GetName(), stateErr.State))
+}
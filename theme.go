@@ -1,256 +1,324 @@
 package main
 
 import (
+	"fmt"
 	"strings"
+	"time"
 	
 	"github.com/charmbracelet/lipgloss"
 )
 
+// Palette is a theme's colours, named after the Catppuccin palette
+type Palette struct {
+	Rosewater string `json:"rosewater"`
+	Flamingo  string `json:"flamingo"`
+	Pink      string `json:"pink"`
+	Mauve     string `json:"mauve"`
+	Red       string `json:"red"`
+	Maroon    string `json:"maroon"`
+	Peach     string `json:"peach"`
+	Yellow    string `json:"yellow"`
+	Green     string `json:"green"`
+	Teal      string `json:"teal"`
+	Sky       string `json:"sky"`
+	Sapphire  string `json:"sapphire"`
+	Blue      string `json:"blue"`
+	Lavender  string `json:"lavender"`
+	Text      string `json:"text"`
+	Subtext1  string `json:"subtext1"`
+	Subtext0  string `json:"subtext0"`
+	Overlay2  string `json:"overlay2"`
+	Overlay1  string `json:"overlay1"`
+	Overlay0  string `json:"overlay0"`
+	Surface2  string `json:"surface2"`
+	Surface1  string `json:"surface1"`
+	Surface0  string `json:"surface0"`
+	Base      string `json:"base"`
+	Mantle    string `json:"mantle"`
+	Crust     string `json:"crust"`
+}
+
 // Catppuccin Mocha color palette
+var CatppuccinMocha = Palette{
+	Rosewater: "#f5e0dc",
+	Flamingo:  "#f2cdcd",
+	Pink:      "#f5c2e7",
+	Mauve:     "#cba6f7",
+	Red:       "#f38ba8",
+	Maroon:    "#eba0ac",
+	Peach:     "#fab387",
+	Yellow:    "#f9e2af",
+	Green:     "#a6e3a1",
+	Teal:      "#94e2d5",
+	Sky:       "#89dceb",
+	Sapphire:  "#74c7ec",
+	Blue:      "#89b4fa",
+	Lavender:  "#b4befe",
+	Text:      "#cdd6f4",
+	Subtext1:  "#bac2de",
+	Subtext0:  "#a6adc8",
+	Overlay2:  "#9399b2",
+	Overlay1:  "#7f849c",
+	Overlay0:  "#6c7086",
+	Surface2:  "#585b70",
+	Surface1:  "#45475a",
+	Surface0:  "#313244",
+	Base:      "#1e1e2e",
+	Mantle:    "#181825",
+	Crust:     "#11111b",
+}
+
+// Colors is the active theme's palette
+var Colors = CatppuccinMocha
+
+// Theme styles, set from the active palette by applyPalette
 var (
-	CatppuccinMocha = struct {
-		Rosewater string
-		Flamingo  string
-		Pink      string
-		Mauve     string
-		Red       string
-		Maroon    string
-		Peach     string
-		Yellow    string
-		Green     string
-		Teal      string
-		Sky       string
-		Sapphire  string
-		Blue      string
-		Lavender  string
-		Text      string
-		Subtext1  string
-		Subtext0  string
-		Overlay2  string
-		Overlay1  string
-		Overlay0  string
-		Surface2  string
-		Surface1  string
-		Surface0  string
-		Base      string
-		Mantle    string
-		Crust     string
-	}{
-		Rosewater: "#f5e0dc",
-		Flamingo:  "#f2cdcd",
-		Pink:      "#f5c2e7",
-		Mauve:     "#cba6f7",
-		Red:       "#f38ba8",
-		Maroon:    "#eba0ac",
-		Peach:     "#fab387",
-		Yellow:    "#f9e2af",
-		Green:     "#a6e3a1",
-		Teal:      "#94e2d5",
-		Sky:       "#89dceb",
-		Sapphire:  "#74c7ec",
-		Blue:      "#89b4fa",
-		Lavender:  "#b4befe",
-		Text:      "#cdd6f4",
-		Subtext1:  "#bac2de",
-		Subtext0:  "#a6adc8",
-		Overlay2:  "#9399b2",
-		Overlay1:  "#7f849c",
-		Overlay0:  "#6c7086",
-		Surface2:  "#585b70",
-		Surface1:  "#45475a",
-		Surface0:  "#313244",
-		Base:      "#1e1e2e",
-		Mantle:    "#181825",
-		Crust:     "#11111b",
-	}
+	BaseStyle              lipgloss.Style
+	TitleStyle             lipgloss.Style
+	SubtitleStyle          lipgloss.Style
+	BorderStyle            lipgloss.Style
+	ActiveBorderStyle      lipgloss.Style
+	ButtonStyle            lipgloss.Style
+	ActiveButtonStyle      lipgloss.Style
+	ListItemStyle          lipgloss.Style
+	SelectedListItemStyle  lipgloss.Style
+	SuccessStyle           lipgloss.Style
+	ErrorStyle             lipgloss.Style
+	WarningStyle           lipgloss.Style
+	InfoStyle              lipgloss.Style
+	InputStyle             lipgloss.Style
+	FocusedInputStyle      lipgloss.Style
+	TableHeaderStyle       lipgloss.Style
+	TableRowStyle          lipgloss.Style
+	TableAltRowStyle       lipgloss.Style
+	HelpStyle              lipgloss.Style
+	KeyStyle               lipgloss.Style
+	SpinnerStyle           lipgloss.Style
+	NetworkNameStyle       lipgloss.Style
+	NetworkIPStyle         lipgloss.Style
+	NetworkMetaStyle       lipgloss.Style
+	MessageStyle           lipgloss.Style
+	ErrorMessageStyle      lipgloss.Style
+	EmptyStateStyle        lipgloss.Style
+	TableStyle             lipgloss.Style
+	TableCellStyle         lipgloss.Style
+	TableRowEvenStyle      lipgloss.Style
+	TableRowOddStyle       lipgloss.Style
+	TableSelectedCellStyle lipgloss.Style
+	HelpBoxStyle           lipgloss.Style
+	LabelStyle             lipgloss.Style
+	ActiveInputStyle       lipgloss.Style
+	FormBoxStyle           lipgloss.Style
+	SubtleTextStyle        lipgloss.Style
+	ChipStyle              lipgloss.Style
+	ErrorBoxStyle          lipgloss.Style
+	ErrorTitleStyle        lipgloss.Style
 )
 
-// Theme styles
-var (
+func init() {
+	applyPalette(CatppuccinMocha)
+}
+
+// applyPalette makes a palette the active theme, rebuilding every style from it
+func applyPalette(p Palette) {
+	Colors = p
+
 	// Base styles
 	BaseStyle = lipgloss.NewStyle().
-			Background(lipgloss.Color(CatppuccinMocha.Base)).
-			Foreground(lipgloss.Color(CatppuccinMocha.Text))
+		Background(lipgloss.Color(p.Base)).
+		Foreground(lipgloss.Color(p.Text))
 
 	// Title styles
 	TitleStyle = lipgloss.NewStyle().
-			Foreground(lipgloss.Color(CatppuccinMocha.Lavender)).
-			Background(lipgloss.Color(CatppuccinMocha.Surface0)).
-			Padding(0, 1).
-			Bold(true)
+		Foreground(lipgloss.Color(p.Lavender)).
+		Background(lipgloss.Color(p.Surface0)).
+		Padding(0, 1).
+		Bold(true)
 
 	SubtitleStyle = lipgloss.NewStyle().
-			Foreground(lipgloss.Color(CatppuccinMocha.Subtext1)).
-			Italic(true)
+		Foreground(lipgloss.Color(p.Subtext1)).
+		Italic(true)
 
 	// Border styles
 	BorderStyle = lipgloss.NewStyle().
-			Border(lipgloss.RoundedBorder()).
-			BorderForeground(lipgloss.Color(CatppuccinMocha.Surface2))
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(lipgloss.Color(p.Surface2))
 
 	ActiveBorderStyle = lipgloss.NewStyle().
-				Border(lipgloss.RoundedBorder()).
-				BorderForeground(lipgloss.Color(CatppuccinMocha.Mauve))
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(lipgloss.Color(p.Mauve))
 
 	// Button styles
 	ButtonStyle = lipgloss.NewStyle().
-			Foreground(lipgloss.Color(CatppuccinMocha.Base)).
-			Background(lipgloss.Color(CatppuccinMocha.Blue)).
-			Padding(0, 2).
-			Margin(0, 1)
+		Foreground(lipgloss.Color(p.Base)).
+		Background(lipgloss.Color(p.Blue)).
+		Padding(0, 2).
+		Margin(0, 1)
 
 	ActiveButtonStyle = lipgloss.NewStyle().
-				Foreground(lipgloss.Color(CatppuccinMocha.Base)).
-				Background(lipgloss.Color(CatppuccinMocha.Mauve)).
-				Padding(0, 2).
-				Margin(0, 1).
-				Bold(true)
+		Foreground(lipgloss.Color(p.Base)).
+		Background(lipgloss.Color(p.Mauve)).
+		Padding(0, 2).
+		Margin(0, 1).
+		Bold(true)
 
 	// List styles
 	ListItemStyle = lipgloss.NewStyle().
-			Foreground(lipgloss.Color(CatppuccinMocha.Text)).
-			Padding(0, 2)
+		Foreground(lipgloss.Color(p.Text)).
+		Padding(0, 2)
 
 	SelectedListItemStyle = lipgloss.NewStyle().
-				Foreground(lipgloss.Color(CatppuccinMocha.Base)).
-				Background(lipgloss.Color(CatppuccinMocha.Mauve)).
-				Padding(0, 2).
-				Bold(true)
+		Foreground(lipgloss.Color(p.Base)).
+		Background(lipgloss.Color(p.Mauve)).
+		Padding(0, 2).
+		Bold(true)
 
 	// Status styles
 	SuccessStyle = lipgloss.NewStyle().
-			Foreground(lipgloss.Color(CatppuccinMocha.Green)).
-			Bold(true)
+		Foreground(lipgloss.Color(p.Green)).
+		Bold(true)
 
 	ErrorStyle = lipgloss.NewStyle().
-			Foreground(lipgloss.Color(CatppuccinMocha.Red)).
-			Bold(true)
+		Foreground(lipgloss.Color(p.Red)).
+		Bold(true)
 
 	WarningStyle = lipgloss.NewStyle().
-			Foreground(lipgloss.Color(CatppuccinMocha.Yellow)).
-			Bold(true)
+		Foreground(lipgloss.Color(p.Yellow)).
+		Bold(true)
 
 	InfoStyle = lipgloss.NewStyle().
-			Foreground(lipgloss.Color(CatppuccinMocha.Blue)).
-			Bold(true)
+		Foreground(lipgloss.Color(p.Blue)).
+		Bold(true)
 
 	// Input styles
 	InputStyle = lipgloss.NewStyle().
-			Foreground(lipgloss.Color(CatppuccinMocha.Text)).
-			Background(lipgloss.Color(CatppuccinMocha.Surface0)).
-			Padding(0, 1).
-			Border(lipgloss.RoundedBorder()).
-			BorderForeground(lipgloss.Color(CatppuccinMocha.Surface2))
+		Foreground(lipgloss.Color(p.Text)).
+		Background(lipgloss.Color(p.Surface0)).
+		Padding(0, 1).
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(lipgloss.Color(p.Surface2))
 
 	FocusedInputStyle = lipgloss.NewStyle().
-				Foreground(lipgloss.Color(CatppuccinMocha.Text)).
-				Background(lipgloss.Color(CatppuccinMocha.Surface0)).
-				Padding(0, 1).
-				Border(lipgloss.RoundedBorder()).
-				BorderForeground(lipgloss.Color(CatppuccinMocha.Mauve))
+		Foreground(lipgloss.Color(p.Text)).
+		Background(lipgloss.Color(p.Surface0)).
+		Padding(0, 1).
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(lipgloss.Color(p.Mauve))
 
 	// Table styles
 	TableHeaderStyle = lipgloss.NewStyle().
-				Foreground(lipgloss.Color(CatppuccinMocha.Lavender)).
-				Background(lipgloss.Color(CatppuccinMocha.Surface1)).
-				Bold(true).
-				Padding(0, 1)
+		Foreground(lipgloss.Color(p.Lavender)).
+		Background(lipgloss.Color(p.Surface1)).
+		Bold(true).
+		Padding(0, 1)
 
 	TableRowStyle = lipgloss.NewStyle().
-			Foreground(lipgloss.Color(CatppuccinMocha.Text)).
-			Padding(0, 1)
+		Foreground(lipgloss.Color(p.Text)).
+		Padding(0, 1)
 
 	TableAltRowStyle = lipgloss.NewStyle().
-				Foreground(lipgloss.Color(CatppuccinMocha.Text)).
-				Background(lipgloss.Color(CatppuccinMocha.Surface0)).
-				Padding(0, 1)
+		Foreground(lipgloss.Color(p.Text)).
+		Background(lipgloss.Color(p.Surface0)).
+		Padding(0, 1)
 
 	// Help styles
 	HelpStyle = lipgloss.NewStyle().
-			Foreground(lipgloss.Color(CatppuccinMocha.Subtext0)).
-			Italic(true)
+		Foreground(lipgloss.Color(p.Subtext0)).
+		Italic(true)
 
 	KeyStyle = lipgloss.NewStyle().
-			Foreground(lipgloss.Color(CatppuccinMocha.Mauve)).
-			Bold(true)
+		Foreground(lipgloss.Color(p.Mauve)).
+		Bold(true)
 
 	// Loading styles
 	SpinnerStyle = lipgloss.NewStyle().
-			Foreground(lipgloss.Color(CatppuccinMocha.Blue))
+		Foreground(lipgloss.Color(p.Blue))
 
 	// Network type styles
 	NetworkNameStyle = lipgloss.NewStyle().
-				Foreground(lipgloss.Color(CatppuccinMocha.Green)).
-				Bold(true)
+		Foreground(lipgloss.Color(p.Green)).
+		Bold(true)
 
 	NetworkIPStyle = lipgloss.NewStyle().
-			Foreground(lipgloss.Color(CatppuccinMocha.Blue))
+		Foreground(lipgloss.Color(p.Blue))
 
 	NetworkMetaStyle = lipgloss.NewStyle().
-				Foreground(lipgloss.Color(CatppuccinMocha.Subtext0)).
-				Italic(true)
+		Foreground(lipgloss.Color(p.Subtext0)).
+		Italic(true)
 
-	// Message styles  
+	// Message styles
 	MessageStyle = lipgloss.NewStyle().
-			Foreground(lipgloss.Color(CatppuccinMocha.Green)).
-			Bold(true)
+		Foreground(lipgloss.Color(p.Green)).
+		Bold(true)
 
 	ErrorMessageStyle = lipgloss.NewStyle().
-				Foreground(lipgloss.Color(CatppuccinMocha.Red)).
-				Bold(true)
+		Foreground(lipgloss.Color(p.Red)).
+		Bold(true)
 
 	// Additional missing styles
 	EmptyStateStyle = lipgloss.NewStyle().
-				Foreground(lipgloss.Color(CatppuccinMocha.Subtext0)).
-				Italic(true).
-				Align(lipgloss.Center)
+		Foreground(lipgloss.Color(p.Subtext0)).
+		Italic(true).
+		Align(lipgloss.Center)
 
 	TableStyle = lipgloss.NewStyle().
-			Border(lipgloss.RoundedBorder()).
-			BorderForeground(lipgloss.Color(CatppuccinMocha.Surface2))
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(lipgloss.Color(p.Surface2))
 
 	TableCellStyle = lipgloss.NewStyle().
-				Padding(0, 1)
+		Padding(0, 1)
 
 	TableRowEvenStyle = lipgloss.NewStyle().
-				Background(lipgloss.Color(CatppuccinMocha.Surface0))
+		Background(lipgloss.Color(p.Surface0))
 
 	TableRowOddStyle = lipgloss.NewStyle().
-				Background(lipgloss.Color(CatppuccinMocha.Base))
+		Background(lipgloss.Color(p.Base))
+
+	TableSelectedCellStyle = TableCellStyle.Copy().
+		Foreground(lipgloss.Color(p.Base)).
+		Background(lipgloss.Color(p.Mauve)).
+		Bold(true)
 
 	HelpBoxStyle = lipgloss.NewStyle().
-			Border(lipgloss.RoundedBorder()).
-			BorderForeground(lipgloss.Color(CatppuccinMocha.Blue)).
-			Padding(1, 2)
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(lipgloss.Color(p.Blue)).
+		Padding(1, 2)
 
 	// Form styles
 	LabelStyle = lipgloss.NewStyle().
-			Foreground(lipgloss.Color(CatppuccinMocha.Lavender)).
-			Bold(true)
+		Foreground(lipgloss.Color(p.Lavender)).
+		Bold(true)
 
 	ActiveInputStyle = lipgloss.NewStyle().
-				Border(lipgloss.RoundedBorder()).
-				BorderForeground(lipgloss.Color(CatppuccinMocha.Mauve)).
-				Padding(0, 1)
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(lipgloss.Color(p.Mauve)).
+		Padding(0, 1)
 
 	FormBoxStyle = lipgloss.NewStyle().
-			Border(lipgloss.RoundedBorder()).
-			BorderForeground(lipgloss.Color(CatppuccinMocha.Surface2)).
-			Padding(1, 2)
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(lipgloss.Color(p.Surface2)).
+		Padding(1, 2)
 
 	SubtleTextStyle = lipgloss.NewStyle().
-				Foreground(lipgloss.Color(CatppuccinMocha.Subtext0)).
-				Italic(true)
+		Foreground(lipgloss.Color(p.Subtext0)).
+		Italic(true)
+
+	// Structured terms of the resource list filter
+	ChipStyle = lipgloss.NewStyle().
+		Foreground(lipgloss.Color(p.Base)).
+		Background(lipgloss.Color(p.Teal)).
+		Padding(0, 1).
+		MarginRight(1)
 
 	ErrorBoxStyle = lipgloss.NewStyle().
-			Border(lipgloss.RoundedBorder()).
-			BorderForeground(lipgloss.Color(CatppuccinMocha.Red)).
-			Padding(1, 2)
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(lipgloss.Color(p.Red)).
+		Padding(1, 2)
 
 	ErrorTitleStyle = lipgloss.NewStyle().
-				Foreground(lipgloss.Color(CatppuccinMocha.Red)).
-				Bold(true)
-)
+		Foreground(lipgloss.Color(p.Red)).
+		Bold(true)
+}
 
 // Helper functions for common styling patterns
 func RenderTitle(title string) string {
@@ -301,4 +369,371 @@ func RenderNetworkIP(ip string) string {
 
 func RenderNetworkMeta(meta string) string {
 	return NetworkMetaStyle.Render(meta)
-}
\ No newline at end of file
+}
+
+// RenderResourceState renders a resource's lifecycle state: green when changes go through,
+// yellow while an operation is in progress, red when it is stopped or failed
+func RenderResourceState(resource CloudResource) string {
+	color := Colors.Green
+	switch stateClass(resource) {
+	case StateBusy:
+		color = Colors.Yellow
+	case StateUnavailable:
+		color = Colors.Red
+	}
+	return lipgloss.NewStyle().Foreground(lipgloss.Color(color)).Render(displayState(resource))
+}
+
+// RenderTabs renders a tab bar with the active tab highlighted
+func RenderTabs(tabs []string, active int) string {
+	rendered := make([]string, len(tabs))
+	for i, tab := range tabs {
+		if i == active {
+			rendered[i] = ActiveButtonStyle.Render(tab)
+		} else {
+			rendered[i] = ButtonStyle.Render(tab)
+		}
+	}
+	return lipgloss.JoinHorizontal(lipgloss.Top, rendered...)
+}
+
+// RenderPSCTable renders the Private Service Connect settings of a SQL instance
+func RenderPSCTable(instance SQLInstance) string {
+	if !instance.PSCEnabled {
+		return EmptyStateStyle.Render("Private Service Connect is not enabled on this instance")
+	}
+
+	lines := []string{}
+	if instance.PSCServiceAttachmentLink != "" {
+		lines = append(lines, SubtleTextStyle.Render("Service attachment: "+instance.PSCServiceAttachmentLink), "")
+	}
+
+	if len(instance.AllowedConsumerProjects) == 0 {
+		lines = append(lines, EmptyStateStyle.Render("No allowed consumer projects configured"))
+		return lipgloss.JoinVertical(lipgloss.Left, lines...)
+	}
+
+	rows := []string{TableHeaderStyle.Render(TableCellStyle.Width(50).Render("Allowed Consumer Project"))}
+	for i, project := range instance.AllowedConsumerProjects {
+		row := TableCellStyle.Width(50).Render(project)
+		if i%2 == 0 {
+			rows = append(rows, TableRowEvenStyle.Render(row))
+		} else {
+			rows = append(rows, TableRowOddStyle.Render(row))
+		}
+	}
+	lines = append(lines, TableStyle.Render(lipgloss.JoinVertical(lipgloss.Left, rows...)))
+
+	return lipgloss.JoinVertical(lipgloss.Left, lines...)
+}
+
+// RenderGKEAccessStatus renders the master authorized networks settings of a cluster
+func RenderGKEAccessStatus(cluster GKECluster) string {
+	if !cluster.MasterAuthorizedNetworksEnabled {
+		return lipgloss.JoinVertical(
+			lipgloss.Left,
+			ErrorStyle.Render("🚨 Master authorized networks: DISABLED"),
+			ErrorStyle.Render("   The control plane accepts connections from ANY IP address (press 'e' to enable)"),
+		)
+	}
+
+	gcpAccess := "allowed"
+	if !cluster.GCPPublicCidrsAccessEnabled {
+		gcpAccess = "blocked"
+	}
+	return lipgloss.JoinVertical(
+		lipgloss.Left,
+		SuccessStyle.Render("🔐 Master authorized networks: ENABLED"),
+		InfoStyle.Render("☁️  Google Cloud public IPs: "+gcpAccess),
+	)
+}
+
+// RenderHistoryTable renders audit log entries, showing at most limit rows starting at offset
+func RenderHistoryTable(entries []AuditEntry, offset, limit int) string {
+	if len(entries) == 0 {
+		return EmptyStateStyle.Render("No changes recorded yet")
+	}
+
+	header := TableHeaderStyle.Render(lipgloss.JoinHorizontal(
+		lipgloss.Top,
+		TableCellStyle.Width(17).Render("Time"),
+		TableCellStyle.Width(24).Render("User"),
+		TableCellStyle.Width(28).Render("Resource"),
+		TableCellStyle.Width(20).Render("Operation"),
+		TableCellStyle.Width(30).Render("Change"),
+		TableCellStyle.Width(9).Render("Outcome"),
+	))
+
+	end := offset + limit
+	if limit <= 0 || end > len(entries) {
+		end = len(entries)
+	}
+
+	rows := []string{header}
+	for i := offset; i < end; i++ {
+		entry := entries[i]
+		user := entry.Account
+		if user == "" {
+			user = entry.OSUser
+		}
+		outcome := SuccessStyle.Render("ok")
+		if entry.Outcome != AuditOutcomeSuccess {
+			outcome = ErrorStyle.Render("failed")
+		}
+		row := lipgloss.JoinHorizontal(
+			lipgloss.Top,
+			TableCellStyle.Width(17).Render(entry.Timestamp.Local().Format("2006-01-02 15:04")),
+			TableCellStyle.Width(24).Render(truncate(user, 22)),
+			TableCellStyle.Width(28).Render(truncate(entry.Resource, 26)),
+			TableCellStyle.Width(20).Render(entry.Operation),
+			TableCellStyle.Width(30).Render(truncate(auditChangeSummary(entry), 28)),
+			TableCellStyle.Width(9).Render(outcome),
+		)
+		if i%2 == 0 {
+			rows = append(rows, TableRowEvenStyle.Render(row))
+		} else {
+			rows = append(rows, TableRowOddStyle.Render(row))
+		}
+	}
+
+	return TableStyle.Render(lipgloss.JoinVertical(lipgloss.Left, rows...))
+}
+
+// RenderJobPanel renders background jobs, oldest first, with their elapsed time and state
+func RenderJobPanel(jobs []Job, now time.Time, width int) string {
+	headers := []string{
+		TableCellStyle.Width(28).Render("Job"),
+		TableCellStyle.Width(30).Render("Resource"),
+		TableCellStyle.Width(9).Render("Elapsed"),
+		TableCellStyle.Width(10).Render("State"),
+	}
+	// Finished jobs show their result in whatever width is left
+	resultWidth := width - 81
+	if resultWidth > 10 {
+		headers = append(headers, TableCellStyle.Width(resultWidth).Render("Result"))
+	}
+	header := TableHeaderStyle.Render(lipgloss.JoinHorizontal(lipgloss.Top, headers...))
+
+	rows := []string{header}
+	for i, job := range jobs {
+		var state string
+		switch job.State {
+		case JobDone:
+			state = SuccessStyle.Render(job.State)
+		case JobFailed:
+			state = ErrorStyle.Render(job.State)
+		case JobRunning:
+			state = WarningStyle.Render(job.State)
+		default:
+			state = SubtleTextStyle.Render(job.State)
+		}
+		cells := []string{
+			TableCellStyle.Width(28).Render(truncate(job.Kind, 26)),
+			TableCellStyle.Width(30).Render(truncate(job.Subject(), 28)),
+			TableCellStyle.Width(9).Render(fmt.Sprintf("%.0fs", job.Elapsed(now).Seconds())),
+			TableCellStyle.Width(10).Render(state),
+		}
+		if resultWidth > 10 {
+			cells = append(cells, TableCellStyle.Width(resultWidth).Render(truncate(oneLine(job.Message), resultWidth-2)))
+		}
+		row := lipgloss.JoinHorizontal(lipgloss.Top, cells...)
+		if i%2 == 0 {
+			rows = append(rows, TableRowEvenStyle.Render(row))
+		} else {
+			rows = append(rows, TableRowOddStyle.Render(row))
+		}
+	}
+
+	return TableStyle.Render(lipgloss.JoinVertical(lipgloss.Left, rows...))
+}
+
+// RenderSearchResults renders search matches, showing at most limit rows from offset with the cursor row highlighted
+func RenderSearchResults(matches []SearchMatch, cursor, offset, limit int) string {
+	header := TableHeaderStyle.Render(lipgloss.JoinHorizontal(
+		lipgloss.Top,
+		TableCellStyle.Width(44).Render("Resource"),
+		TableCellStyle.Width(26).Render("Entry"),
+		TableCellStyle.Width(20).Render("CIDR"),
+		TableCellStyle.Width(10).Render("Match"),
+	))
+
+	end := offset + limit
+	if limit <= 0 || end > len(matches) {
+		end = len(matches)
+	}
+
+	rows := []string{header}
+	for i := offset; i < end; i++ {
+		match := matches[i]
+		cellStyle := TableCellStyle
+		if i == cursor {
+			cellStyle = TableSelectedCellStyle
+		}
+
+		matchLabel := match.Match
+		if i != cursor {
+			switch match.Match {
+			case MatchExact:
+				matchLabel = SuccessStyle.Render(matchLabel)
+			case MatchContains:
+				matchLabel = WarningStyle.Render(matchLabel)
+			case MatchOpen:
+				matchLabel = ErrorStyle.Render(matchLabel)
+			}
+		}
+		cidr := match.CIDR
+		if match.Port != "" {
+			cidr += ":" + match.Port
+		}
+
+		row := lipgloss.JoinHorizontal(
+			lipgloss.Top,
+			cellStyle.Width(44).Render(truncate(fmt.Sprintf("%s %s/%s", match.Resource.Type, match.Resource.Project, match.Resource.Name), 42)),
+			cellStyle.Width(26).Render(truncate(match.Name, 24)),
+			cellStyle.Width(20).Render(truncate(cidr, 18)),
+			cellStyle.Width(10).Render(matchLabel),
+		)
+		if i%2 == 0 {
+			rows = append(rows, TableRowEvenStyle.Render(row))
+		} else {
+			rows = append(rows, TableRowOddStyle.Render(row))
+		}
+	}
+
+	return TableStyle.Render(lipgloss.JoinVertical(lipgloss.Left, rows...))
+}
+
+// RenderAccessTable renders whether the user's IP reaches each resource, showing at most limit rows
+// from offset with the cursor row highlighted
+func RenderAccessTable(statuses []AccessStatus, cursor, offset, limit int) string {
+	header := TableHeaderStyle.Render(lipgloss.JoinHorizontal(
+		lipgloss.Top,
+		TableCellStyle.Width(44).Render("Resource"),
+		TableCellStyle.Width(15).Render("Status"),
+		TableCellStyle.Width(32).Render("Access Via"),
+		TableCellStyle.Width(32).Render("Your Old Entries"),
+	))
+
+	end := offset + limit
+	if limit <= 0 || end > len(statuses) {
+		end = len(statuses)
+	}
+
+	rows := []string{header}
+	for i := offset; i < end; i++ {
+		status := statuses[i]
+		cellStyle := TableCellStyle
+		if i == cursor {
+			cellStyle = TableSelectedCellStyle
+		}
+
+		label := status.Status
+		if i != cursor {
+			switch status.Status {
+			case AccessReachable:
+				label = SuccessStyle.Render(label)
+			case AccessBlocked:
+				label = ErrorStyle.Render(label)
+			default:
+				label = SubtleTextStyle.Render(label)
+			}
+		}
+
+		via := ""
+		if status.Via != nil {
+			via = fmt.Sprintf("%s (%s)", networkName(*status.Via), status.Via.Value)
+		} else if status.Status == AccessReachable {
+			via = "open to every IP"
+		}
+
+		stale := ""
+		if len(status.Stale) > 0 {
+			stale = fmt.Sprintf("%s %s", networkName(status.Stale[0]), status.Stale[0].Value)
+			if len(status.Stale) > 1 {
+				stale += fmt.Sprintf(" +%d", len(status.Stale)-1)
+			}
+			stale = truncate(stale, 30)
+			if i != cursor {
+				stale = WarningStyle.Render(stale)
+			}
+		}
+
+		resource := status.Resource
+		row := lipgloss.JoinHorizontal(
+			lipgloss.Top,
+			cellStyle.Width(44).Render(truncate(fmt.Sprintf("%s %s (%s)", getResourceIcon(resource), resource.GetName(), resource.GetProject()), 42)),
+			cellStyle.Width(15).Render(label),
+			cellStyle.Width(32).Render(truncate(via, 30)),
+			cellStyle.Width(32).Render(stale),
+		)
+		if i%2 == 0 {
+			rows = append(rows, TableRowEvenStyle.Render(row))
+		} else {
+			rows = append(rows, TableRowOddStyle.Render(row))
+		}
+	}
+
+	return TableStyle.Render(lipgloss.JoinVertical(lipgloss.Left, rows...))
+}
+
+// truncate shortens text to at most width runes, marking the cut with an ellipsis
+func truncate(text string, width int) string {
+	runes := []rune(text)
+	if len(runes) <= width {
+		return text
+	}
+	return string(runes[:width-1]) + "…"
+}
+
+// RenderNetworkDiff renders a network diff with additions, removals and renames coloured
+func RenderNetworkDiff(diff NetworkDiff) string {
+	if diff.Empty() {
+		return SubtleTextStyle.Render("No changes")
+	}
+	return RenderDiffLines(diff.Lines())
+}
+
+// RenderDiffLines colours "+", "-" and "~" change lines such as NetworkDiff.Lines
+func RenderDiffLines(lines []string) string {
+	for i, line := range lines {
+		switch line[0] {
+		case '+':
+			lines[i] = SuccessStyle.Render(line)
+		case '-':
+			lines[i] = ErrorStyle.Render(line)
+		default:
+			lines[i] = WarningStyle.Render(line)
+		}
+	}
+	return lipgloss.JoinVertical(lipgloss.Left, lines...)
+}
+
+// RenderNetworkChange renders the full list a change will send, marking added (+) and
+// renamed (~) entries, followed by the removed (-) ones
+func RenderNetworkChange(before, after []AuthorizedNetwork) string {
+	beforeByValue, beforeOrder := indexNetworks(before)
+	afterByValue, afterOrder := indexNetworks(after)
+
+	var lines []string
+	for _, value := range afterOrder {
+		network := afterByValue[value]
+		old, existed := beforeByValue[value]
+		switch {
+		case !existed:
+			lines = append(lines, SuccessStyle.Render(fmt.Sprintf("+ %-20s %s", value, networkName(network))))
+		case networkName(old) != networkName(network):
+			lines = append(lines, WarningStyle.Render(fmt.Sprintf("~ %-20s %s → %s", value, networkName(old), networkName(network))))
+		default:
+			lines = append(lines, fmt.Sprintf("  %-20s %s", value, networkName(network)))
+		}
+	}
+	for _, value := range beforeOrder {
+		if _, kept := afterByValue[value]; !kept {
+			lines = append(lines, ErrorStyle.Render(fmt.Sprintf("- %-20s %s", value, networkName(beforeByValue[value]))))
+		}
+	}
+	if len(lines) == 0 {
+		return SubtleTextStyle.Render("No networks")
+	}
+	return lipgloss.JoinVertical(lipgloss.Left, lines...)
+}
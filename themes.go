@@ -0,0 +1,370 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/muesli/termenv"
+)
+
+// envTheme overrides the theme named in the config file
+const envTheme = "PIAM_ANC_THEME"
+
+// ThemeConfig selects the TUI's colours
+type ThemeConfig struct {
+	Name string `json:"name"` // auto (the default), a name from themes, or plain
+	File string `json:"file"` // JSON palette overriding some or all of the named theme's colours
+}
+
+// Catppuccin Macchiato color palette
+var CatppuccinMacchiato = Palette{
+	Rosewater: "#f4dbd6",
+	Flamingo:  "#f0c6c6",
+	Pink:      "#f5bde6",
+	Mauve:     "#c6a0f6",
+	Red:       "#ed8796",
+	Maroon:    "#ee99a0",
+	Peach:     "#f5a97f",
+	Yellow:    "#eed49f",
+	Green:     "#a6da95",
+	Teal:      "#8bd5ca",
+	Sky:       "#91d7e3",
+	Sapphire:  "#7dc4e4",
+	Blue:      "#8aadf4",
+	Lavender:  "#b7bdf8",
+	Text:      "#cad3f5",
+	Subtext1:  "#b8c0e0",
+	Subtext0:  "#a5adcb",
+	Overlay2:  "#939ab7",
+	Overlay1:  "#8087a2",
+	Overlay0:  "#6e738d",
+	Surface2:  "#5b6078",
+	Surface1:  "#494d64",
+	Surface0:  "#363a4f",
+	Base:      "#24273a",
+	Mantle:    "#1e2030",
+	Crust:     "#181926",
+}
+
+// Catppuccin Frappé color palette
+var CatppuccinFrappe = Palette{
+	Rosewater: "#f2d5cf",
+	Flamingo:  "#eebebe",
+	Pink:      "#f4b8e4",
+	Mauve:     "#ca9ee6",
+	Red:       "#e78284",
+	Maroon:    "#ea999c",
+	Peach:     "#ef9f76",
+	Yellow:    "#e5c890",
+	Green:     "#a6d189",
+	Teal:      "#81c8be",
+	Sky:       "#99d1db",
+	Sapphire:  "#85c1dc",
+	Blue:      "#8caaee",
+	Lavender:  "#babbf1",
+	Text:      "#c6d0f5",
+	Subtext1:  "#b5bfe2",
+	Subtext0:  "#a5adce",
+	Overlay2:  "#949cbb",
+	Overlay1:  "#838ba7",
+	Overlay0:  "#737994",
+	Surface2:  "#626880",
+	Surface1:  "#51576d",
+	Surface0:  "#414559",
+	Base:      "#303446",
+	Mantle:    "#292c3c",
+	Crust:     "#232634",
+}
+
+// Catppuccin Latte color palette, for light terminals
+var CatppuccinLatte = Palette{
+	Rosewater: "#dc8a78",
+	Flamingo:  "#dd7878",
+	Pink:      "#ea76cb",
+	Mauve:     "#8839ef",
+	Red:       "#d20f39",
+	Maroon:    "#e64553",
+	Peach:     "#fe640b",
+	Yellow:    "#df8e1d",
+	Green:     "#40a02b",
+	Teal:      "#179299",
+	Sky:       "#04a5e5",
+	Sapphire:  "#209fb5",
+	Blue:      "#1e66f5",
+	Lavender:  "#7287fd",
+	Text:      "#4c4f69",
+	Subtext1:  "#5c5f77",
+	Subtext0:  "#6c6f85",
+	Overlay2:  "#7c7f93",
+	Overlay1:  "#8c8fa1",
+	Overlay0:  "#9ca0b0",
+	Surface2:  "#acb0be",
+	Surface1:  "#bcc0cc",
+	Surface0:  "#ccd0da",
+	Base:      "#eff1f5",
+	Mantle:    "#e6e9ef",
+	Crust:     "#dce0e8",
+}
+
+// HighContrast is white text on black with saturated accents, for projectors and recordings
+var HighContrast = Palette{
+	Rosewater: "#ffd7d7",
+	Flamingo:  "#ffafaf",
+	Pink:      "#ff87ff",
+	Mauve:     "#d75fff",
+	Red:       "#ff5f5f",
+	Maroon:    "#ff8787",
+	Peach:     "#ffaf00",
+	Yellow:    "#ffff00",
+	Green:     "#00ff5f",
+	Teal:      "#00ffd7",
+	Sky:       "#5fffff",
+	Sapphire:  "#00d7ff",
+	Blue:      "#5fafff",
+	Lavender:  "#d7d7ff",
+	Text:      "#ffffff",
+	Subtext1:  "#ffffff",
+	Subtext0:  "#e4e4e4",
+	Overlay2:  "#d0d0d0",
+	Overlay1:  "#bcbcbc",
+	Overlay0:  "#a8a8a8",
+	Surface2:  "#ffffff",
+	Surface1:  "#3a3a3a",
+	Surface0:  "#262626",
+	Base:      "#000000",
+	Mantle:    "#000000",
+	Crust:     "#000000",
+}
+
+// themes are the built-in palettes by name
+var themes = map[string]Palette{
+	"mocha":         CatppuccinMocha,
+	"macchiato":     CatppuccinMacchiato,
+	"frappe":        CatppuccinFrappe,
+	"latte":         CatppuccinLatte,
+	"high-contrast": HighContrast,
+}
+
+// plainMode drops colours and emoji: set by NO_COLOR or the "plain" theme
+var plainMode bool
+
+// themeNames lists the names a theme can be given, for error messages and help
+func themeNames() []string {
+	names := []string{"auto", "plain"}
+	for name := range themes {
+		names = append(names, name)
+	}
+	sort.Strings(names[2:])
+	return names
+}
+
+// configureTheme applies the theme chosen by NO_COLOR, PIAM_ANC_THEME or the config file.
+// On error the default theme is applied, so a bad theme never stops piam-anc starting.
+func configureTheme(config ThemeConfig) error {
+	name := strings.ToLower(config.Name)
+	if env := os.Getenv(envTheme); env != "" {
+		name = strings.ToLower(env)
+	}
+
+	// https://no-color.org: any non-empty value turns colour off
+	if os.Getenv("NO_COLOR") != "" || name == "plain" {
+		plainMode = true
+		lipgloss.SetColorProfile(termenv.Ascii)
+		return nil
+	}
+
+	palette, err := namedPalette(name)
+	if err == nil && config.File != "" {
+		palette, err = loadPaletteFile(config.File, palette)
+	}
+	if err != nil {
+		palette, _ = namedPalette("auto")
+	}
+	applyPalette(palette)
+	return err
+}
+
+// namedPalette returns a built-in palette; auto picks Latte on light terminals and Mocha otherwise
+func namedPalette(name string) (Palette, error) {
+	switch name {
+	case "", "auto":
+		if lipgloss.HasDarkBackground() {
+			return CatppuccinMocha, nil
+		}
+		return CatppuccinLatte, nil
+	case "frappé":
+		name = "frappe"
+	}
+	palette, ok := themes[name]
+	if !ok {
+		return Palette{}, fmt.Errorf("unknown theme %q (choose from %s)", name, strings.Join(themeNames(), ", "))
+	}
+	return palette, nil
+}
+
+// colorPattern matches the colours a palette file may use: hex RGB or an ANSI colour number
+var colorPattern = regexp.MustCompile(`^(#[0-9a-fA-F]{6}|#[0-9a-fA-F]{3}|[0-9]{1,3})$`)
+
+// loadPaletteFile reads a custom palette. Colours missing from the file come from base, or
+// from the theme named by its "extends" key.
+func loadPaletteFile(path string, base Palette) (Palette, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return base, fmt.Errorf("failed to read theme %s: %v", path, err)
+	}
+
+	var header struct {
+		Extends string `json:"extends"`
+	}
+	if err := json.Unmarshal(data, &header); err != nil {
+		return base, fmt.Errorf("failed to parse theme %s: %v", path, err)
+	}
+	if header.Extends != "" {
+		if base, err = namedPalette(strings.ToLower(header.Extends)); err != nil {
+			return base, fmt.Errorf("theme %s: %v", path, err)
+		}
+	}
+
+	file := struct {
+		Extends string `json:"extends"`
+		Palette
+	}{Palette: base}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&file); err != nil {
+		return base, fmt.Errorf("failed to parse theme %s: %v", path, err)
+	}
+
+	var invalid []string
+	for name, color := range paletteColors(file.Palette) {
+		if !colorPattern.MatchString(color) {
+			invalid = append(invalid, fmt.Sprintf("%s %q", name, color))
+		}
+	}
+	if len(invalid) > 0 {
+		sort.Strings(invalid)
+		return base, fmt.Errorf("theme %s: invalid colours: %s", path, strings.Join(invalid, ", "))
+	}
+	return file.Palette, nil
+}
+
+// paletteColors returns a palette's colours by their JSON names
+func paletteColors(palette Palette) map[string]string {
+	data, _ := json.Marshal(palette)
+	colors := map[string]string{}
+	json.Unmarshal(data, &colors)
+	return colors
+}
+
+// plainReplacements keep the meaning of icons that say more than decoration
+var plainReplacements = map[string]string{
+	"🔒": "[private]",
+	"⚠": "!",
+	"✅": "✓",
+	"❌": "✗",
+}
+
+// isEmoji reports whether r is drawn as an emoji, or joins or modifies one. Symbols used as
+// plain text, such as ✓, ✗, • and arrows, are kept.
+func isEmoji(r rune) bool {
+	switch {
+	case r >= 0x1F000 && r <= 0x1FAFF: // Pictographs, emoticons, transport, flags
+		return true
+	case r >= 0x2600 && r <= 0x26FF: // Miscellaneous symbols, e.g. ☸ ⚡ ⚠
+		return true
+	case r >= 0x2700 && r <= 0x27BF: // Dingbats, e.g. ➕ ✏ ❤, except check and cross marks
+		return r < 0x2713 || r > 0x2718
+	case r >= 0x23E9 && r <= 0x23FA, r == 0x231A, r == 0x231B: // ⏳ ⏱ ⌛
+		return true
+	case r >= 0x2B05 && r <= 0x2B55, r == 0x2139: // ⭐ ℹ
+		return true
+	case r == 0xFE0E, r == 0xFE0F, r == 0x200D, r == 0x20E3: // Variation selectors and joiners
+		return true
+	}
+	return false
+}
+
+// isBoxDrawing reports whether r is part of a border or table rule
+func isBoxDrawing(r rune) bool {
+	return r >= 0x2500 && r <= 0x257F
+}
+
+// plainText removes emoji from rendered text for plain mode. Each emoji is dropped along with
+// the spaces after it, and the width it took is given back just before the next border on
+// the line, so boxes and table columns stay aligned.
+func plainText(text string) string {
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		lines[i] = plainLine(line)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// plainLine removes the emoji from one line of rendered text
+func plainLine(line string) string {
+	var out strings.Builder
+	pad := 0 // Width to give back before the next border
+	for len(line) > 0 {
+		r, size := utf8.DecodeRuneInString(line)
+		if !isEmoji(r) {
+			if isBoxDrawing(r) {
+				pad = givePadding(&out, pad)
+			}
+			out.WriteString(line[:size])
+			line = line[size:]
+			continue
+		}
+
+		// Take the whole emoji sequence, and the spaces after it unless text replaces it
+		end := 0
+		for end < len(line) {
+			r, size := utf8.DecodeRuneInString(line[end:])
+			if !isEmoji(r) {
+				break
+			}
+			end += size
+		}
+		emoji := line[:end]
+		replacement := plainReplacements[strings.TrimRight(emoji, "\ufe0e\ufe0f")]
+		if replacement == "" {
+			for end < len(line) && line[end] == ' ' {
+				end++
+			}
+		} else if out.Len() > 0 && !strings.HasSuffix(out.String(), " ") {
+			replacement = " " + replacement
+		}
+		removed := line[:end]
+		line = line[end:]
+		out.WriteString(replacement)
+		pad += lipgloss.Width(removed) - lipgloss.Width(replacement)
+	}
+	if pad > 0 && strings.ContainsFunc(out.String(), isBoxDrawing) {
+		out.WriteString(strings.Repeat(" ", pad))
+	}
+	return out.String()
+}
+
+// givePadding writes back the width removed since the last border, taking it from the
+// spaces already written when replacements made the line wider
+func givePadding(out *strings.Builder, pad int) int {
+	if pad > 0 {
+		out.WriteString(strings.Repeat(" ", pad))
+		return 0
+	}
+	text := out.String()
+	trimmed := strings.TrimRight(text, " ")
+	spaces := len(text) - len(trimmed)
+	// Keep one space between the text and the border
+	if take := min(-pad, spaces-1); take > 0 {
+		out.Reset()
+		out.WriteString(text[:len(text)-take])
+	}
+	return 0
+}
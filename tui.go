@@ -2,8 +2,11 @@ package main
 
 import (
 	"context"
+	"encoding/base64"
+	"errors"
 	"fmt"
 	"io"
+	"net"
 	"net/http"
 	"os"
 	"os/exec"
@@ -12,6 +15,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/charmbracelet/bubbles/key"
 	"github.com/charmbracelet/bubbles/list"
 	"github.com/charmbracelet/bubbles/spinner"
 	"github.com/charmbracelet/bubbles/textinput"
@@ -27,25 +31,72 @@ const (
 	stateResourceSelection
 	stateNetworkView
 	stateAddNetwork
+	stateRemoveNetwork
+	stateAddPSCProject
+	stateRemovePSCProject
+	stateConfirm
+	stateHistory
+	stateRequestAccess
+	stateRequests
+	stateSearch
+	stateMyAccess
 	stateError
 )
 
+// Network view tabs for SQL instances
+const (
+	networkTabAuthorized = iota
+	networkTabPSC
+)
+
 // Model represents the main TUI model
 type Model struct {
-	state           sessionState
-	width           int
-	height          int
-	spinner         spinner.Model
-	resourceList    list.Model
-	networkManager  *NetworkManager
+	state            sessionState
+	width            int
+	height           int
+	spinner          spinner.Model
+	resourceList     list.Model
+	networkManager   *NetworkManager
+	config           *Config
+	policy           *Policy
+	groups           *NetworkGroups
 	selectedResource CloudResource
-	resources       []CloudResource
-	
+	resources        []CloudResource
+
 	// Add network form
 	nameInput    textinput.Model
 	ipInput      textinput.Model
 	addFormFocus int
+	addGroup     int // 0 to type a CIDR, n to add the n-th configured group
 	
+	// Apply add/remove to the SQL primary and all its replicas
+	applyToReplicas bool
+
+	// Private Service Connect tab and form
+	networkTab int
+	pscInput   textinput.Model
+
+	// Navigable network table, and the entry the add form is editing
+	table   networkTable
+	editing *AuthorizedNetwork
+
+	// Flips whether the resource detail pane shows: by default it does when it fits beside the table
+	detailsToggled bool
+
+	// Set while a form waits for a busy resource, e.g. "my-cluster to leave RECONCILING"
+	waitingFor string
+
+	// Key bindings, from the config file
+	keys KeyMap
+
+	// Confirmed changes running in the background, and toasts for the ones that finished
+	jobs        []Job
+	nextJobID   int
+	showJobs    bool
+	jobsTicking bool
+	toasts      []toast
+	quitPending bool // Quit was pressed once while jobs were running
+
 	// Status and errors
 	message     string
 	isError     bool
@@ -54,9 +105,61 @@ type Model struct {
 	submitStartTime time.Time
 	
 	// Navigation
-	activeTab   int
-	showHelp    bool
-	
+	showHelp bool
+
+	// Resource list tab (empty for all types), grouping, sort mode and collapsed groups
+	activeTab ResourceType
+	groupBy   string
+	sortBy    string
+	collapsed map[string]bool
+
+	// Pending action awaiting explicit confirmation
+	confirm *confirmation
+
+	// Who added each network of the selected resource, from Cloud Audit Logs
+	attribution    map[string]NetworkAttribution
+	attributionErr string
+
+	// Audit log history view
+	history            []AuditEntry
+	historyOffset      int
+	historyReturnState sessionState
+
+	// Access requests: the request form and the approver queue
+	reasonInput        textinput.Model
+	commentInput       textinput.Model
+	requests           []AccessRequest
+	requestCursor      int
+	requestReturnState sessionState
+	requestDir         string
+	rejecting          bool
+
+	// Estate-wide search for the entries authorizing an IP or CIDR
+	searchInput       textinput.Model
+	searchQuery       *net.IPNet // Last query run; nil before the first
+	searchMatches     []SearchMatch
+	searchCursor      int
+	searchOffset      int
+	searchReturnState sessionState
+
+	// "Where do I have access": what the user's current IP reaches
+	accessIP          string
+	accessNames       []string
+	access            []AccessStatus
+	accessCursor      int
+	accessOffset      int
+	accessLoading     bool
+	accessReturnState sessionState
+}
+
+// confirmation describes a risky action the user must approve before it runs
+type confirmation struct {
+	title       string
+	subject     string // Shown under the title; defaults to the selected resource
+	body        string
+	action      jobAction
+	resources   []CloudResource // Resources the change applies to, refreshed when its job finishes
+	returnState sessionState
 }
 
 // List item for resources
@@ -75,6 +178,10 @@ func (r resourceItem) FilterValue() string {
 
 func (r resourceItem) Title() string {
 	title := fmt.Sprintf("%s %s (%s)", getResourceIcon(r.resource), r.resource.GetName(), r.resource.GetProject())
+	if instance, ok := r.resource.(SQLInstance); ok && instance.IsReplica() {
+		// Replicas are listed right below their primary
+		title = "  ↳ " + title
+	}
 	if !r.resource.CanAddNetwork() {
 		title += " 🔒"
 	}
@@ -84,7 +191,7 @@ func (r resourceItem) Title() string {
 func (r resourceItem) Description() string {
 	// Format with consistent width for alignment
 	region := fmt.Sprintf("%-12s", r.resource.GetRegion())
-	resourceType := fmt.Sprintf("%-3s", r.resource.GetType())
+	resourceType := fmt.Sprintf("%-5s", r.resource.GetType())
 	
 	var networkCount int
 	var desc string
@@ -96,8 +203,8 @@ func (r resourceItem) Description() string {
 		desc = fmt.Sprintf("%s • %s • %d networks", region, resourceType, networkCount)
 		// Apply SQL-specific background styling
 		desc = lipgloss.NewStyle().
-			Background(lipgloss.Color(CatppuccinMocha.Surface0)).
-			Foreground(lipgloss.Color(CatppuccinMocha.Blue)).
+			Background(lipgloss.Color(Colors.Surface0)).
+			Foreground(lipgloss.Color(Colors.Blue)).
 			Padding(0, 1).
 			Render(desc)
 	case GKECluster:
@@ -105,17 +212,42 @@ func (r resourceItem) Description() string {
 		desc = fmt.Sprintf("%s • %s • %d networks", region, resourceType, networkCount)
 		// Apply GKE-specific background styling
 		desc = lipgloss.NewStyle().
-			Background(lipgloss.Color(CatppuccinMocha.Surface1)).
-			Foreground(lipgloss.Color(CatppuccinMocha.Mauve)).
+			Background(lipgloss.Color(Colors.Surface1)).
+			Foreground(lipgloss.Color(Colors.Mauve)).
+			Padding(0, 1).
+			Render(desc)
+	case AWSSecurityGroup:
+		networkCount = len(res.AuthorizedNetworks)
+		desc = fmt.Sprintf("%s • %s • %d networks", region, resourceType, networkCount)
+		// Apply AWS-specific background styling
+		desc = lipgloss.NewStyle().
+			Background(lipgloss.Color(Colors.Surface0)).
+			Foreground(lipgloss.Color(Colors.Peach)).
+			Padding(0, 1).
+			Render(desc)
+	case AzureDatabaseServer:
+		networkCount = len(res.AuthorizedNetworks)
+		desc = fmt.Sprintf("%s • %s • %d networks", region, resourceType, networkCount)
+		// Apply Azure-specific background styling
+		desc = lipgloss.NewStyle().
+			Background(lipgloss.Color(Colors.Surface1)).
+			Foreground(lipgloss.Color(Colors.Sapphire)).
 			Padding(0, 1).
 			Render(desc)
 	default:
 		desc = fmt.Sprintf("%s • %s", region, resourceType)
 	}
 	
+	if instance, ok := r.resource.(SQLInstance); ok && instance.IsReplica() {
+		desc += " " + SubtleTextStyle.Render("replica of "+instance.MasterInstanceName)
+	}
+	if displayState(r.resource) != "" {
+		desc += " " + RenderResourceState(r.resource)
+	}
+
 	// Add restrictions if any
 	if restrictions := r.resource.GetNetworkRestrictions(); restrictions != "" {
-		desc += "\n" + lipgloss.NewStyle().Foreground(lipgloss.Color(CatppuccinMocha.Yellow)).Render("⚠️  " + restrictions)
+		desc += "\n" + lipgloss.NewStyle().Foreground(lipgloss.Color(Colors.Yellow)).Render("⚠️  "+restrictions)
 	}
 	
 	return desc
@@ -165,22 +297,34 @@ func getUserName() string {
 func getConsoleURL(resource CloudResource) string {
 	switch r := resource.(type) {
 	case SQLInstance:
-		return fmt.Sprintf("https://console.cloud.google.com/sql/instances/%s/edit?project=%s", 
+		return fmt.Sprintf("https://console.cloud.google.com/sql/instances/%s/edit?project=%s",
 			r.Name, r.Project)
 	case GKECluster:
-		return fmt.Sprintf("https://console.cloud.google.com/kubernetes/clusters/details/%s/%s?project=%s", 
+		return fmt.Sprintf("https://console.cloud.google.com/kubernetes/clusters/details/%s/%s?project=%s",
 			r.Location, r.Name, r.Project)
+	case AWSSecurityGroup:
+		return fmt.Sprintf("https://%s.console.aws.amazon.com/ec2/home?region=%s#SecurityGroup:groupId=%s",
+			r.Region, r.Region, r.GroupID)
+	case AzureDatabaseServer:
+		return fmt.Sprintf("https://portal.azure.com/#@/resource%s/networking", r.ID)
 	default:
 		return ""
 	}
 }
 
 func getResourceIcon(resource CloudResource) string {
+	if plainMode {
+		return "[" + string(resource.GetType()) + "]"
+	}
 	switch resource.GetType() {
 	case ResourceTypeSQL:
 		return "🗄️"
 	case ResourceTypeGKE:
 		return "☸️"
+	case ResourceTypeAWS:
+		return "🛡️"
+	case ResourceTypeAzure:
+		return "🔷"
 	default:
 		return "📦"
 	}
@@ -200,6 +344,68 @@ type networkAddedMsg struct {
 	message string
 }
 
+type networkRemovedMsg struct {
+	success bool
+	message string
+}
+
+type pscUpdatedMsg struct {
+	success bool
+	message string
+}
+
+type settingsUpdatedMsg struct {
+	success bool
+	message string
+}
+
+type attributionLoadedMsg struct {
+	key         string
+	attribution map[string]NetworkAttribution
+	err         error
+}
+
+type undoLoadedMsg struct {
+	snapshot *Snapshot
+	diff     NetworkDiff
+	settings []string // PSC and GKE access changes, from Snapshot.SettingChanges
+}
+
+type historyLoadedMsg struct {
+	entries []AuditEntry
+}
+
+type previewLoadedMsg struct {
+	confirm     *confirmation
+	returnState sessionState
+	err         error
+}
+
+type requestSubmittedMsg struct {
+	success bool
+	message string
+}
+
+type requestsLoadedMsg struct {
+	requests []AccessRequest
+	dir      string
+}
+
+type requestDecidedMsg struct {
+	success bool
+	message string
+}
+
+type accessLoadedMsg struct {
+	ip    string
+	names []string
+}
+
+type clipboardMsg struct {
+	success bool
+	message string
+}
+
 type errorMsg struct {
 	err error
 }
@@ -226,19 +432,189 @@ func initialModel() Model {
 	ipInput.CharLimit = 20
 	ipInput.Width = 20
 	
+	// Create PSC consumer project input
+	pscInput := textinput.New()
+	pscInput.Placeholder = "consumer-project-id"
+	pscInput.CharLimit = 30
+	pscInput.Width = 30
+
+	// Create access request inputs
+	reasonInput := textinput.New()
+	reasonInput.Placeholder = "Why do you need access?"
+	reasonInput.CharLimit = 200
+	reasonInput.Width = 50
+
+	commentInput := textinput.New()
+	commentInput.Placeholder = "Reason for rejecting"
+	commentInput.CharLimit = 200
+	commentInput.Width = 50
+
+	// Create search input
+	searchInput := textinput.New()
+	searchInput.Placeholder = "198.51.100.23 or 10.0.0.0/8"
+	searchInput.CharLimit = 43
+	searchInput.Width = 43
+
 	// Create resource list
 	resourceList := list.New([]list.Item{}, list.NewDefaultDelegate(), 0, 0)
-	resourceList.Title = "Select Cloud Resource (type to search)"
+	resourceList.Title = "Select Cloud Resource (/ to search by name or type:sql public:true networks>20 ...)"
 	resourceList.SetShowStatusBar(false)
 	resourceList.SetFilteringEnabled(true)
+	keyMap.applyListKeys(&resourceList)
 	
-	return Model{
+	m := Model{
 		state:        stateLoading,
 		spinner:      s,
 		resourceList: resourceList,
 		nameInput:    nameInput,
 		ipInput:      ipInput,
+		pscInput:     pscInput,
+		reasonInput:  reasonInput,
+		commentInput: commentInput,
+		searchInput:  searchInput,
+		table:        newNetworkTable(),
+		collapsed:    make(map[string]bool),
+		keys:         keyMap,
+	}
+
+	// Reopen the resource list the way it was left
+	prefs := LoadPrefs()
+	m.activeTab = prefs.ResourceTab
+	m.groupBy = prefs.GroupBy
+	m.sortBy = prefs.SortBy
+
+	// Config problems are reported again by NewNetworkManager when loading resources
+	if config, err := LoadConfig(); err == nil {
+		m.config = config
+		m.policy, _ = NewPolicy(config.Policy)
+		m.groups, _ = NewNetworkGroups(config.Groups)
+	}
+
+	return m
+}
+
+// selectedGroup returns the group picked in the add form, if any
+func (m Model) selectedGroup() (NetworkGroup, bool) {
+	groups := m.groups.All()
+	if m.addGroup < 1 || m.addGroup > len(groups) {
+		return NetworkGroup{}, false
+	}
+	return groups[m.addGroup-1], true
+}
+
+// addFormViolations evaluates the add form's current values against policy
+func (m Model) addFormViolations() []PolicyViolation {
+	if group, ok := m.selectedGroup(); ok && m.selectedResource != nil {
+		var policyErr *PolicyError
+		if _, _, err := groupNetworksToAdd(m.selectedResource, group, m.policy); errors.As(err, &policyErr) {
+			return policyErr.Violations
+		}
+		return nil
+	}
+
+	ip := strings.TrimSpace(m.ipInput.Value())
+	if ip == "" || m.selectedResource == nil {
+		return nil
+	}
+	if m.editing != nil {
+		var policyErr *PolicyError
+		if _, _, err := editedNetworks(m.selectedResource, m.editing.Value, m.nameInput.Value(), ip, m.policy); errors.As(err, &policyErr) {
+			return policyErr.Violations
+		}
+		return nil
+	}
+	return m.policy.Evaluate(m.selectedResource, strings.TrimSpace(m.nameInput.Value()), ip, resourceNetworks(m.selectedResource))
+}
+
+// replicaGroup returns the selected SQL instance's primary and replicas, or nil for other resources
+func (m Model) replicaGroup() []CloudResource {
+	instance, ok := m.selectedResource.(SQLInstance)
+	if !ok {
+		return nil
+	}
+	return relatedSQLInstances(m.resources, instance)
+}
+
+// targetResources returns the resources an add or remove should apply to
+func (m Model) targetResources() []CloudResource {
+	if m.applyToReplicas {
+		if group := m.replicaGroup(); len(group) > 1 {
+			return group
+		}
+	}
+	return []CloudResource{m.selectedResource}
+}
+
+// renderReplicaToggle renders the "apply to primary and all replicas" option for forms
+func (m Model) renderReplicaToggle() string {
+	group := m.replicaGroup()
+	if len(group) <= 1 {
+		return ""
+	}
+
+	names := make([]string, len(group))
+	for i, resource := range group {
+		names[i] = resource.GetName()
+	}
+
+	checkbox := "[ ]"
+	if m.applyToReplicas {
+		checkbox = "[x]"
+	}
+	return lipgloss.JoinVertical(
+		lipgloss.Left,
+		LabelStyle.Render(fmt.Sprintf("%s Apply to primary and all replicas (%s)", checkbox, firstKey(m.keys.Replicas))),
+		SubtleTextStyle.Render(strings.Join(names, ", ")),
+	)
+}
+
+// setRequestFormFocus focuses one of the access request form's name, IP and reason fields
+func (m *Model) setRequestFormFocus(focus int) {
+	m.addFormFocus = focus
+	m.nameInput.Blur()
+	m.ipInput.Blur()
+	m.reasonInput.Blur()
+	switch focus {
+	case 0:
+		m.nameInput.Focus()
+	case 1:
+		m.ipInput.Focus()
+	case 2:
+		m.reasonInput.Focus()
+	}
+}
+
+// findResource returns the discovered resource a reference points at, or nil
+func (m Model) findResource(ref ResourceRef) CloudResource {
+	for _, resource := range m.resources {
+		if sameResource(resourceRefOf(resource), ref) {
+			return resource
+		}
+	}
+	return nil
+}
+
+// typing reports whether a text input has focus, so that printable keys are text rather than commands
+func (m Model) typing() bool {
+	switch m.state {
+	case stateResourceSelection:
+		return m.resourceList.FilterState() == list.Filtering
+	case stateNetworkView:
+		return m.table.filtering
+	case stateAddNetwork:
+		return m.addGroup == 0 && m.addFormFocus >= 0
+	case stateRemoveNetwork, stateAddPSCProject, stateRemovePSCProject, stateRequestAccess, stateSearch:
+		return true
+	case stateRequests:
+		return m.rejecting
 	}
+	return false
+}
+
+// pscTabInstance returns the selected SQL instance when its PSC tab is active
+func (m Model) pscTabInstance() (SQLInstance, bool) {
+	instance, ok := m.selectedResource.(SQLInstance)
+	return instance, ok && m.networkTab == networkTabPSC
 }
 
 // Init command
@@ -259,25 +635,147 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.width = msg.Width
 		m.height = msg.Height
 		m.resourceList.SetWidth(msg.Width - 4)
-		m.resourceList.SetHeight(msg.Height - 10)
+		m.resizeResourceList()
 		
 	case tea.KeyMsg:
-		switch msg.String() {
-		case "ctrl+c", "q":
+		// The network table's filter, actions menu and details take every key while open
+		if m.state == stateNetworkView && m.table.capturesKeys() && !commandKey(msg, true, m.keys.Quit) {
+			return m.updateNetworkTable(msg)
+		}
+		// The search input always has focus
+		if m.state == stateSearch && !commandKey(msg, true, m.keys.Quit) {
+			return m.updateSearch(msg)
+		}
+		// Printable keys are text while an input has focus; only keys such as Enter, Esc and Ctrl+C act
+		if m.typing() && typedKey(msg) {
+			break
+		}
+
+		// Quitting stops any running jobs, so it takes a second press while there are some
+		quitPending := m.quitPending
+		m.quitPending = false
+		switch {
+		case key.Matches(msg, m.keys.Quit):
+			if n := m.activeJobs(); n > 0 && !quitPending {
+				m.quitPending = true
+				m.message = fmt.Sprintf("%d change(s) still running - press %s again to quit and abandon them", n, firstKey(m.keys.Quit))
+				m.isError = true
+				return m, nil
+			}
 			return m, tea.Quit
-		case "?":
+		case key.Matches(msg, m.keys.Jobs):
+			if len(m.jobs) > 0 {
+				m.showJobs = !m.showJobs
+				m.resizeResourceList()
+			}
+		case key.Matches(msg, m.keys.Help):
 			if m.state != stateLoading && m.state != stateError {
 				m.showHelp = !m.showHelp
 			}
-		case "esc":
+		case key.Matches(msg, m.keys.Back):
 			if m.showHelp {
 				m.showHelp = false
+			} else if m.waitingFor != "" {
+				m.waitingFor = ""
+				m.isSubmitting = false
+				m.message = "Stopped waiting - nothing was changed"
+				m.isError = false
+			} else if m.state == stateNetworkView && m.table.filter.Value() != "" {
+				m.table.reset()
+			} else if m.state == stateNetworkView && m.detailPaneReplacesBody() {
+				m.detailsToggled = false
+			} else if m.state == stateAddNetwork && m.editing != nil && !m.isSubmitting {
+				m.state = stateNetworkView
+				m.editing = nil
+				m.message = ""
 			} else if m.state == stateNetworkView || m.state == stateAddNetwork {
 				m.state = stateResourceSelection
+				m.networkTab = 0
+				m.message = ""
+			} else if (m.state == stateAddPSCProject || m.state == stateRemovePSCProject) && !m.isSubmitting {
+				m.state = stateNetworkView
+				m.message = ""
+			} else if m.state == stateRemoveNetwork && !m.isSubmitting {
+				m.state = stateNetworkView
+				m.message = ""
+			} else if m.state == stateHistory {
+				m.state = m.historyReturnState
 				m.message = ""
+			} else if m.state == stateMyAccess && !m.isSubmitting {
+				m.state = m.accessReturnState
+				m.message = ""
+			} else if m.state == stateRequestAccess && !m.isSubmitting {
+				m.state = stateNetworkView
+				m.message = ""
+			} else if m.state == stateRequests && !m.isSubmitting {
+				if m.rejecting {
+					m.rejecting = false
+					m.commentInput.Blur()
+				} else {
+					m.state = m.requestReturnState
+				}
+				m.message = ""
+			} else if m.state == stateConfirm && !m.isSubmitting {
+				m.state = m.confirm.returnState
+				m.confirm = nil
+				m.message = "Cancelled"
+				m.isError = false
+			}
+		case key.Matches(msg, m.keys.Confirm):
+			// Nothing is ever sent in dry-run mode, so confirmations only preview
+			if m.state == stateConfirm && !m.isSubmitting && !dryRunMode {
+				kind := jobKind(m.confirm.title)
+				cmd := m.enqueueJob(kind, m.confirm.resources, m.confirm.action)
+				// Forms close once their change is queued; other views stay where they were
+				m.state = m.confirm.returnState
+				if m.state == stateAddNetwork || m.state == stateRemoveNetwork {
+					m.state = stateNetworkView
+					m.editing = nil
+				}
+				m.confirm = nil
+				m.message = fmt.Sprintf("Queued: %s - it runs in the background", kind)
+				m.isError = false
+				return m, cmd
+			}
+		case key.Matches(msg, m.keys.Cancel):
+			if m.state == stateConfirm && !m.isSubmitting {
+				m.state = m.confirm.returnState
+				m.confirm = nil
+				m.message = "Cancelled"
+				m.isError = false
 			}
-		case "a":
+		case key.Matches(msg, m.keys.ToggleNetworks):
 			if m.state == stateNetworkView {
+				if cluster, ok := m.selectedResource.(GKECluster); ok {
+					m.confirm = confirmMasterAuthorizedNetworks(cluster)
+					m.state = stateConfirm
+				}
+			}
+		case key.Matches(msg, m.keys.ToggleGoogleIPs):
+			if m.state == stateNetworkView {
+				if cluster, ok := m.selectedResource.(GKECluster); ok {
+					if !cluster.MasterAuthorizedNetworksEnabled {
+						m.message = fmt.Sprintf("Enable master authorized networks first (press %s)", firstKey(m.keys.ToggleNetworks))
+						m.isError = true
+					} else {
+						m.confirm = confirmGCPPublicCidrsAccess(cluster)
+						m.state = stateConfirm
+					}
+				}
+			}
+		case key.Matches(msg, m.keys.Add):
+			if instance, ok := m.pscTabInstance(); ok && m.state == stateNetworkView {
+				if !instance.PSCEnabled {
+					m.message = "Private Service Connect is not enabled on this instance"
+					m.isError = true
+				} else {
+					m.state = stateAddPSCProject
+					m.pscInput.Reset()
+					m.pscInput.Focus()
+					// Don't let the 'a' keypress reach the input
+					return m, nil
+				}
+			} else if m.state == stateNetworkView {
 				if m.selectedResource.CanAddNetwork() {
 					m.state = stateAddNetwork
 					m.addFormFocus = 0
@@ -294,6 +792,10 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 						m.ipInput.SetValue(publicIP)
 					}
 					
+					m.applyToReplicas = false
+					m.addGroup = 0
+					m.editing = nil
+
 					// Don't auto-focus to prevent 'a' from being typed in field
 					m.nameInput.Blur()
 					m.ipInput.Blur()
@@ -303,23 +805,195 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 					m.isError = true
 				}
 			}
-		case "c":
+		case key.Matches(msg, m.keys.Remove):
+			if instance, ok := m.pscTabInstance(); ok && m.state == stateNetworkView {
+				if len(instance.AllowedConsumerProjects) == 0 {
+					m.message = "No allowed consumer projects to remove"
+					m.isError = true
+				} else {
+					m.state = stateRemovePSCProject
+					m.pscInput.Reset()
+					m.pscInput.Focus()
+					return m, nil
+				}
+			} else if m.state == stateNetworkView {
+				if len(resourceNetworks(m.selectedResource)) == 0 {
+					m.message = "No authorized networks to remove"
+					m.isError = true
+				} else {
+					m.state = stateRemoveNetwork
+					m.applyToReplicas = false
+					m.ipInput.Reset()
+					if network, ok := m.selectedNetwork(); ok {
+						m.ipInput.SetValue(network.Value)
+					}
+					m.ipInput.Focus()
+					// Don't let the 'd' keypress reach the input
+					return m, nil
+				}
+			}
+		case key.Matches(msg, m.keys.Replicas):
+			if (m.state == stateAddNetwork || m.state == stateRemoveNetwork) && !m.isSubmitting && m.editing == nil && len(m.replicaGroup()) > 1 {
+				m.applyToReplicas = !m.applyToReplicas
+				return m, nil
+			}
+		case key.Matches(msg, m.keys.NetworkGroup):
+			if m.state == stateAddNetwork && !m.isSubmitting && m.editing == nil && len(m.groups.All()) > 0 {
+				// Cycle through the configured groups and back to typing a CIDR
+				m.addGroup = (m.addGroup + 1) % (len(m.groups.All()) + 1)
+				m.addFormFocus = -1
+				m.nameInput.Blur()
+				m.ipInput.Blur()
+				return m, nil
+			}
+		case key.Matches(msg, m.keys.Undo):
+			if m.state == stateNetworkView {
+				m.message = "Looking for the last change..."
+				m.isError = false
+				return m, loadUndo(m.selectedResource)
+			}
+		case key.Matches(msg, m.keys.History):
+			if m.state == stateNetworkView || m.state == stateResourceSelection {
+				// From a resource, show only its history
+				var resource CloudResource
+				if m.state == stateNetworkView {
+					resource = m.selectedResource
+				}
+				m.historyReturnState = m.state
+				m.history = nil
+				m.historyOffset = 0
+				m.state = stateHistory
+				return m, loadHistory(resource)
+			}
+		case key.Matches(msg, m.keys.RequestAccess):
+			if _, onPSCTab := m.pscTabInstance(); m.state == stateNetworkView && !onPSCTab {
+				m.state = stateRequestAccess
+				m.nameInput.Reset()
+				m.ipInput.Reset()
+				m.reasonInput.Reset()
+				m.addGroup = 0
+				if username := getUserName(); username != "" {
+					m.nameInput.SetValue(username)
+				}
+				if publicIP := getPublicIP(); publicIP != "" {
+					m.ipInput.SetValue(publicIP)
+				}
+				m.addFormFocus = 2
+				m.nameInput.Blur()
+				m.ipInput.Blur()
+				m.reasonInput.Focus()
+				// Don't let the 'R' keypress reach the input
+				return m, nil
+			}
+		case key.Matches(msg, m.keys.Requests):
+			if m.state == stateNetworkView || m.state == stateResourceSelection {
+				m.requestReturnState = m.state
+				m.requests = nil
+				m.requestCursor = 0
+				m.rejecting = false
+				m.state = stateRequests
+				return m, loadRequests
+			}
+		case key.Matches(msg, m.keys.SearchIP):
+			if m.state == stateNetworkView || m.state == stateResourceSelection {
+				m.searchReturnState = m.state
+				m.searchInput.Reset()
+				// Start from the highlighted entry: where else is it allowed?
+				if _, onPSCTab := m.pscTabInstance(); m.state == stateNetworkView && !onPSCTab {
+					if network, ok := m.selectedNetwork(); ok {
+						m.searchInput.SetValue(network.Value)
+					}
+				}
+				m.searchQuery = nil
+				m.searchMatches = nil
+				m.searchCursor = 0
+				m.searchOffset = 0
+				m.message = ""
+				m.state = stateSearch
+				return m, m.searchInput.Focus()
+			}
+		case key.Matches(msg, m.keys.MyAccess):
+			if m.state == stateNetworkView || m.state == stateResourceSelection {
+				m.accessReturnState = m.state
+				m.access = nil
+				m.accessCursor = 0
+				m.accessOffset = 0
+				m.accessLoading = true
+				m.message = ""
+				m.state = stateMyAccess
+				return m, loadMyAccess
+			}
+		case key.Matches(msg, m.keys.GroupBy):
+			if m.state == stateResourceSelection {
+				m.groupBy = nextChoice(resourceGroupings, m.groupBy)
+				return m, tea.Batch(m.refreshResourceList(), m.savePrefs())
+			}
+		case key.Matches(msg, m.keys.SortBy):
+			if m.state == stateResourceSelection {
+				m.sortBy = nextChoice(resourceSorts, m.sortBy)
+				return m, tea.Batch(m.refreshResourceList(), m.savePrefs())
+			}
+		case key.Matches(msg, m.keys.Reject):
+			if m.state == stateRequests && !m.rejecting && !m.isSubmitting && len(m.requests) > 0 {
+				m.rejecting = true
+				m.commentInput.Reset()
+				m.commentInput.Focus()
+				return m, nil
+			}
+		case key.Matches(msg, m.keys.Details):
+			if m.state == stateNetworkView {
+				m.detailsToggled = !m.detailsToggled
+				return m, nil
+			}
+		case key.Matches(msg, m.keys.Console):
 			if m.state == stateNetworkView {
 				// Open console URL
 				return m, openConsoleURL(m.selectedResource)
 			}
-		case "r":
-			if m.state == stateResourceSelection || m.state == stateNetworkView {
+		case key.Matches(msg, m.keys.Refresh):
+			if m.state == stateNetworkView || m.state == stateResourceSelection {
 				m.state = stateLoading
 				return m, loadResources
 			}
-		case "enter":
+		case key.Matches(msg, m.keys.Select):
 			if m.state == stateResourceSelection {
-				if i, ok := m.resourceList.SelectedItem().(resourceItem); ok {
+				switch i := m.resourceList.SelectedItem().(type) {
+				case resourceItem:
 					return m, selectResource(i.resource)
+				case groupHeaderItem:
+					m.collapsed[i.key] = !m.collapsed[i.key]
+					return m, m.refreshResourceList()
 				}
-			} else if m.state == stateAddNetwork {
-				if m.addFormFocus == -1 {
+			} else if m.submitsOnEnter() && m.unreadyTarget() != nil {
+				// The API rejects changes to resources mid-operation or stopped, so don't send any
+				stateErr := m.unreadyTarget()
+				if !stateErr.Busy {
+					m.message = stateErr.Error()
+					m.isError = true
+				} else {
+					m.waitingFor = fmt.Sprintf("%s to leave %s", stateErr.Resource.GetName(), stateErr.State)
+					m.message = fmt.Sprintf("⏳ Waiting for %s... (0s) - %s to stop", m.waitingFor, firstKey(m.keys.Back))
+					m.isError = false
+					m.isSubmitting = true
+					m.submitStartTime = time.Now()
+					return m, tea.Batch(waitUntilReady(m.targetResources()), tickCmd())
+				}
+			} else if m.state == stateAddNetwork && !m.isSubmitting {
+				if group, ok := m.selectedGroup(); ok {
+					if len(m.addFormViolations()) > 0 {
+						m.message = "Fix the policy violations above before submitting"
+						m.isError = true
+					} else {
+						m.message = "Computing preview..."
+						m.isError = false
+						m.isSubmitting = true
+						return m, previewChange("➕ Add Network Group", m.targetResources(), stateAddNetwork,
+							func(nm *NetworkManager, resource CloudResource) (*ChangePreview, error) {
+								return nm.PreviewAddGroup(resource, group)
+							},
+							submitAddGroup(m.targetResources(), group))
+					}
+				} else if m.addFormFocus == -1 {
 					// First enter focuses name field
 					m.addFormFocus = 0
 					m.nameInput.Focus()
@@ -328,20 +1002,107 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 					m.addFormFocus = 1
 					m.nameInput.Blur()
 					m.ipInput.Focus()
+				} else if m.addFormFocus == 1 && len(m.addFormViolations()) > 0 {
+					m.message = "Fix the policy violations above before submitting"
+					m.isError = true
+				} else if m.addFormFocus == 1 && strings.TrimSpace(m.nameInput.Value()) == "" {
+					m.message = "Network name is required"
+					m.isError = true
+				} else if m.addFormFocus == 1 && m.editing != nil {
+					oldIP, name, ip := m.editing.Value, m.nameInput.Value(), m.ipInput.Value()
+					m.message = "Computing preview..."
+					m.isError = false
+					m.isSubmitting = true
+					return m, previewChange("✏️  Edit Authorized Network", []CloudResource{m.selectedResource}, stateAddNetwork,
+						func(nm *NetworkManager, resource CloudResource) (*ChangePreview, error) {
+							return nm.PreviewEditNetwork(resource, oldIP, name, ip)
+						},
+						submitEditNetwork(m.selectedResource, oldIP, name, ip))
 				} else if m.addFormFocus == 1 {
-					// Show loading state
-					m.message = "Adding network... (0s) - GCP may take up to 60 seconds"
+					// Show exactly what will be sent before sending it
+					name, ip := m.nameInput.Value(), m.ipInput.Value()
+					m.message = "Computing preview..."
 					m.isError = false
 					m.isSubmitting = true
-					m.submitStartTime = time.Now()
-					return m, tea.Batch(
-						submitAddNetwork(m.selectedResource, m.nameInput.Value(), m.ipInput.Value()),
-						tickCmd(),
-					)
+					return m, previewChange("➕ Add Authorized Network", m.targetResources(), stateAddNetwork,
+						func(nm *NetworkManager, resource CloudResource) (*ChangePreview, error) {
+							return nm.PreviewAddNetwork(resource, name, ip)
+						},
+						submitAddNetwork(m.targetResources(), name, ip))
+				}
+			} else if (m.state == stateAddPSCProject || m.state == stateRemovePSCProject) && !m.isSubmitting {
+				if strings.TrimSpace(m.pscInput.Value()) == "" {
+					m.message = "Project ID is required"
+					m.isError = true
+					break
+				}
+				kind := "Add PSC Consumer Project"
+				if m.state == stateRemovePSCProject {
+					kind = "Remove PSC Consumer Project"
+				}
+				instance := m.selectedResource.(SQLInstance)
+				cmd := m.enqueueJob(kind, []CloudResource{instance},
+					submitPSCConsumerProject(instance, m.pscInput.Value(), m.state == stateRemovePSCProject))
+				m.state = stateNetworkView
+				m.message = fmt.Sprintf("Queued: %s - it runs in the background", kind)
+				m.isError = false
+				return m, cmd
+			} else if m.state == stateRequestAccess && !m.isSubmitting {
+				if m.addFormFocus < 2 {
+					m.setRequestFormFocus(m.addFormFocus + 1)
+				} else {
+					m.isSubmitting = true
+					return m, submitAccessRequest(m.selectedResource, m.nameInput.Value(), m.ipInput.Value(), m.reasonInput.Value())
+				}
+			} else if m.state == stateRequests && !m.isSubmitting && len(m.requests) > 0 {
+				request := m.requests[m.requestCursor]
+				if m.rejecting {
+					if strings.TrimSpace(m.commentInput.Value()) == "" {
+						m.message = "Enter a comment for the requester"
+						m.isError = true
+					} else {
+						m.isSubmitting = true
+						return m, rejectRequest(request.ID, m.commentInput.Value())
+					}
+				} else if resource := m.findResource(request.Resource); resource == nil {
+					m.message = "The requested resource was not found - refresh, or reject the request"
+					m.isError = true
+				} else if violations := EvaluateRequest(request, resource, m.policy); len(violations) > 0 {
+					m.message = "This request can't be approved as it stands - reject it (x) with a comment"
+					m.isError = true
+				} else {
+					m.message = ""
+					m.confirm = confirmApproveRequest(request, resource)
+					m.state = stateConfirm
 				}
+			} else if m.state == stateRemoveNetwork && !m.isSubmitting {
+				ip := m.ipInput.Value()
+				m.message = "Computing preview..."
+				m.isError = false
+				m.isSubmitting = true
+				return m, previewChange("➖ Remove Authorized Network", m.targetResources(), stateRemoveNetwork,
+					func(nm *NetworkManager, resource CloudResource) (*ChangePreview, error) {
+						return nm.PreviewRemoveNetwork(resource, ip)
+					},
+					submitRemoveNetwork(m.targetResources(), ip))
 			}
-		case "tab":
-			if m.state == stateAddNetwork {
+		case key.Matches(msg, m.keys.NextTab, m.keys.PrevTab):
+			if m.state == stateResourceSelection && m.resourceList.FilterState() != list.Filtering {
+				step := 1
+				if key.Matches(msg, m.keys.PrevTab) {
+					step = -1
+				}
+				return m, m.switchResourceTab(step)
+			} else if key.Matches(msg, m.keys.PrevTab) {
+				break
+			} else if m.state == stateNetworkView {
+				// SQL instances have a second tab for PSC consumer projects
+				if _, ok := m.selectedResource.(SQLInstance); ok {
+					m.networkTab = (m.networkTab + 1) % 2
+				}
+			} else if m.state == stateRequestAccess && !m.isSubmitting {
+				m.setRequestFormFocus((m.addFormFocus + 1) % 3)
+			} else if m.state == stateAddNetwork && m.addGroup == 0 {
 				if m.addFormFocus == -1 {
 					// First tab focuses name field
 					m.addFormFocus = 0
@@ -362,70 +1123,185 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	case resourcesLoadedMsg:
 		m.isLoading = false
 		m.resources = msg.resources
-		
-		items := make([]list.Item, len(msg.resources))
-		for i, resource := range msg.resources {
-			items[i] = resourceItem{resource: resource}
-		}
-		
-		m.resourceList.SetItems(items)
 		m.state = stateResourceSelection
+		cmds = append(cmds, m.refreshResourceList())
 		
 	case resourceSelectedMsg:
+		if m.selectedResource == nil || resourceKey(m.selectedResource) != resourceKey(msg.resource) {
+			m.attribution = nil
+			m.attributionErr = ""
+			m.table.reset()
+		}
 		m.selectedResource = msg.resource
+		m.table.clamp(len(m.tableRows()), m.networkTableHeight())
 		m.state = stateNetworkView
 		m.message = ""
+		return m, loadAttribution(msg.resource)
+
+	case attributionLoadedMsg:
+		// Ignore results for a resource that is no longer selected
+		if m.selectedResource != nil && resourceKey(m.selectedResource) == msg.key {
+			m.attribution = msg.attribution
+			m.attributionErr = ""
+			if msg.err != nil {
+				m.attributionErr = msg.err.Error()
+			}
+		}
 		
-	case networkAddedMsg:
-		// Clear submitting state
+	case previewLoadedMsg:
 		m.isSubmitting = false
-		
-		if msg.success {
-			// Show success message and go back to network view
-			m.message = msg.message
+		// Ignore previews for a form the user has left
+		if m.state != msg.returnState {
+			break
+		}
+		if msg.err != nil {
+			m.message = msg.err.Error()
+			m.isError = true
+		} else {
+			m.message = ""
+			m.confirm = msg.confirm
+			m.state = stateConfirm
+		}
+
+	case undoLoadedMsg:
+		if m.state != stateNetworkView {
+			break
+		}
+		if msg.snapshot == nil {
+			m.message = "No snapshots of this resource - nothing to undo"
+			m.isError = true
+		} else if msg.diff.Empty() && len(msg.settings) == 0 {
+			m.message = "The resource already matches the last snapshot - nothing to undo"
 			m.isError = false
-			m.state = stateNetworkView
-			// Refresh the selected resource to show updated networks
-			return m, selectResource(m.selectedResource)
 		} else {
-			// Show error message but stay in add form
-			m.message = msg.message
+			m.message = ""
+			m.confirm = confirmUndo(m.selectedResource, msg.snapshot, msg.diff, msg.settings)
+			m.state = stateConfirm
+		}
+
+	case historyLoadedMsg:
+		m.history = msg.entries
+		m.historyOffset = 0
+
+	case requestSubmittedMsg:
+		m.isSubmitting = false
+		m.message = msg.message
+		m.isError = !msg.success
+		if msg.success {
+			m.state = stateNetworkView
+		}
+
+	case requestsLoadedMsg:
+		m.requests = msg.requests
+		m.requestDir = msg.dir
+		if m.requestCursor >= len(m.requests) {
+			m.requestCursor = len(m.requests) - 1
+		}
+		if m.requestCursor < 0 {
+			m.requestCursor = 0
+		}
+
+	case requestDecidedMsg:
+		m.isSubmitting = false
+		m.message = msg.message
+		m.isError = !msg.success
+		m.rejecting = false
+		m.commentInput.Blur()
+		if m.confirm != nil {
+			m.state = m.confirm.returnState
+			m.confirm = nil
+		}
+		return m, loadRequests
+
+	case accessLoadedMsg:
+		m.accessLoading = false
+		m.accessIP = msg.ip
+		m.accessNames = msg.names
+		if msg.ip == "" {
+			m.access = nil
+			m.message = fmt.Sprintf("Couldn't detect your public IP - check your connection and press %s to retry", firstKey(m.keys.Refresh))
 			m.isError = true
+		} else {
+			m.access = CheckAccess(m.resources, msg.ip, msg.names)
+			m.accessCursor, m.accessOffset = clampWindow(m.accessCursor, m.accessOffset, len(m.access), m.accessTableHeight())
 		}
-		
+
+	case clipboardMsg:
+		m.message = msg.message
+		m.isError = !msg.success
+
 	case errorMsg:
 		m.message = msg.err.Error()
 		m.isError = true
 		m.isSubmitting = false // Clear submitting state on error
-		// Don't change state to error if we're in add network form
-		if m.state != stateAddNetwork {
+		// Don't change state to error if we're in a network form
+		if m.state != stateAddNetwork && m.state != stateRemoveNetwork && m.state != stateConfirm &&
+			m.state != stateAddPSCProject && m.state != stateRemovePSCProject && m.state != stateHistory &&
+			m.state != stateRequestAccess && m.state != stateRequests && m.state != stateMyAccess {
 			m.state = stateError
 		}
 		
-	case tickMsg:
-		if m.isSubmitting && m.state == stateAddNetwork {
-			// Update the message with elapsed time
-			elapsed := time.Since(m.submitStartTime).Seconds()
-			m.message = fmt.Sprintf("Adding network... (%.0fs) - GCP may take up to 60 seconds", elapsed)
-			// Continue ticking
-			return m, tickCmd()
+	case resourcesReadyMsg:
+		// Ignore the result if the wait was stopped
+		if m.waitingFor == "" {
+			break
 		}
-		
-	}
-	
-	// Update components
-	switch m.state {
+		m.waitingFor = ""
+		m.isSubmitting = false
+		if msg.err != nil {
+			m.message = msg.err.Error()
+			m.isError = true
+			break
+		}
+		for _, resource := range msg.resources {
+			cmds = append(cmds, m.replaceResource(resource))
+			if resourceKey(resource) == resourceKey(m.selectedResource) {
+				m.selectedResource = resource
+			}
+		}
+		m.message = fmt.Sprintf("%s is %s - press %s to review the change", m.selectedResource.GetName(), displayState(m.selectedResource), firstKey(m.keys.Select))
+		m.isError = false
+
+	case jobFinishedMsg:
+		return m.finishJob(msg)
+
+	case jobTickMsg:
+		return m.expireToasts(time.Time(msg))
+
+	case tickMsg:
+		if m.isSubmitting && m.waitingFor != "" {
+			elapsed := time.Since(m.submitStartTime).Seconds()
+			m.message = fmt.Sprintf("⏳ Waiting for %s... (%.0fs) - %s to stop", m.waitingFor, elapsed, firstKey(m.keys.Back))
+			return m, tickCmd()
+		}
+	}
+	
+	// Update components
+	switch m.state {
 	case stateLoading:
 		m.spinner, cmd = m.spinner.Update(msg)
 		cmds = append(cmds, cmd)
 		
 	case stateResourceSelection:
+		wasUnfiltered := m.resourceList.FilterState() == list.Unfiltered
 		m.resourceList, cmd = m.resourceList.Update(msg)
 		cmds = append(cmds, cmd)
+		// Group headers are hidden while searching and come back afterwards
+		if wasUnfiltered != (m.resourceList.FilterState() == list.Unfiltered) {
+			cmds = append(cmds, m.refreshResourceList())
+		}
 		
+	case stateNetworkView:
+		if key, ok := msg.(tea.KeyMsg); ok {
+			if _, onPSCTab := m.pscTabInstance(); !onPSCTab {
+				m, cmd = m.updateNetworkTable(key)
+				cmds = append(cmds, cmd)
+			}
+		}
+
 	case stateAddNetwork:
-		// Don't update inputs when submitting
-		if !m.isSubmitting {
+		// Don't update inputs when submitting or adding a group
+		if !m.isSubmitting && m.addGroup == 0 {
 			if m.addFormFocus == 0 {
 				m.nameInput, cmd = m.nameInput.Update(msg)
 				cmds = append(cmds, cmd)
@@ -435,6 +1311,70 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			}
 			// Don't update inputs when addFormFocus == -1 (no field focused)
 		}
+
+	case stateRemoveNetwork:
+		if !m.isSubmitting {
+			m.ipInput, cmd = m.ipInput.Update(msg)
+			cmds = append(cmds, cmd)
+		}
+
+	case stateAddPSCProject, stateRemovePSCProject:
+		if !m.isSubmitting {
+			m.pscInput, cmd = m.pscInput.Update(msg)
+			cmds = append(cmds, cmd)
+		}
+
+	case stateRequestAccess:
+		if !m.isSubmitting {
+			switch m.addFormFocus {
+			case 0:
+				m.nameInput, cmd = m.nameInput.Update(msg)
+			case 1:
+				m.ipInput, cmd = m.ipInput.Update(msg)
+			case 2:
+				m.reasonInput, cmd = m.reasonInput.Update(msg)
+			}
+			cmds = append(cmds, cmd)
+		}
+
+	case stateRequests:
+		if m.rejecting {
+			if !m.isSubmitting {
+				m.commentInput, cmd = m.commentInput.Update(msg)
+				cmds = append(cmds, cmd)
+			}
+		} else if msg, ok := msg.(tea.KeyMsg); ok {
+			switch {
+			case key.Matches(msg, m.keys.Up):
+				if m.requestCursor > 0 {
+					m.requestCursor--
+				}
+			case key.Matches(msg, m.keys.Down):
+				if m.requestCursor < len(m.requests)-1 {
+					m.requestCursor++
+				}
+			}
+		}
+
+	case stateMyAccess:
+		if key, ok := msg.(tea.KeyMsg); ok && !m.isSubmitting && !m.accessLoading {
+			m, cmd = m.updateMyAccess(key)
+			cmds = append(cmds, cmd)
+		}
+
+	case stateHistory:
+		if msg, ok := msg.(tea.KeyMsg); ok {
+			switch {
+			case key.Matches(msg, m.keys.Up):
+				if m.historyOffset > 0 {
+					m.historyOffset--
+				}
+			case key.Matches(msg, m.keys.Down):
+				if m.historyOffset < len(m.history)-1 {
+					m.historyOffset++
+				}
+			}
+		}
 	}
 	
 	return m, tea.Batch(cmds...)
@@ -442,6 +1382,14 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 // View renders the UI
 func (m Model) View() string {
+	if plainMode {
+		return plainText(m.view())
+	}
+	return m.view()
+}
+
+// view renders the current state
+func (m Model) view() string {
 	if m.showHelp {
 		return m.renderHelpView()
 	}
@@ -457,10 +1405,39 @@ func (m Model) View() string {
 		content = m.renderNetworkView()
 	case stateAddNetwork:
 		content = m.renderAddNetworkView()
+	case stateRemoveNetwork:
+		content = m.renderRemoveNetworkView()
+	case stateAddPSCProject, stateRemovePSCProject:
+		content = m.renderPSCProjectView()
+	case stateConfirm:
+		content = m.renderConfirmView()
+	case stateHistory:
+		content = m.renderHistoryView()
+	case stateRequestAccess:
+		content = m.renderRequestAccessView()
+	case stateRequests:
+		content = m.renderRequestsView()
+	case stateSearch:
+		content = m.renderSearchView()
+	case stateMyAccess:
+		content = m.renderMyAccessView()
 	case stateError:
 		content = m.renderErrorView()
 	}
 	
+	if dryRunMode {
+		content = lipgloss.JoinVertical(
+			lipgloss.Left,
+			WarningStyle.Render("🧪 DRY RUN - changes are previewed but never sent"),
+			content,
+		)
+	}
+
+	// Background jobs and their toasts stay at the bottom of every view
+	if jobs := m.renderJobs(); jobs != "" {
+		content = lipgloss.JoinVertical(lipgloss.Left, content, "", jobs)
+	}
+
 	return BaseStyle.Width(m.width).Height(m.height).Render(content)
 }
 
@@ -472,7 +1449,7 @@ func (m Model) renderLoadingView() string {
 			lipgloss.Center,
 			m.spinner.View(),
 			"Discovering all cloud resources across your projects...",
-			lipgloss.NewStyle().Foreground(lipgloss.Color(CatppuccinMocha.Overlay0)).Render("This may take a moment for many projects"),
+			lipgloss.NewStyle().Foreground(lipgloss.Color(Colors.Overlay0)).Render("This may take a moment for many projects"),
 		),
 	)
 }
@@ -481,9 +1458,13 @@ func (m Model) renderResourceSelectionView() string {
 	title := RenderTitle("🔐 PIAM Admin Network Configurator")
 	subtitle := RenderSubtitle(fmt.Sprintf("Found %d resources across your projects", len(m.resources)))
 	
+	k := m.keys
 	help := RenderHelp([]string{
-		"↑/↓ Navigate • Enter Select • / Search",
-		"r Refresh • q Quit • ? Help",
+		strings.Join(hints(hint("Navigate", k.Up, k.Down), hint("Select/Collapse", k.Select), hint("Search", k.Filter),
+			hint("Type", k.NextTab), hint("Group", k.GroupBy), hint("Sort", k.SortBy)), " • "),
+		strings.Join(hints(hint("Search by IP", k.SearchIP), hint("My access", k.MyAccess), hint("History", k.History),
+			hint("Requests", k.Requests), m.jobsHint()), " • "),
+		strings.Join(hints(hint("Refresh", k.Refresh), hint("Quit", k.Quit), hint("Help", k.Help)), " • "),
 	})
 	
 	content := lipgloss.JoinVertical(
@@ -491,6 +1472,8 @@ func (m Model) renderResourceSelectionView() string {
 		title,
 		subtitle,
 		"",
+		m.renderResourceTabs(),
+		lipgloss.JoinHorizontal(lipgloss.Top, SubtleTextStyle.Render(m.resourceListStatus()+"  "), m.renderQueryChips()),
 		m.resourceList.View(),
 	)
 	
@@ -516,33 +1499,64 @@ func (m Model) renderResourceSelectionView() string {
 	)
 }
 
+// renderNetworkBody renders the network view's table, or the PSC tab of SQL instances
+func (m Model) renderNetworkBody() string {
+	networks := resourceNetworks(m.selectedResource)
+	table := m.renderNetworkTable(networks)
+	if m.attributionErr != "" && len(networks) > 0 {
+		table = lipgloss.JoinVertical(
+			lipgloss.Left,
+			table,
+			SubtleTextStyle.Render("Added by/at unavailable: "+m.attributionErr),
+		)
+	}
+	if warnings := RenderPartialGroups(m.groups.Coverage(networks)); warnings != "" {
+		table = lipgloss.JoinVertical(lipgloss.Left, table, "", warnings)
+	}
+
+	// SQL instances have a second tab for Private Service Connect
+	if instance, isSQL := m.selectedResource.(SQLInstance); isSQL {
+		if _, onPSCTab := m.pscTabInstance(); onPSCTab {
+			table = RenderPSCTable(instance)
+		}
+		table = lipgloss.JoinVertical(
+			lipgloss.Left,
+			RenderTabs([]string{"Authorized Networks", "PSC Consumer Projects"}, m.networkTab),
+			table,
+		)
+	}
+	return table
+}
+
 func (m Model) renderNetworkView() string {
-	var networks []AuthorizedNetwork
+	networks := resourceNetworks(m.selectedResource)
 	resourceType := m.selectedResource.GetType()
 	
-	// Get networks based on resource type
-	switch r := m.selectedResource.(type) {
-	case SQLInstance:
-		networks = r.AuthorizedNetworks
-	case GKECluster:
-		networks = r.MasterAuthorizedNetworks
-	}
-	
 	title := RenderTitle(fmt.Sprintf("%s %s", getResourceIcon(m.selectedResource), m.selectedResource.GetDisplayName()))
 	
 	var subtitle string
-	if resourceType == ResourceTypeSQL {
+	consoleName := "Google Cloud Console"
+	switch resourceType {
+	case ResourceTypeSQL:
 		subtitle = "SQL Instance Authorized Networks"
-	} else {
+	case ResourceTypeAWS:
+		subtitle = "AWS Security Group Ingress Rules"
+		consoleName = "AWS Console"
+	case ResourceTypeAzure:
+		if r, ok := m.selectedResource.(AzureDatabaseServer); ok {
+			subtitle = fmt.Sprintf("Azure %s Firewall Rules", r.kindLabel())
+		}
+		consoleName = "Azure Portal"
+	default:
 		subtitle = "GKE Cluster Master Authorized Networks"
 	}
 	subtitle = RenderSubtitle(subtitle)
 	
 	// Show console link
 	consoleLink := lipgloss.NewStyle().
-		Foreground(lipgloss.Color(CatppuccinMocha.Blue)).
+		Foreground(lipgloss.Color(Colors.Blue)).
 		Underline(true).
-		Render("🌐 Open in Google Cloud Console (press 'c')")
+		Render(fmt.Sprintf("🌐 Open in %s (press '%s')", consoleName, firstKey(m.keys.Console)))
 	
 	// Show restrictions if any
 	restrictions := ""
@@ -550,23 +1564,81 @@ func (m Model) renderNetworkView() string {
 		restrictions = WarningStyle.Render("⚠️  " + r)
 	}
 	
-	// Create table
-	table := RenderNetworkTable(networks)
+	// GKE clusters show their access settings prominently, since a disabled
+	// feature means the table below is not enforced at all
+	if cluster, ok := m.selectedResource.(GKECluster); ok {
+		restrictions = RenderGKEAccessStatus(cluster)
+	}
+
+	// Resource details go beside the table when they fit; otherwise I shows them instead
+	table := m.renderNetworkBody()
+	beside, showDetails := m.detailPaneLayout(table)
+	if showDetails && beside {
+		table = lipgloss.JoinHorizontal(lipgloss.Top, table, "  ", m.renderDetailPane())
+	} else if showDetails {
+		table = m.renderDetailPane()
+	}
 	
+	instance, isSQL := m.selectedResource.(SQLInstance)
+	_, onPSCTab := m.pscTabInstance()
+
 	// Help text
-	helpItems := []string{
-		"↑/↓ Navigate",
-		"c Console",
-		"Esc Back",
-		"r Refresh",
-		"q Quit",
-	}
+	k := m.keys
+	helpItems := hints(
+		hint("Details", k.Details),
+		hint("Console", k.Console),
+		hint("Undo", k.Undo),
+		hint("History", k.History),
+		hint("Request access", k.RequestAccess),
+		hint("Requests", k.Requests),
+		hint("Search by IP", k.SearchIP),
+		hint("My access", k.MyAccess),
+		m.jobsHint(),
+		hint("Back", k.Back),
+		hint("Refresh", k.Refresh),
+		hint("Quit", k.Quit),
+	)
 	
-	if m.selectedResource.CanAddNetwork() {
-		helpItems = append([]string{"a Add network"}, helpItems...)
+	if onPSCTab {
+		helpItems = append(hints(hint("Networks", k.NextTab)), helpItems...)
+		if len(instance.AllowedConsumerProjects) > 0 {
+			helpItems = append(hints(hint("Remove project", k.Remove)), helpItems...)
+		}
+		if instance.PSCEnabled {
+			helpItems = append(hints(hint("Add project", k.Add)), helpItems...)
+		}
+		networks = nil
+	} else if isSQL {
+		helpItems = append(hints(hint("PSC", k.NextTab)), helpItems...)
+	}
+	if len(networks) > 0 {
+		helpItems = append(hints(hint("Select", k.Up, k.Down), hint("Actions", k.Select), hint("Filter", k.Filter),
+			hint("Sort", k.SortTable), hint("Remove network", k.Remove)), helpItems...)
+	}
+	if cluster, ok := m.selectedResource.(GKECluster); ok {
+		if cluster.MasterAuthorizedNetworksEnabled {
+			helpItems = append(hints(hint("Disable authorized networks", k.ToggleNetworks), hint("Toggle Google Cloud IPs", k.ToggleGoogleIPs)), helpItems...)
+		} else {
+			helpItems = append(hints(hint("Enable authorized networks", k.ToggleNetworks)), helpItems...)
+		}
+	}
+	if m.selectedResource.CanAddNetwork() && !onPSCTab {
+		helpItems = append(hints(hint("Add network", k.Add)), helpItems...)
 	}
 	
 	help := RenderHelp(helpItems)
+	switch {
+	case showDetails && !beside:
+		help = RenderHelp(hints(hint("Close details", k.Details, k.Back)))
+	case onPSCTab:
+	case m.table.detail:
+		help = RenderHelp(hints(hint("Close details", k.Back)))
+	case m.table.menu:
+		help = RenderHelp(hints(hint("Select", k.Up, k.Down), hint("Run", k.Select),
+			hint("Shortcuts", k.Copy, k.Info, k.Edit, k.Remove), hint("Close", k.Back)))
+	case m.table.filtering:
+		help = RenderHelp(hints("Type to filter", hint("Select", k.Up, k.Down), hint("Keep filter", k.Select), hint("Clear", k.Back)))
+	}
 	
 	content := lipgloss.JoinVertical(
 		lipgloss.Left,
@@ -617,6 +1689,10 @@ func (m Model) renderNetworkView() string {
 func (m Model) renderAddNetworkView() string {
 	title := RenderTitle("➕ Add Authorized Network")
 	subtitle := RenderSubtitle(fmt.Sprintf("Adding to: %s", m.selectedResource.GetDisplayName()))
+	if m.editing != nil {
+		title = RenderTitle("✏️  Edit Authorized Network")
+		subtitle = RenderSubtitle(fmt.Sprintf("Editing %s (%s) on: %s", networkName(*m.editing), m.editing.Value, m.selectedResource.GetDisplayName()))
+	}
 	
 	// Name input
 	nameLabel := "Network Name:"
@@ -646,7 +1722,38 @@ func (m Model) renderAddNetworkView() string {
 		"",
 		SubtleTextStyle.Render("Example: 192.168.1.100/32 or 10.0.0.0/24"),
 	)
+	if group, ok := m.selectedGroup(); ok {
+		form = m.renderGroupPicker(group)
+	}
+	if groups := m.groups.All(); len(groups) > 0 && m.editing == nil {
+		picker := "None - type a CIDR"
+		if m.addGroup > 0 {
+			picker = fmt.Sprintf("%s (%d of %d)", groups[m.addGroup-1].Name, m.addGroup, len(groups))
+		}
+		form = lipgloss.JoinVertical(
+			lipgloss.Left,
+			LabelStyle.Render(fmt.Sprintf("Network Group (%s):", firstKey(m.keys.NetworkGroup))),
+			InputStyle.Render(picker),
+			"",
+			form,
+		)
+	}
+	if toggle := m.renderReplicaToggle(); toggle != "" && m.editing == nil {
+		form = lipgloss.JoinVertical(lipgloss.Left, form, "", toggle)
+	}
 	
+	// Show policy violations inline as the user types
+	if violations := m.addFormViolations(); len(violations) > 0 {
+		lines := []string{"", ErrorStyle.Render("Policy violations:")}
+		for _, v := range violations {
+			lines = append(lines, ErrorMessageStyle.Render("  ✗ "+v.String()))
+		}
+		form = lipgloss.JoinVertical(lipgloss.Left, append([]string{form}, lines...)...)
+	}
+	if warning := m.renderStateWarning(); warning != "" {
+		form = lipgloss.JoinVertical(lipgloss.Left, form, "", warning)
+	}
+
 	formBox := FormBoxStyle.Render(form)
 	
 	var helpItems []string
@@ -654,16 +1761,15 @@ func (m Model) renderAddNetworkView() string {
 		helpItems = []string{
 			"Please wait...",
 		}
+	} else if m.addGroup > 0 {
+		helpItems = hints(hint("Add group", m.keys.Select), hint("Next group", m.keys.NetworkGroup),
+			hint("Cancel", m.keys.Back), hint("Quit", m.keys.Quit))
 	} else if m.addFormFocus == -1 {
-		helpItems = []string{
-			"Tab/Enter Focus first field",
-			"Esc Cancel • q Quit",
-		}
+		helpItems = hints(hint("Focus first field", m.keys.NextTab, m.keys.Select),
+			hint("Cancel", m.keys.Back), hint("Quit", m.keys.Quit))
 	} else {
-		helpItems = []string{
-			"Tab Navigate fields • Enter Submit",
-			"Esc Cancel • q Quit",
-		}
+		helpItems = hints(hint("Navigate fields", m.keys.NextTab), hint("Submit", m.keys.Select),
+			hint("Cancel", m.keys.Back))
 	}
 	help := RenderHelp(helpItems)
 	
@@ -697,103 +1803,952 @@ func (m Model) renderAddNetworkView() string {
 	)
 }
 
-func (m Model) renderErrorView() string {
-	return lipgloss.Place(
-		m.width, m.height,
-		lipgloss.Center, lipgloss.Center,
-		ErrorBoxStyle.Render(
-			lipgloss.JoinVertical(
-				lipgloss.Center,
-				ErrorTitleStyle.Render("⚠️  Error"),
-				"",
-				m.message,
-				"",
-				SubtleTextStyle.Render("Press q to quit or Esc to go back"),
-			),
-		),
-	)
-}
+// renderGroupPicker lists a group's members and which the selected resource already has
+func (m Model) renderGroupPicker(group NetworkGroup) string {
+	missing := make(map[string]bool)
+	for _, network := range group.Missing(resourceNetworks(m.selectedResource)) {
+		missing[network.Value] = true
+	}
 
-func (m Model) renderHelpView() string {
-	helpText := `
-🔐 PIAM Admin Network Configurator
+	lines := []string{LabelStyle.Render(group.Name)}
+	if group.Description != "" {
+		lines = append(lines, SubtleTextStyle.Render(group.Description))
+	}
+	lines = append(lines, "")
+	for _, member := range group.Networks() {
+		if missing[member.Value] {
+			lines = append(lines, SuccessStyle.Render(fmt.Sprintf("+ %-20s %s", member.Value, member.Name)))
+		} else {
+			lines = append(lines, SubtleTextStyle.Render(fmt.Sprintf("✓ %-20s already present", member.Value)))
+		}
+	}
+	if len(missing) == 0 {
+		lines = append(lines, "", SubtleTextStyle.Render("Every member is already present"))
+	}
+	return lipgloss.JoinVertical(lipgloss.Left, lines...)
+}
 
-A beautiful TUI for managing Cloud SQL and GKE authorized networks.
+func (m Model) renderRemoveNetworkView() string {
+	title := RenderTitle("➖ Remove Authorized Network")
+	subtitle := RenderSubtitle(fmt.Sprintf("Removing from: %s", m.selectedResource.GetDisplayName()))
 
-NAVIGATION
-  ↑/↓ or j/k     Navigate through lists
-  Enter          Select resource or submit form
-  Esc            Go back / Cancel
-  Tab            Switch between form fields
-  /              Search resources
-  q or Ctrl+C    Quit
+	form := lipgloss.JoinVertical(
+		lipgloss.Left,
+		LabelStyle.Render("IP Address/CIDR:"),
+		ActiveInputStyle.Render(m.ipInput.View()),
+		"",
+		SubtleTextStyle.Render("Enter the IP/CIDR of the network to remove"),
+	)
+	if toggle := m.renderReplicaToggle(); toggle != "" {
+		form = lipgloss.JoinVertical(lipgloss.Left, form, "", toggle)
+	}
+	if warning := m.renderStateWarning(); warning != "" {
+		form = lipgloss.JoinVertical(lipgloss.Left, form, "", warning)
+	}
 
-ACTIONS
-  a              Add authorized network (when available)
-  r              Refresh resource list
-  ?              Toggle this help
+	helpItems := hints(hint("Remove", m.keys.Select), hint("Cancel", m.keys.Back))
+	if m.isSubmitting {
+		helpItems = []string{"Please wait..."}
+	}
 
-RESOURCE ICONS
-  🗄️             SQL Database Instance
-  ☸️             GKE Kubernetes Cluster
-  🔒             Resource cannot accept external networks
+	content := lipgloss.JoinVertical(
+		lipgloss.Left,
+		title,
+		subtitle,
+		"",
+		FormBoxStyle.Render(form),
+		"",
+		RenderNetworkTable(resourceNetworks(m.selectedResource), m.attribution, m.groups),
+	)
 
-NETWORK RESTRICTIONS
-  • Private SQL instances cannot have authorized networks
-  • GKE clusters always support master authorized networks
-  • Some resources may require VPN or jumphost access
+	if m.message != "" {
+		messageStyle := MessageStyle
+		if m.isError {
+			messageStyle = ErrorMessageStyle
+		}
+		content = lipgloss.JoinVertical(
+			lipgloss.Left,
+			content,
+			"",
+			messageStyle.Render(m.message),
+		)
+	}
 
-Press any key to return...`
-	
-	return lipgloss.Place(
-		m.width, m.height,
-		lipgloss.Center, lipgloss.Center,
-		HelpBoxStyle.Width(80).Render(helpText),
+	return lipgloss.JoinVertical(
+		lipgloss.Left,
+		content,
+		RenderHelp(helpItems),
 	)
 }
 
-// Commands
-func loadResources() tea.Msg {
-	ctx := context.Background()
-	nm, err := NewNetworkManager(ctx)
-	if err != nil {
-		return errorMsg{err}
+func (m Model) renderPSCProjectView() string {
+	removing := m.state == stateRemovePSCProject
+
+	title := RenderTitle("➕ Allow PSC Consumer Project")
+	subtitle := RenderSubtitle(fmt.Sprintf("Adding to: %s", m.selectedResource.GetDisplayName()))
+	fieldHint := "Project ID or number allowed to create PSC endpoints to this instance"
+	helpItems := hints(hint("Add", m.keys.Select), hint("Cancel", m.keys.Back))
+	if removing {
+		title = RenderTitle("➖ Remove PSC Consumer Project")
+		subtitle = RenderSubtitle(fmt.Sprintf("Removing from: %s", m.selectedResource.GetDisplayName()))
+		fieldHint = "Existing PSC endpoints in this project will stop working"
+		helpItems = hints(hint("Remove", m.keys.Select), hint("Cancel", m.keys.Back))
 	}
-	
-	resources, err := nm.ListAllResources()
-	if err != nil {
-		return errorMsg{err}
+	if m.isSubmitting {
+		helpItems = []string{"Please wait..."}
 	}
-	
-	return resourcesLoadedMsg{resources}
-}
 
-func selectResource(resource CloudResource) tea.Cmd {
-	return func() tea.Msg {
-		ctx := context.Background()
-		nm, err := NewNetworkManager(ctx)
-		if err != nil {
-			return errorMsg{err}
-		}
-		
-		// Get fresh details
-		updated, err := nm.GetResourceDetails(resource)
-		if err != nil {
-			return errorMsg{err}
+	form := lipgloss.JoinVertical(
+		lipgloss.Left,
+		LabelStyle.Render("Consumer Project:"),
+		ActiveInputStyle.Render(m.pscInput.View()),
+		"",
+		SubtleTextStyle.Render(fieldHint),
+	)
+
+	content := lipgloss.JoinVertical(
+		lipgloss.Left,
+		title,
+		subtitle,
+		"",
+		FormBoxStyle.Render(form),
+		"",
+		RenderPSCTable(m.selectedResource.(SQLInstance)),
+	)
+
+	if m.message != "" {
+		messageStyle := MessageStyle
+		if m.isError {
+			messageStyle = ErrorMessageStyle
 		}
-		
-		return resourceSelectedMsg{resource: updated}
+		content = lipgloss.JoinVertical(
+			lipgloss.Left,
+			content,
+			"",
+			messageStyle.Render(m.message),
+		)
 	}
+
+	return lipgloss.JoinVertical(
+		lipgloss.Left,
+		content,
+		RenderHelp(helpItems),
+	)
 }
 
-func submitAddNetwork(resource CloudResource, name, ip string) tea.Cmd {
-	return func() tea.Msg {
-		// Validate inputs
-		if strings.TrimSpace(name) == "" {
-			return networkAddedMsg{
-				success: false,
-				message: "Network name is required",
-			}
+func (m Model) renderConfirmView() string {
+	helpItems := hints(hint("Confirm", m.keys.Confirm), hint("Cancel", m.keys.Cancel, m.keys.Back))
+	if dryRunMode {
+		helpItems = hints("Dry run - nothing will be sent", hint("Back", m.keys.Back))
+	}
+
+	subject := m.confirm.subject
+	if subject == "" && m.selectedResource != nil {
+		subject = m.selectedResource.GetDisplayName()
+	}
+
+	content := lipgloss.JoinVertical(
+		lipgloss.Left,
+		RenderTitle(m.confirm.title),
+		RenderSubtitle(subject),
+		"",
+		FormBoxStyle.Render(m.confirm.body),
+	)
+
+	if m.message != "" {
+		messageStyle := MessageStyle
+		if m.isError {
+			messageStyle = ErrorMessageStyle
+		}
+		content = lipgloss.JoinVertical(
+			lipgloss.Left,
+			content,
+			"",
+			messageStyle.Render(m.message),
+		)
+	}
+
+	return lipgloss.JoinVertical(
+		lipgloss.Left,
+		content,
+		RenderHelp(helpItems),
+	)
+}
+
+func (m Model) renderHistoryView() string {
+	subtitle := "All changes made through piam-anc, newest first"
+	if m.historyReturnState == stateNetworkView && m.selectedResource != nil {
+		subtitle = fmt.Sprintf("Changes to %s, newest first", m.selectedResource.GetDisplayName())
+	}
+
+	// Leave room for the title, subtitle, table header and help
+	rows := m.bodyHeight() - 12
+	if rows < 5 {
+		rows = 5
+	}
+
+	content := lipgloss.JoinVertical(
+		lipgloss.Left,
+		RenderTitle("📜 Change History"),
+		RenderSubtitle(subtitle),
+		"",
+		RenderHistoryTable(m.history, m.historyOffset, rows),
+	)
+
+	if m.message != "" {
+		messageStyle := MessageStyle
+		if m.isError {
+			messageStyle = ErrorMessageStyle
+		}
+		content = lipgloss.JoinVertical(
+			lipgloss.Left,
+			content,
+			"",
+			messageStyle.Render(m.message),
+		)
+	}
+
+	return lipgloss.JoinVertical(
+		lipgloss.Left,
+		content,
+		RenderHelp(hints(hint("Scroll", m.keys.Up, m.keys.Down), hint("Back", m.keys.Back), hint("Quit", m.keys.Quit))),
+	)
+}
+
+// updateSearch handles a key in the search view, whose input always has focus
+func (m Model) updateSearch(msg tea.KeyMsg) (Model, tea.Cmd) {
+	height := m.searchResultsHeight()
+	switch {
+	case commandKey(msg, true, m.keys.Back):
+		m.state = m.searchReturnState
+		m.message = ""
+		return m, nil
+	case commandKey(msg, true, m.keys.Up):
+		m.searchCursor--
+	case commandKey(msg, true, m.keys.Down):
+		m.searchCursor++
+	case commandKey(msg, true, m.keys.PageUp):
+		m.searchCursor -= height
+	case commandKey(msg, true, m.keys.PageDown):
+		m.searchCursor += height
+	case commandKey(msg, true, m.keys.Select):
+		query, err := ParseSearchQuery(m.searchInput.Value())
+		if err != nil {
+			m.message = err.Error()
+			m.isError = true
+			return m, nil
+		}
+		m.message = ""
+		// A new query searches; pressing Enter again opens the highlighted resource
+		if m.searchQuery == nil || query.String() != m.searchQuery.String() {
+			m.searchQuery = query
+			m.searchMatches = SearchNetworks(m.resources, query)
+			m.searchCursor, m.searchOffset = 0, 0
+			return m, nil
+		}
+		if len(m.searchMatches) == 0 {
+			return m, nil
+		}
+		resource := m.findResource(m.searchMatches[m.searchCursor].Resource)
+		if resource == nil {
+			m.message = "The resource was not found - refresh the resource list"
+			m.isError = true
+			return m, nil
+		}
+		return m, selectResource(resource)
+	default:
+		var cmd tea.Cmd
+		m.searchInput, cmd = m.searchInput.Update(msg)
+		return m, cmd
+	}
+	m.searchCursor, m.searchOffset = clampWindow(m.searchCursor, m.searchOffset, len(m.searchMatches), height)
+	return m, nil
+}
+
+// searchResultsHeight is how many result rows fit below the search input
+func (m Model) searchResultsHeight() int {
+	if m.height == 0 {
+		return 20
+	}
+	rows := m.bodyHeight() - 16
+	if rows < 5 {
+		rows = 5
+	}
+	return rows
+}
+
+func (m Model) renderSearchView() string {
+	title := RenderTitle("🔎 Who Can Reach What")
+	subtitle := RenderSubtitle(fmt.Sprintf("Find the entries of all %d discovered resources that match an IP or CIDR", len(m.resources)))
+
+	var results string
+	switch {
+	case m.searchQuery == nil:
+		results = SubtleTextStyle.Render("Exact matches, ranges containing the address and ranges inside the searched CIDR are listed")
+	case len(m.searchMatches) == 0:
+		results = EmptyStateStyle.Render(fmt.Sprintf("No entry matches %s", m.searchQuery))
+	default:
+		height := m.searchResultsHeight()
+		end := m.searchOffset + height
+		if end > len(m.searchMatches) {
+			end = len(m.searchMatches)
+		}
+		results = lipgloss.JoinVertical(
+			lipgloss.Left,
+			RenderSearchResults(m.searchMatches, m.searchCursor, m.searchOffset, height),
+			SubtleTextStyle.Render(fmt.Sprintf("Rows %d-%d of %d matches for %s", m.searchOffset+1, end, len(m.searchMatches), m.searchQuery)),
+		)
+	}
+
+	content := lipgloss.JoinVertical(
+		lipgloss.Left,
+		title,
+		subtitle,
+		"",
+		LabelStyle.Render("IP Address/CIDR:"),
+		ActiveInputStyle.Render(m.searchInput.View()),
+		"",
+		results,
+	)
+
+	if m.message != "" {
+		messageStyle := MessageStyle
+		if m.isError {
+			messageStyle = ErrorMessageStyle
+		}
+		content = lipgloss.JoinVertical(
+			lipgloss.Left,
+			content,
+			"",
+			messageStyle.Render(m.message),
+		)
+	}
+
+	help := hints(hint("Search", m.keys.Select), hint("Back", m.keys.Back))
+	if len(m.searchMatches) > 0 {
+		help = hints(hint("Search / open resource", m.keys.Select), hint("Select", m.keys.Up, m.keys.Down), hint("Back", m.keys.Back))
+	}
+	return lipgloss.JoinVertical(
+		lipgloss.Left,
+		content,
+		RenderHelp(help),
+	)
+}
+
+// updateMyAccess handles a key in the "where do I have access" view
+func (m Model) updateMyAccess(msg tea.KeyMsg) (Model, tea.Cmd) {
+	height := m.accessTableHeight()
+	switch {
+	case key.Matches(msg, m.keys.Up):
+		m.accessCursor--
+	case key.Matches(msg, m.keys.Down):
+		m.accessCursor++
+	case key.Matches(msg, m.keys.PageUp):
+		m.accessCursor -= height
+	case key.Matches(msg, m.keys.PageDown):
+		m.accessCursor += height
+	case key.Matches(msg, m.keys.Refresh):
+		m.accessLoading = true
+		m.message = ""
+		return m, loadMyAccess
+	case key.Matches(msg, m.keys.Select):
+		if len(m.access) > 0 {
+			return m, selectResource(m.access[m.accessCursor].Resource)
+		}
+	case key.Matches(msg, m.keys.FixAccess):
+		return m.fixAccess()
+	}
+	m.accessCursor, m.accessOffset = clampWindow(m.accessCursor, m.accessOffset, len(m.access), height)
+	return m, nil
+}
+
+// fixAccess previews the change that lets the user's IP into the highlighted resource:
+// moving their first stale entry to the current IP, or adding a new entry for it
+func (m Model) fixAccess() (Model, tea.Cmd) {
+	if len(m.access) == 0 {
+		return m, nil
+	}
+	status := m.access[m.accessCursor]
+	resource := status.Resource
+	switch {
+	case status.Status == AccessPrivate:
+		m.message = "This resource has no public endpoint - connect through its private network"
+		m.isError = true
+		return m, nil
+	case status.Status == AccessReachable:
+		m.message = "Your IP can already reach this resource"
+		m.isError = false
+		return m, nil
+	case !resource.CanAddNetwork():
+		m.message = "Cannot add networks to this resource: " + resource.GetNetworkRestrictions()
+		m.isError = true
+		return m, nil
+	}
+
+	// The confirmation and the refresh afterwards work on the selected resource
+	m.selectedResource = resource
+	m.message = "Computing preview..."
+	m.isError = false
+	m.isSubmitting = true
+	ip := m.accessIP
+
+	if len(status.Stale) > 0 {
+		old := status.Stale[0]
+		name := networkName(old)
+		return m, previewChange("✏️  Update Your Entry", []CloudResource{resource}, stateMyAccess,
+			func(nm *NetworkManager, resource CloudResource) (*ChangePreview, error) {
+				return nm.PreviewEditNetwork(resource, old.Value, name, ip)
+			},
+			submitEditNetwork(resource, old.Value, name, ip))
+	}
+
+	name := getUserName()
+	if len(m.accessNames) > 0 {
+		name = m.accessNames[0]
+	}
+	return m, previewChange("➕ Add Your IP", []CloudResource{resource}, stateMyAccess,
+		func(nm *NetworkManager, resource CloudResource) (*ChangePreview, error) {
+			return nm.PreviewAddNetwork(resource, name, ip)
+		},
+		submitAddNetwork([]CloudResource{resource}, name, ip))
+}
+
+// replaceResource swaps fresh details of a resource into the discovered list
+func (m *Model) replaceResource(resource CloudResource) tea.Cmd {
+	for i, r := range m.resources {
+		if resourceKey(r) == resourceKey(resource) {
+			m.resources[i] = resource
+		}
+	}
+	return m.refreshResourceList()
+}
+
+// accessTableHeight is how many rows of the access table fit on screen
+func (m Model) accessTableHeight() int {
+	if m.height == 0 {
+		return 20
+	}
+	rows := m.bodyHeight() - 20
+	if rows < 5 {
+		rows = 5
+	}
+	return rows
+}
+
+func (m Model) renderMyAccessView() string {
+	title := RenderTitle("📍 Where Do I Have Access")
+
+	if m.accessLoading {
+		return lipgloss.JoinVertical(
+			lipgloss.Left,
+			title,
+			RenderSubtitle("Detecting your public IP..."),
+		)
+	}
+
+	subtitle := "Your public IP could not be detected"
+	if m.accessIP != "" {
+		subtitle = fmt.Sprintf("From your public IP %s", m.accessIP)
+		if len(m.accessNames) > 0 {
+			subtitle += fmt.Sprintf(", with entries named after %s", strings.Join(m.accessNames, " or "))
+		}
+	}
+
+	lines := []string{title, RenderSubtitle(subtitle), ""}
+	if len(m.access) > 0 {
+		counts := make(map[string]int)
+		stale := 0
+		for _, status := range m.access {
+			counts[status.Status]++
+			stale += len(status.Stale)
+		}
+		summary := []string{
+			SuccessStyle.Render(fmt.Sprintf("✓ %d reachable", counts[AccessReachable])),
+			ErrorStyle.Render(fmt.Sprintf("✗ %d not reachable", counts[AccessBlocked])),
+			SubtleTextStyle.Render(fmt.Sprintf("%d private only", counts[AccessPrivate])),
+		}
+		if stale > 0 {
+			summary = append(summary, WarningStyle.Render(fmt.Sprintf("⚠️  %d of your entries point at old IPs", stale)))
+		}
+		lines = append(lines,
+			strings.Join(summary, "   "),
+			"",
+			RenderAccessTable(m.access, m.accessCursor, m.accessOffset, m.accessTableHeight()),
+			m.renderAccessDetail(m.access[m.accessCursor]),
+		)
+	}
+
+	if m.message != "" {
+		messageStyle := MessageStyle
+		if m.isError {
+			messageStyle = ErrorMessageStyle
+		}
+		lines = append(lines, "", messageStyle.Render(m.message))
+	}
+
+	help := hints(hint("Select", m.keys.Up, m.keys.Down), hint("Open", m.keys.Select), hint("Fix my access", m.keys.FixAccess),
+		hint("Re-detect IP", m.keys.Refresh), hint("Back", m.keys.Back))
+	if m.isSubmitting {
+		help = []string{"Please wait..."}
+	}
+	lines = append(lines, RenderHelp(help))
+	return lipgloss.JoinVertical(lipgloss.Left, lines...)
+}
+
+// renderAccessDetail explains the highlighted resource's status and what f would do
+func (m Model) renderAccessDetail(status AccessStatus) string {
+	var lines []string
+	for _, network := range status.Stale {
+		lines = append(lines, WarningStyle.Render(fmt.Sprintf("⚠️  %s points at %s, not your current IP", networkName(network), network.Value)))
+	}
+	switch {
+	case status.Status == AccessReachable && status.Via == nil:
+		lines = append(lines, WarningStyle.Render("Reachable because master authorized networks are disabled - it is open to every IP"))
+	case status.Status != AccessBlocked:
+	case !status.Resource.CanAddNetwork():
+		lines = append(lines, SubtleTextStyle.Render(status.Resource.GetNetworkRestrictions()))
+	case len(status.Stale) > 0:
+		lines = append(lines, SubtleTextStyle.Render(fmt.Sprintf("f moves %s to %s", networkName(status.Stale[0]), m.accessIP)))
+	default:
+		lines = append(lines, SubtleTextStyle.Render(fmt.Sprintf("f adds %s", m.accessIP)))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// loadMyAccess detects the user's public IP and the names their entries are likely to carry
+func loadMyAccess() tea.Msg {
+	return accessLoadedMsg{ip: getPublicIP(), names: accessNames()}
+}
+
+func (m Model) renderRequestAccessView() string {
+	title := RenderTitle("🙋 Request Access")
+	subtitle := RenderSubtitle(fmt.Sprintf("Ask an approver to add a network to: %s", m.selectedResource.GetDisplayName()))
+
+	fields := []struct {
+		label string
+		input textinput.Model
+	}{
+		{"Network Name:", m.nameInput},
+		{"IP Address/CIDR:", m.ipInput},
+		{"Reason:", m.reasonInput},
+	}
+	var lines []string
+	for i, field := range fields {
+		style := InputStyle
+		if m.addFormFocus == i {
+			style = ActiveInputStyle
+		}
+		if i > 0 {
+			lines = append(lines, "")
+		}
+		lines = append(lines, LabelStyle.Render(field.label), style.Render(field.input.View()))
+	}
+	lines = append(lines, "", SubtleTextStyle.Render("The request is queued for an approver, who sees it with 'A'"))
+
+	// Show the policy evaluation so the requester can fix problems before submitting
+	if violations := m.addFormViolations(); len(violations) > 0 {
+		lines = append(lines, "", ErrorStyle.Render("Policy violations:"))
+		for _, v := range violations {
+			lines = append(lines, ErrorMessageStyle.Render("  ✗ "+v.String()))
+		}
+	}
+
+	content := lipgloss.JoinVertical(
+		lipgloss.Left,
+		title,
+		subtitle,
+		"",
+		FormBoxStyle.Render(lipgloss.JoinVertical(lipgloss.Left, lines...)),
+	)
+
+	if m.message != "" {
+		messageStyle := MessageStyle
+		if m.isError {
+			messageStyle = ErrorMessageStyle
+		}
+		content = lipgloss.JoinVertical(
+			lipgloss.Left,
+			content,
+			"",
+			messageStyle.Render(m.message),
+		)
+	}
+
+	helpItems := hints(hint("Navigate fields", m.keys.NextTab), hint("Submit", m.keys.Select), hint("Cancel", m.keys.Back))
+	if m.isSubmitting {
+		helpItems = []string{"Please wait..."}
+	}
+	return lipgloss.JoinVertical(
+		lipgloss.Left,
+		content,
+		RenderHelp(helpItems),
+	)
+}
+
+func (m Model) renderRequestsView() string {
+	title := RenderTitle("📥 Access Requests")
+	subtitle := RenderSubtitle(fmt.Sprintf("Pending requests in %s, oldest first", m.requestDir))
+
+	var body string
+	if m.requests == nil {
+		body = SubtleTextStyle.Render("Loading requests...")
+	} else if len(m.requests) == 0 {
+		body = EmptyStateStyle.Render("No pending access requests")
+	} else {
+		rows := make([]string, len(m.requests))
+		for i, request := range m.requests {
+			row := fmt.Sprintf("%s  %-28s %-36s %s",
+				request.CreatedAt.Local().Format("2006-01-02 15:04"),
+				truncate(request.Requester, 28),
+				truncate(request.Resource.String(), 36),
+				request.CIDR)
+			if i == m.requestCursor {
+				rows[i] = SelectedListItemStyle.Render("▶ " + row)
+			} else {
+				rows[i] = ListItemStyle.Render("  " + row)
+			}
+		}
+		body = lipgloss.JoinVertical(
+			lipgloss.Left,
+			strings.Join(rows, "\n"),
+			"",
+			m.renderRequestDetail(m.requests[m.requestCursor]),
+		)
+	}
+
+	content := lipgloss.JoinVertical(lipgloss.Left, title, subtitle, "", body)
+
+	if m.rejecting {
+		content = lipgloss.JoinVertical(
+			lipgloss.Left,
+			content,
+			"",
+			LabelStyle.Render("Comment for the requester:"),
+			ActiveInputStyle.Render(m.commentInput.View()),
+		)
+	}
+
+	if m.message != "" {
+		messageStyle := MessageStyle
+		if m.isError {
+			messageStyle = ErrorMessageStyle
+		}
+		content = lipgloss.JoinVertical(
+			lipgloss.Left,
+			content,
+			"",
+			messageStyle.Render(m.message),
+		)
+	}
+
+	helpItems := hints(hint("Select", m.keys.Up, m.keys.Down), hint("Approve", m.keys.Select), hint("Reject", m.keys.Reject),
+		hint("Back", m.keys.Back), hint("Quit", m.keys.Quit))
+	if m.isSubmitting {
+		helpItems = []string{"Please wait..."}
+	} else if m.rejecting {
+		helpItems = hints(hint("Reject", m.keys.Select), hint("Cancel", m.keys.Back))
+	}
+	return lipgloss.JoinVertical(
+		lipgloss.Left,
+		content,
+		RenderHelp(helpItems),
+	)
+}
+
+// renderRequestDetail shows a request and how it fares against policy and the live resource
+func (m Model) renderRequestDetail(request AccessRequest) string {
+	lines := []string{
+		LabelStyle.Render(fmt.Sprintf("%s wants %s (%s) on %s", request.Requester, request.CIDR, request.Name, request.Resource)),
+		fmt.Sprintf("Reason: %s", request.Reason),
+		SubtleTextStyle.Render(fmt.Sprintf("Request %s, OS user %s", request.ID, request.OSUser)),
+		"",
+	}
+
+	resource := m.findResource(request.Resource)
+	if resource == nil {
+		lines = append(lines, WarningStyle.Render("⚠️  Resource not found among discovered resources"))
+	} else if violations := EvaluateRequest(request, resource, m.policy); len(violations) > 0 {
+		lines = append(lines, ErrorStyle.Render("Policy evaluation:"))
+		for _, v := range violations {
+			lines = append(lines, ErrorMessageStyle.Render("  ✗ "+v.String()))
+		}
+	} else {
+		lines = append(lines, SuccessStyle.Render("✓ Passes policy"))
+	}
+	return FormBoxStyle.Render(lipgloss.JoinVertical(lipgloss.Left, lines...))
+}
+
+func (m Model) renderErrorView() string {
+	return lipgloss.Place(
+		m.width, m.height,
+		lipgloss.Center, lipgloss.Center,
+		ErrorBoxStyle.Render(
+			lipgloss.JoinVertical(
+				lipgloss.Center,
+				ErrorTitleStyle.Render("⚠️  Error"),
+				"",
+				m.message,
+				"",
+				SubtleTextStyle.Render(fmt.Sprintf("Press %s to quit or %s to go back", firstKey(m.keys.Quit), firstKey(m.keys.Back))),
+			),
+		),
+	)
+}
+
+func (m Model) renderHelpView() string {
+	helpText := `
+🔐 PIAM Admin Network Configurator
+
+A beautiful TUI for managing Cloud SQL, GKE, AWS and Azure authorized networks.
+
+` + m.keys.keyHelp("", 76) + `
+
+RESOURCE ICONS
+  🗄️             SQL Database Instance
+  ☸️             GKE Kubernetes Cluster
+  🛡️             AWS Security Group
+  🔷             Azure SQL / PostgreSQL Server
+  🔒             Resource cannot accept external networks
+
+NETWORK RESTRICTIONS
+  • Private SQL instances cannot have authorized networks
+  • GKE clusters with master authorized networks disabled are open
+    to all IPs; enabling them requires confirmation
+  • Some resources may require VPN or jumphost access
+
+Press any key to return...`
+	
+	return lipgloss.Place(
+		m.width, m.height,
+		lipgloss.Center, lipgloss.Center,
+		HelpBoxStyle.Width(80).Render(helpText),
+	)
+}
+
+// Commands
+func loadResources() tea.Msg {
+	ctx := context.Background()
+	nm, err := NewNetworkManager(ctx)
+	if err != nil {
+		return errorMsg{err}
+	}
+	
+	resources, err := nm.ListAllResources()
+	if err != nil {
+		return errorMsg{err}
+	}
+	
+	return resourcesLoadedMsg{resources}
+}
+
+// loadAttribution looks up who added each network of a resource in Cloud Audit Logs
+func loadAttribution(resource CloudResource) tea.Cmd {
+	return func() tea.Msg {
+		nm, err := NewNetworkManager(context.Background())
+		if err != nil {
+			return attributionLoadedMsg{key: resourceKey(resource), err: err}
+		}
+
+		attribution, err := nm.GetNetworkAttribution(resource)
+		return attributionLoadedMsg{key: resourceKey(resource), attribution: attribution, err: err}
+	}
+}
+
+// loadUndo finds the snapshot taken before the last change to a resource and diffs it against the live state
+func loadUndo(resource CloudResource) tea.Cmd {
+	return func() tea.Msg {
+		nm, err := NewNetworkManager(context.Background())
+		if err != nil {
+			return errorMsg{err}
+		}
+
+		snapshot, err := nm.snapshots.LatestChange(resourceRefOf(resource))
+		if err != nil {
+			return errorMsg{err}
+		}
+		if snapshot == nil {
+			return undoLoadedMsg{}
+		}
+
+		current, err := nm.GetResourceDetails(resource)
+		if err != nil {
+			return errorMsg{err}
+		}
+		return undoLoadedMsg{
+			snapshot: snapshot,
+			diff:     diffNetworks(resourceNetworks(current), snapshot.Networks),
+			settings: snapshot.SettingChanges(current),
+		}
+	}
+}
+
+// confirmUndo asks before restoring a resource to a snapshot
+func confirmUndo(resource CloudResource, snapshot *Snapshot, diff NetworkDiff, settings []string) *confirmation {
+	changes := RenderNetworkDiff(diff)
+	if len(settings) > 0 {
+		changes = RenderDiffLines(append(diff.Lines(), settings...))
+	}
+	body := lipgloss.JoinVertical(
+		lipgloss.Left,
+		fmt.Sprintf("Restore the resource to before the %s at %s", snapshot.Operation, snapshot.Timestamp.Local().Format("2006-01-02 15:04:05")),
+		SubtleTextStyle.Render(fmt.Sprintf("Snapshot %s by %s", snapshot.ID, snapshot.Account)),
+		"",
+		changes,
+	)
+	return &confirmation{
+		title:       "↩️  Undo Last Change",
+		body:        body,
+		action:      restoreSnapshot(snapshot),
+		resources:   []CloudResource{resource},
+		returnState: stateNetworkView,
+	}
+}
+
+// restoreSnapshot puts a resource's networks back to a snapshot
+func restoreSnapshot(snapshot *Snapshot) jobAction {
+	return func(nm *NetworkManager) tea.Msg {
+		if err := nm.RestoreSnapshot(snapshot); err != nil {
+			return settingsUpdatedMsg{success: false, message: fmt.Sprintf("Failed to restore snapshot: %v", err)}
+		}
+		return settingsUpdatedMsg{success: true, message: fmt.Sprintf("Restored snapshot %s", snapshot.ID)}
+	}
+}
+
+// submitAccessRequest queues a request for an approver to add a network
+func submitAccessRequest(resource CloudResource, name, ip, reason string) tea.Cmd {
+	return func() tea.Msg {
+		config, err := LoadConfig()
+		if err != nil {
+			return errorMsg{err}
+		}
+
+		request := &AccessRequest{
+			Resource: resourceRefOf(resource),
+			Name:     name,
+			CIDR:     ip,
+			Reason:   reason,
+		}
+		if err := NewRequestQueue(config.Requests).Submit(request); err != nil {
+			return requestSubmittedMsg{success: false, message: fmt.Sprintf("Failed to submit request: %v", err)}
+		}
+		return requestSubmittedMsg{success: true, message: fmt.Sprintf("Requested access for %s - request %s is waiting for an approver", request.CIDR, request.ID)}
+	}
+}
+
+// loadRequests reads the pending access requests
+func loadRequests() tea.Msg {
+	config, err := LoadConfig()
+	if err != nil {
+		return errorMsg{err}
+	}
+
+	queue := NewRequestQueue(config.Requests)
+	requests, err := queue.List(RequestPending)
+	if err != nil {
+		return errorMsg{err}
+	}
+	if requests == nil {
+		requests = []AccessRequest{}
+	}
+	return requestsLoadedMsg{requests: requests, dir: queue.Dir()}
+}
+
+// confirmApproveRequest asks before adding a requested network
+func confirmApproveRequest(request AccessRequest, resource CloudResource) *confirmation {
+	body := lipgloss.JoinVertical(
+		lipgloss.Left,
+		fmt.Sprintf("Add %s (%s) to %s", request.CIDR, request.Name, request.Resource),
+		fmt.Sprintf("Requested by %s: %s", request.Requester, request.Reason),
+	)
+	return &confirmation{
+		title:       "✅ Approve Access Request",
+		subject:     request.Resource.String(),
+		body:        body,
+		action:      approveRequest(request.ID),
+		resources:   []CloudResource{resource},
+		returnState: stateRequests,
+	}
+}
+
+// approveRequest adds a requested network and marks the request approved
+func approveRequest(id string) jobAction {
+	return func(nm *NetworkManager) tea.Msg {
+		config, err := LoadConfig()
+		if err != nil {
+			return errorMsg{err}
+		}
+		if err := nm.ApproveRequest(NewRequestQueue(config.Requests), id, ""); err != nil {
+			return requestDecidedMsg{success: false, message: fmt.Sprintf("Failed to approve request %s: %v", id, err)}
+		}
+		return requestDecidedMsg{success: true, message: fmt.Sprintf("Approved request %s", id)}
+	}
+}
+
+// rejectRequest closes a request with a comment
+func rejectRequest(id, comment string) tea.Cmd {
+	return func() tea.Msg {
+		config, err := LoadConfig()
+		if err != nil {
+			return errorMsg{err}
+		}
+		if err := NewRequestQueue(config.Requests).Reject(id, comment); err != nil {
+			return requestDecidedMsg{success: false, message: fmt.Sprintf("Failed to reject request %s: %v", id, err)}
+		}
+		return requestDecidedMsg{success: true, message: fmt.Sprintf("Rejected request %s", id)}
+	}
+}
+
+// loadHistory reads the audit log, newest first, limited to one resource unless it is nil
+func loadHistory(resource CloudResource) tea.Cmd {
+	return func() tea.Msg {
+		config, err := LoadConfig()
+		if err != nil {
+			return errorMsg{err}
+		}
+
+		filter := AuditFilter{}
+		if resource != nil {
+			filter.Resource = resource.GetProject() + "/" + resource.GetName()
+		}
+
+		entries, err := NewAuditLog(config.Audit).Read(filter)
+		if err != nil {
+			return errorMsg{err}
+		}
+
+		for i, j := 0, len(entries)-1; i < j; i, j = i+1, j-1 {
+			entries[i], entries[j] = entries[j], entries[i]
+		}
+		return historyLoadedMsg{entries}
+	}
+}
+
+func selectResource(resource CloudResource) tea.Cmd {
+	return func() tea.Msg {
+		ctx := context.Background()
+		nm, err := NewNetworkManager(ctx)
+		if err != nil {
+			return errorMsg{err}
+		}
+		
+		// Get fresh details
+		updated, err := nm.GetResourceDetails(resource)
+		if err != nil {
+			return errorMsg{err}
+		}
+		
+		return resourceSelectedMsg{resource: updated}
+	}
+}
+
+func submitAddNetwork(resources []CloudResource, name, ip string) jobAction {
+	return func(nm *NetworkManager) tea.Msg {
+		// Validate inputs
+		if strings.TrimSpace(name) == "" {
+			return networkAddedMsg{
+				success: false,
+				message: "Network name is required",
+			}
 		}
 		
 		if strings.TrimSpace(ip) == "" {
@@ -803,45 +2758,347 @@ func submitAddNetwork(resource CloudResource, name, ip string) tea.Cmd {
 			}
 		}
 		
-		ctx := context.Background()
-		nm, err := NewNetworkManager(ctx)
-		if err != nil {
-			return errorMsg{err}
+		if len(resources) == 1 {
+			err := nm.AddNetworkToResource(resources[0], name, ip)
+			if err != nil {
+				return networkAddedMsg{
+					success: false,
+					message: fmt.Sprintf("Failed to add network: %v", err),
+				}
+			}
+
+			return networkAddedMsg{
+				success: true,
+				message: fmt.Sprintf("Successfully added network %s", name),
+			}
 		}
 		
-		err = nm.AddNetworkToResource(resource, name, ip)
+		results := nm.AddNetworkToResources(resources, name, ip)
+		success, summary := summarizeResults(results)
+		return networkAddedMsg{
+			success: success,
+			message: fmt.Sprintf("Added network %s:\n%s", name, summary),
+		}
+	}
+}
+
+// previewChange computes what a change would send to each resource, then asks to confirm it.
+// In dry-run mode the preview is as far as it goes.
+func previewChange(title string, resources []CloudResource, returnState sessionState, preview func(*NetworkManager, CloudResource) (*ChangePreview, error), action jobAction) tea.Cmd {
+	return func() tea.Msg {
+		nm, err := NewNetworkManager(context.Background())
 		if err != nil {
+			return errorMsg{err}
+		}
+
+		var previews []ChangePreview
+		for _, resource := range resources {
+			p, err := preview(nm, resource)
+			if err != nil {
+				if len(resources) > 1 {
+					err = fmt.Errorf("%s: %v", resource.GetName(), err)
+				}
+				return previewLoadedMsg{returnState: returnState, err: err}
+			}
+			previews = append(previews, *p)
+		}
+
+		return previewLoadedMsg{
+			confirm: &confirmation{
+				title:       title,
+				body:        renderChangePreviews(previews),
+				action:      action,
+				resources:   resources,
+				returnState: returnState,
+			},
+			returnState: returnState,
+		}
+	}
+}
+
+// renderChangePreviews renders the network list each resource will be sent
+func renderChangePreviews(previews []ChangePreview) string {
+	var sections []string
+	for i, preview := range previews {
+		if i > 0 {
+			sections = append(sections, "")
+		}
+		if len(previews) > 1 {
+			sections = append(sections, LabelStyle.Render(preview.Resource.GetDisplayName()))
+		}
+		sections = append(sections,
+			RenderNetworkChange(preview.Before, preview.After),
+			SubtleTextStyle.Render(fmt.Sprintf("%d entries now, %d after this change", len(preview.Before), len(preview.After))),
+		)
+	}
+	if dryRunMode {
+		sections = append(sections, "", WarningStyle.Render("Dry run: this change will not be sent"))
+	}
+	return lipgloss.JoinVertical(lipgloss.Left, sections...)
+}
+
+// submitAddGroup adds a group's missing members to each resource
+func submitAddGroup(resources []CloudResource, group NetworkGroup) jobAction {
+	return func(nm *NetworkManager) tea.Msg {
+		if len(resources) == 1 {
+			if err := nm.AddGroupToResource(resources[0], group); err != nil {
+				return networkAddedMsg{
+					success: false,
+					message: fmt.Sprintf("Failed to add group %s: %v", group.Name, err),
+				}
+			}
+			return networkAddedMsg{
+				success: true,
+				message: fmt.Sprintf("Successfully added group %s", group.Name),
+			}
+		}
+
+		results := nm.AddGroupToResources(resources, group)
+		success, summary := summarizeResults(results)
+		return networkAddedMsg{
+			success: success,
+			message: fmt.Sprintf("Added group %s:\n%s", group.Name, summary),
+		}
+	}
+}
+
+// submitEditNetwork renames an entry or changes its CIDR
+func submitEditNetwork(resource CloudResource, oldIP, name, ip string) jobAction {
+	return func(nm *NetworkManager) tea.Msg {
+		if err := nm.EditNetwork(resource, oldIP, name, ip); err != nil {
 			return networkAddedMsg{
 				success: false,
-				message: fmt.Sprintf("Failed to add network: %v", err),
+				message: fmt.Sprintf("Failed to edit network: %v", err),
 			}
 		}
-		
 		return networkAddedMsg{
 			success: true,
-			message: fmt.Sprintf("Successfully added network %s", name),
+			message: fmt.Sprintf("Successfully updated %s to %s %s", oldIP, name, ip),
+		}
+	}
+}
+
+func submitRemoveNetwork(resources []CloudResource, ip string) jobAction {
+	return func(nm *NetworkManager) tea.Msg {
+		if strings.TrimSpace(ip) == "" {
+			return networkRemovedMsg{
+				success: false,
+				message: "IP address is required",
+			}
+		}
+
+		if len(resources) == 1 {
+			err := nm.RemoveNetworkFromResource(resources[0], strings.TrimSpace(ip))
+			if err != nil {
+				return networkRemovedMsg{
+					success: false,
+					message: fmt.Sprintf("Failed to remove network: %v", err),
+				}
+			}
+
+			return networkRemovedMsg{
+				success: true,
+				message: fmt.Sprintf("Successfully removed network %s", ip),
+			}
+		}
+
+		results := nm.RemoveNetworkFromResources(resources, strings.TrimSpace(ip))
+		success, summary := summarizeResults(results)
+		return networkRemovedMsg{
+			success: success,
+			message: fmt.Sprintf("Removed network %s:\n%s", ip, summary),
+		}
+	}
+}
+
+// summarizeResults renders one line per resource and reports whether all succeeded
+func summarizeResults(results []ResourceResult) (bool, string) {
+	allSucceeded := true
+	lines := make([]string, len(results))
+	for i, result := range results {
+		if result.Err != nil {
+			allSucceeded = false
+			lines[i] = fmt.Sprintf("  ✗ %s: %v", result.Resource.GetName(), result.Err)
+		} else {
+			lines[i] = fmt.Sprintf("  ✓ %s", result.Resource.GetName())
+		}
+	}
+	return allSucceeded, strings.Join(lines, "\n")
+}
+
+func submitPSCConsumerProject(instance SQLInstance, project string, remove bool) jobAction {
+	return func(nm *NetworkManager) tea.Msg {
+		project = strings.TrimSpace(project)
+		if project == "" {
+			return pscUpdatedMsg{
+				success: false,
+				message: "Project ID is required",
+			}
+		}
+
+
+		if remove {
+			if err := nm.RemovePSCConsumerProject(instance, project); err != nil {
+				return pscUpdatedMsg{success: false, message: fmt.Sprintf("Failed to remove project: %v", err)}
+			}
+			return pscUpdatedMsg{success: true, message: fmt.Sprintf("Removed %s from PSC allowed consumer projects", project)}
+		}
+
+		if err := nm.AddPSCConsumerProject(instance, project); err != nil {
+			return pscUpdatedMsg{success: false, message: fmt.Sprintf("Failed to add project: %v", err)}
+		}
+		return pscUpdatedMsg{success: true, message: fmt.Sprintf("Added %s to PSC allowed consumer projects", project)}
+	}
+}
+
+// confirmMasterAuthorizedNetworks builds the confirmation for toggling master authorized networks
+func confirmMasterAuthorizedNetworks(cluster GKECluster) *confirmation {
+	enable := !cluster.MasterAuthorizedNetworksEnabled
+
+	var body string
+	if enable {
+		lines := []string{
+			ErrorStyle.Render("Enabling master authorized networks restricts the control plane"),
+			ErrorStyle.Render("to the entries below. Anyone else will be locked out."),
+			"",
+		}
+		if len(cluster.MasterAuthorizedNetworks) == 0 {
+			lines = append(lines, WarningStyle.Render("The list is empty: only Google Cloud internal access will remain."))
+		} else {
+			lines = append(lines, RenderNetworkTable(cluster.MasterAuthorizedNetworks, nil, nil))
 		}
+		if ip := getPublicIP(); ip != "" && !networksContainIP(cluster.MasterAuthorizedNetworks, ip) {
+			lines = append(lines, "", WarningStyle.Render(fmt.Sprintf("Your current IP %s is not in this list.", ip)))
+		}
+		body = lipgloss.JoinVertical(lipgloss.Left, lines...)
+	} else {
+		body = lipgloss.JoinVertical(
+			lipgloss.Left,
+			ErrorStyle.Render("Disabling master authorized networks opens the control plane"),
+			ErrorStyle.Render("to connections from any IP address on the internet."),
+		)
 	}
+
+	return &confirmation{
+		title: fmt.Sprintf("%s Master Authorized Networks?", enableVerb(enable)),
+		body:  body,
+		action: func(nm *NetworkManager) tea.Msg {
+			if err := nm.SetGKEMasterAuthorizedNetworksEnabled(cluster, enable); err != nil {
+				return settingsUpdatedMsg{success: false, message: fmt.Sprintf("Failed to update cluster: %v", err)}
+			}
+			return settingsUpdatedMsg{success: true, message: fmt.Sprintf("Master authorized networks %s", enabledLabel(enable))}
+		},
+		resources:   []CloudResource{cluster},
+		returnState: stateNetworkView,
+	}
+}
+
+// confirmGCPPublicCidrsAccess builds the confirmation for toggling Google Cloud public IP access
+func confirmGCPPublicCidrsAccess(cluster GKECluster) *confirmation {
+	enable := !cluster.GCPPublicCidrsAccessEnabled
+
+	body := "Blocking Google Cloud public IPs stops Compute Engine VMs, Cloud Build and other\nGoogle Cloud services without an authorized IP from reaching the control plane."
+	if enable {
+		body = "Allowing Google Cloud public IPs lets any Google Cloud public address\n(including other customers' VMs) reach the control plane."
+	}
+
+	return &confirmation{
+		title: fmt.Sprintf("%s Google Cloud Public IP Access?", enableVerb(enable)),
+		body:  WarningStyle.Render(body),
+		action: func(nm *NetworkManager) tea.Msg {
+			if err := nm.SetGKEPublicCidrsAccessEnabled(cluster, enable); err != nil {
+				return settingsUpdatedMsg{success: false, message: fmt.Sprintf("Failed to update cluster: %v", err)}
+			}
+			return settingsUpdatedMsg{success: true, message: fmt.Sprintf("Google Cloud public IP access %s", enabledLabel(enable))}
+		},
+		resources:   []CloudResource{cluster},
+		returnState: stateNetworkView,
+	}
+}
+
+// enableVerb returns "Enable" or "Disable"
+func enableVerb(enable bool) string {
+	if enable {
+		return "Enable"
+	}
+	return "Disable"
+}
+
+// networksContainIP reports whether an IP/CIDR falls within any of the networks
+func networksContainIP(networks []AuthorizedNetwork, ip string) bool {
+	_, ok := entryContaining(networks, ip)
+	return ok
 }
 
 // Helper function to render network table
-func RenderNetworkTable(networks []AuthorizedNetwork) string {
+// RenderNetworkTable renders a network list, with "Added By/At" columns when attribution is non-nil
+// and a "Group" column when any entry belongs to a configured group
+func RenderNetworkTable(networks []AuthorizedNetwork, attribution map[string]NetworkAttribution, groups *NetworkGroups) string {
+	return renderNetworkTableWindow(networks, networks, attribution, groups, -1, 0, len(networks))
+}
+
+// renderNetworkTableWindow renders height rows of a sorted or filtered view of networks from offset,
+// highlighting the cursor row. Columns are sized from the full list so they don't shift while scrolling.
+func renderNetworkTableWindow(networks, rows []AuthorizedNetwork, attribution map[string]NetworkAttribution, groups *NetworkGroups, cursor, offset, height int) string {
 	if len(networks) == 0 {
 		return EmptyStateStyle.Render("No authorized networks configured")
 	}
+	if len(rows) == 0 {
+		return EmptyStateStyle.Render("No entries match the filter")
+	}
+	if offset < 0 || offset >= len(rows) {
+		offset = 0
+	}
 	
+	// Show a port column only for resources with per-port rules (AWS)
+	showPort := false
+	for _, network := range networks {
+		if network.Port != "" {
+			showPort = true
+			break
+		}
+	}
+
+	// Annotate group members, noting groups that are only partly present
+	coverage := make(map[string]GroupCoverage)
+	for _, c := range groups.Coverage(networks) {
+		coverage[c.Group.Name] = c
+	}
+
 	// Create table header
+	headerCells := []string{
+		TableCellStyle.Width(30).Render("Name"),
+		TableCellStyle.Width(20).Render("IP/CIDR"),
+	}
+	if showPort {
+		headerCells = append(headerCells, TableCellStyle.Width(14).Render("Port"))
+	}
+	if len(coverage) > 0 {
+		headerCells = append(headerCells, TableCellStyle.Width(24).Render("Group"))
+	}
+	if attribution != nil {
+		headerCells = append(headerCells,
+			TableCellStyle.Width(28).Render("Added By"),
+			TableCellStyle.Width(18).Render("Added At"),
+		)
+	}
 	header := TableHeaderStyle.Render(
-		lipgloss.JoinHorizontal(
-			lipgloss.Top,
-			TableCellStyle.Width(30).Render("Name"),
-			TableCellStyle.Width(20).Render("IP/CIDR"),
-		),
+		lipgloss.JoinHorizontal(lipgloss.Top, headerCells...),
 	)
 	
-	// Create table rows
-	rows := make([]string, len(networks))
-	for i, network := range networks {
+	// Create table rows, drawing only the visible window
+	end := offset + height
+	if end > len(rows) {
+		end = len(rows)
+	}
+	lines := make([]string, 0, end-offset)
+	for i := offset; i < end; i++ {
+		network := rows[i]
+		cellStyle := TableCellStyle
+		if i == cursor {
+			cellStyle = TableSelectedCellStyle
+		}
+
 		name := network.Name
 		if name == "" {
 			name = network.DisplayName
@@ -850,28 +3107,77 @@ func RenderNetworkTable(networks []AuthorizedNetwork) string {
 			name = "(unnamed)"
 		}
 		
-		row := lipgloss.JoinHorizontal(
-			lipgloss.Top,
-			TableCellStyle.Width(30).Render(name),
-			TableCellStyle.Width(20).Render(network.Value),
-		)
+		cells := []string{
+			cellStyle.Width(30).Render(name),
+			cellStyle.Width(20).Render(network.Value),
+		}
+		if showPort {
+			cells = append(cells, cellStyle.Width(14).Render(network.Port))
+		}
+		if len(coverage) > 0 {
+			var labels []string
+			partial := false
+			for _, name := range groups.GroupsOf(network.Value) {
+				c := coverage[name]
+				if c.Partial() {
+					partial = true
+					labels = append(labels, fmt.Sprintf("%s (%d/%d)", name, c.Present, len(c.Group.CIDRs)))
+				} else {
+					labels = append(labels, name)
+				}
+			}
+			cell := truncate(strings.Join(labels, ", "), 22)
+			if partial {
+				cell = WarningStyle.Render(cell)
+			}
+			cells = append(cells, cellStyle.Width(24).Render(cell))
+		}
+		if attribution != nil {
+			addedBy, addedAt := "unknown", ""
+			if a, ok := lookupAttribution(attribution, network); ok {
+				addedBy = truncate(a.AddedBy(), 26)
+				addedAt = a.AddedAt("2006-01-02 15:04")
+			}
+			cells = append(cells,
+				cellStyle.Width(28).Render(addedBy),
+				cellStyle.Width(18).Render(addedAt),
+			)
+		}
+		row := lipgloss.JoinHorizontal(lipgloss.Top, cells...)
 		
 		if i%2 == 0 {
-			rows[i] = TableRowEvenStyle.Render(row)
+			lines = append(lines, TableRowEvenStyle.Render(row))
 		} else {
-			rows[i] = TableRowOddStyle.Render(row)
+			lines = append(lines, TableRowOddStyle.Render(row))
 		}
 	}
 	
 	table := lipgloss.JoinVertical(
 		lipgloss.Left,
 		header,
-		strings.Join(rows, "\n"),
+		strings.Join(lines, "\n"),
 	)
 	
 	return TableStyle.Render(table)
 }
 
+// RenderPartialGroups warns about groups with only some of their members present
+func RenderPartialGroups(coverage []GroupCoverage) string {
+	var lines []string
+	for _, c := range coverage {
+		if !c.Partial() {
+			continue
+		}
+		missing := make([]string, len(c.Missing))
+		for i, network := range c.Missing {
+			missing[i] = network.Value
+		}
+		lines = append(lines, WarningStyle.Render(fmt.Sprintf("⚠️  Group %s is partially applied (%d of %d): missing %s",
+			c.Group.Name, c.Present, len(c.Group.CIDRs), strings.Join(missing, ", "))))
+	}
+	return strings.Join(lines, "\n")
+}
+
 // openConsoleURL opens the Google Cloud Console for the resource
 func openConsoleURL(resource CloudResource) tea.Cmd {
 	return func() tea.Msg {
@@ -914,6 +3220,44 @@ func openConsoleURL(resource CloudResource) tea.Cmd {
 	}
 }
 
+// copyToClipboard copies text with the platform's clipboard tool, falling back to
+// the terminal's OSC 52 escape sequence (which works over SSH) when there is none
+func copyToClipboard(text string) tea.Cmd {
+	return func() tea.Msg {
+		var candidates [][]string
+		switch runtime.GOOS {
+		case "darwin":
+			candidates = [][]string{{"pbcopy"}}
+		case "windows":
+			candidates = [][]string{{"clip"}}
+		default:
+			if os.Getenv("WAYLAND_DISPLAY") != "" {
+				candidates = append(candidates, []string{"wl-copy"})
+			}
+			candidates = append(candidates,
+				[]string{"xclip", "-selection", "clipboard"},
+				[]string{"xsel", "--clipboard", "--input"},
+			)
+		}
+
+		for _, args := range candidates {
+			if _, err := exec.LookPath(args[0]); err != nil {
+				continue
+			}
+			cmd := exec.Command(args[0], args[1:]...)
+			cmd.Stdin = strings.NewReader(text)
+			if err := cmd.Run(); err == nil {
+				return clipboardMsg{success: true, message: fmt.Sprintf("Copied %s to the clipboard", text)}
+			}
+		}
+
+		if _, err := fmt.Fprintf(os.Stdout, "\x1b]52;c;%s\a", base64.StdEncoding.EncodeToString([]byte(text))); err != nil {
+			return clipboardMsg{success: false, message: fmt.Sprintf("Failed to copy %s: %v", text, err)}
+		}
+		return clipboardMsg{success: true, message: fmt.Sprintf("Sent %s to the terminal clipboard", text)}
+	}
+}
+
 // tickCmd creates a command that ticks every second
 func tickCmd() tea.Cmd {
 	return tea.Tick(time.Second, func(t time.Time) tea.Msg {